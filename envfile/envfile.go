@@ -0,0 +1,51 @@
+// Package envfile parses and writes .env files with the parser envtui uses, for
+// Go programs that embed it without the TUI. Comments, blank lines, quotes and
+// line endings are kept, so a parsed file's String method gives back the
+// original content, and edits change only the lines they touch.
+package envfile
+
+import (
+	"github.com/envtui/envtui/internal/model"
+	"github.com/envtui/envtui/internal/parser"
+)
+
+type (
+	// File is a parsed env file; File.String renders it for writing
+	File = model.EnvFile
+	// Entry is one line of a file (a key/value, comment, blank line or bare key)
+	Entry = model.Entry
+	// EntryType is the kind of line an Entry holds
+	EntryType = model.EntryType
+	// QuoteStyle is how a value was quoted, kept when the file is written
+	QuoteStyle = model.QuoteStyle
+	// ParseOptions enables interpolation of ${VAR} references
+	ParseOptions = parser.ParseOptions
+)
+
+const (
+	KeyValueEntry = model.KeyValueEntry
+	CommentEntry  = model.CommentEntry
+	BlankEntry    = model.BlankEntry
+	BareKeyEntry  = model.BareKeyEntry
+)
+
+const (
+	QuoteNone   = model.QuoteNone
+	QuoteSingle = model.QuoteSingle
+	QuoteDouble = model.QuoteDouble
+)
+
+// Parse parses .env content
+func Parse(content string) (*File, error) {
+	return parser.ParseString(content)
+}
+
+// ParseWithOptions parses .env content using the given options
+func ParseWithOptions(content string, opts ParseOptions) (*File, error) {
+	return parser.ParseWithOptions(content, opts)
+}
+
+// ParseFile reads and parses the .env file at path
+func ParseFile(path string) (*File, error) {
+	return parser.ParseFile(path)
+}
@@ -0,0 +1,39 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFileRoundTrip(t *testing.T) {
+	content := "# Database\r\nDB_HOST=localhost\r\nexport PASSWORD='p@ss $word' # literal\r\n\r\nEMPTY=\"\"\r\n"
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	file, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if file.Path != path {
+		t.Errorf("Path = %s, want %s", file.Path, path)
+	}
+	password := file.GetEntry("PASSWORD")
+	if password == nil || password.Value != "p@ss $word" || !password.Exported || password.QuoteStyle != QuoteSingle {
+		t.Fatalf("unexpected PASSWORD entry: %+v", password)
+	}
+	if got := file.String(); got != content {
+		t.Errorf("String() = %q, want %q", got, content)
+	}
+
+	file.UpdateEntry("DB_HOST", "db.internal")
+	reparsed, err := Parse(file.String())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := reparsed.GetEntry("DB_HOST").Value; got != "db.internal" {
+		t.Errorf("DB_HOST = %s after a round trip", got)
+	}
+}
@@ -1,26 +1,23 @@
 package app
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/envtui/envtui/internal/logging"
 	"github.com/envtui/envtui/internal/model"
 	"github.com/envtui/envtui/internal/parser"
 	"github.com/envtui/envtui/internal/storage"
+	"github.com/envtui/envtui/internal/ui/styles"
 	"github.com/envtui/envtui/internal/ui/views"
 )
 
-func logDebug(msg string) {
-	f, _ := os.OpenFile("/tmp/envtui_debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if f != nil {
-		fmt.Fprintf(f, "[%s] %s\n", time.Now().Format("15:04:05"), msg)
-		f.Close()
-	}
-}
-
 type ViewMode int
 
 const (
@@ -29,8 +26,76 @@ const (
 	ViewModeAdd
 	ViewModeDiff
 	ViewModeBackup
+	ViewModeHistory
+	ViewModeCompare
+	ViewModeIssues
+	ViewModeEffective
 )
 
+// Options configures optional app behavior
+type Options struct {
+	// PersistHistory appends every tracked change to a sidecar history log
+	PersistHistory bool
+	// SecretPatterns are extra key patterns treated as secret (see model.SetSecretPatterns)
+	SecretPatterns []string
+	// Watch reloads open files when another program changes them on disk
+	Watch bool
+	// CategoriesFile holds custom prefix rules for entry categories (see storage.LoadCategoryRules)
+	CategoriesFile string
+	// SchemaFile lists required keys and value types every file is validated against (see storage.LoadSchema)
+	SchemaFile string
+	// BackupPolicy controls when saves back up the file (see storage.SetBackupPolicy)
+	BackupPolicy storage.BackupPolicy
+	// NoConfirmDelete deletes entries without a [y/N] prompt; undo still restores them
+	NoConfirmDelete bool
+	// Precedence orders files by name for the effective config view, highest
+	// priority first; nil uses model.DefaultPrecedence
+	Precedence []string
+	// TemplatesFile adds templates to the built-in ones (see views.LoadTemplates);
+	// empty uses views.DefaultTemplatesPath when that file exists
+	TemplatesFile string
+	// KeysFile remaps list view keys with "action = key[, key...]" lines (see views.ParseKeyBindings)
+	KeysFile string
+	// DebugLog is a file to write diagnostic logs to (created 0600); empty
+	// falls back to logging.EnvVar, and logging stays off if neither is set
+	DebugLog string
+	// LogLevel limits what DebugLog receives: "error", "info" or "debug" (default)
+	LogLevel string
+	// Create starts files that don't exist yet as empty files, written on the
+	// first save, instead of reporting them as missing
+	Create bool
+	// ReadOnly lists files opened read-only: they can be compared and copied
+	// from, but not edited
+	ReadOnly []string
+	// TrackModified keeps when each key was last changed in a sidecar file
+	// next to the env file (see storage.MetadataPath), so it outlives the session
+	TrackModified bool
+	// NoColor draws the interface without colors, using bold, underline and
+	// reverse video instead; NO_COLOR or TERM=dumb turn it on as well
+	NoColor bool
+	// Theme is "dark", "light", "mono" or "auto" (the default), which picks
+	// dark or light from the terminal background (see styles.ParseTheme)
+	Theme string
+	// MaskChar, MaskMatchLength and MaskRevealSuffix control how secret values
+	// are masked on screen (see model.MaskStyle); the default is eight bullets
+	MaskChar         string
+	MaskMatchLength  bool
+	MaskRevealSuffix int
+}
+
+// readOnlyActions change the current file, so they are refused while it is read-only
+var readOnlyActions = map[string]bool{
+	"add": true, "edit": true, "rename": true, "duplicate": true, "delete": true,
+	"undo": true, "redo": true, "sort-file": true, "toggle-export": true,
+	"bulk-delete": true, "bulk-replace": true, "dedupe": true,
+}
+
+// inheritedActions change the selected entry, so they are refused for an entry
+// inherited from an included file
+var inheritedActions = map[string]bool{
+	"edit": true, "rename": true, "delete": true, "toggle-export": true,
+}
+
 type Model struct {
 	envFiles         []*model.EnvFile
 	originalStates   []*model.EnvFile // Original states for diff view
@@ -39,10 +104,24 @@ type Model struct {
 	editView         views.EditView
 	diffView         views.DiffView
 	backupView       views.BackupView
+	historyView      views.HistoryView
+	compareView      views.CompareView
+	issuesView       views.IssuesView
+	effectiveView    views.EffectiveView
 	viewMode         ViewMode
-	err              error
+	err              error  // Fatal: no file could be read, replaces the whole screen
+	bannerErr        error  // A failed operation, shown above the still usable view
+	failedSave       string // Path whose last save failed, cleared once it saves
 	validationIssues []model.ValidationIssue
+	schema           model.Schema
 	changeStack      *model.ChangeStack
+	options          Options
+	conflictFiles    []*model.EnvFile               // Files whose save was refused because they changed on disk, oldest first
+	watchNotice      string                         // Shown while a watched file changed but edits are pending
+	watchStamps      map[string]storage.FileStamp   // Stamps of the open files at the last watch check
+	watchChanged     map[string]bool                // Files found changed by the last watch check, hashed again until reloaded
+	gitInfo          map[string]storage.FileGitInfo // Git status of each open file, by path (see refreshGitInfo)
+	startupCmd       tea.Cmd                        // Run by Init, e.g. to report lines that could not be parsed
 }
 
 // New creates a model with a single file (backward compatibility)
@@ -52,22 +131,64 @@ func New(filePath string) Model {
 
 // NewMultiFile creates a model with multiple files
 func NewMultiFile(filePaths []string) Model {
+	return NewMultiFileWithOptions(filePaths, Options{})
+}
+
+// NewMultiFileWithOptions creates a model with multiple files and the given options.
+// A directory or glob pattern opens every env file it names (see storage.ExpandPath).
+func NewMultiFileWithOptions(filePaths []string, opts Options) Model {
 	if len(filePaths) == 0 {
 		return Model{err: fmt.Errorf("no files provided")}
 	}
 
+	if err := applyLogging(opts.DebugLog, opts.LogLevel); err != nil {
+		return Model{err: err}
+	}
+	if err := model.SetSecretPatterns(opts.SecretPatterns); err != nil {
+		return Model{err: err}
+	}
+	if err := applyCategoryRules(opts.CategoriesFile); err != nil {
+		return Model{err: err}
+	}
+	if err := applyKeyBindings(opts.KeysFile); err != nil {
+		return Model{err: err}
+	}
+	if err := applyTemplates(opts.TemplatesFile); err != nil {
+		return Model{err: err}
+	}
+	if err := applyTheme(opts.Theme, opts.NoColor); err != nil {
+		return Model{err: err}
+	}
+	if err := model.SetMaskStyle(model.MaskStyle{Char: opts.MaskChar, MatchLength: opts.MaskMatchLength, RevealSuffix: opts.MaskRevealSuffix}); err != nil {
+		return Model{err: err}
+	}
+	storage.SetBackupPolicy(opts.BackupPolicy)
+	var schema model.Schema
+	if opts.SchemaFile != "" {
+		var err error
+		if schema, err = storage.LoadSchema(opts.SchemaFile); err != nil {
+			return Model{err: err}
+		}
+	}
+
 	var envFiles []*model.EnvFile
 	var originalStates []*model.EnvFile
-	var firstErr error
 
+	// Directories and glob patterns open every env file they name; read-only
+	// files are opened too
+	filePaths, firstErr := storage.ExpandPaths(append(append([]string(nil), filePaths...), opts.ReadOnly...))
 	for _, path := range filePaths {
-		envFile, err := storage.ReadFile(path)
+		envFile, err := readOrCreateFile(path, opts.Create)
 		if err != nil {
 			if firstErr == nil {
 				firstErr = err
 			}
 			continue
 		}
+		envFile.ReadOnly = opts.isReadOnly(path)
+		if opts.TrackModified {
+			loadLastModified(envFile)
+		}
 		envFiles = append(envFiles, envFile)
 		// Store original state for diff view
 		originalStates = append(originalStates, envFile.Clone())
@@ -76,24 +197,53 @@ func NewMultiFile(filePaths []string) Model {
 	if len(envFiles) == 0 {
 		return Model{err: firstErr}
 	}
+	var bannerErr error
+	if firstErr != nil {
+		// Work with the files that could be read
+		bannerErr = fmt.Errorf("could not open every file: %w", firstErr)
+	}
 
-	// Load the first file
-	currentFile := envFiles[0]
-	issues := currentFile.Validate()
-
-	// Create list view and set files for copy operations
-	listView := views.NewListView(currentFile.FilterEntries(""))
-	listView.SetFiles(envFiles, 0)
-
-	return Model{
+	m := Model{
 		envFiles:         envFiles,
 		originalStates:   originalStates,
-		currentFileIndex: 0,
-		listView:         listView,
+		currentFileIndex: 0, // Start on the first file
 		viewMode:         ViewModeList,
-		validationIssues: issues,
+		schema:           schema,
 		changeStack:      model.NewChangeStack(100), // Track up to 100 changes
+		options:          opts,
+		bannerErr:        bannerErr,
 	}
+	m.refreshGitInfo()
+	// Create the list view with the files it needs for copy operations
+	m.refreshListView()
+	if status := skippedLinesStatus(envFiles); status != "" {
+		m.startupCmd = m.listView.ShowStatus(status, true)
+	}
+	return m
+}
+
+// skippedLinesStatus tells how many lines of the files could not be parsed, or
+// returns "" if every line was read
+func skippedLinesStatus(envFiles []*model.EnvFile) string {
+	count, files, name := 0, 0, ""
+	for _, envFile := range envFiles {
+		if n := len(envFile.SkippedLines); n > 0 {
+			count += n
+			files++
+			name = filepath.Base(envFile.Path)
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	lines := "lines"
+	if count == 1 {
+		lines = "line"
+	}
+	if files > 1 {
+		name = fmt.Sprintf("%d files", files)
+	}
+	return fmt.Sprintf("%d %s of %s could not be parsed and will be dropped on save - press i to review", count, lines, name)
 }
 
 // GetCurrentEnvFile returns the currently active env file
@@ -119,10 +269,38 @@ func (m *Model) ShowDiffView() {
 	if current != nil && original != nil {
 		m.diffView = views.NewDiffView(current, original)
 		m.diffView.SetSize(m.listView.Width(), m.listView.Height())
+		m.diffView.SetExportable(true)
 		m.viewMode = ViewModeDiff
 	}
 }
 
+// ShowDiskDiffView shows the diff view comparing the current state to the file
+// as it is on disk right now, which another program may have changed since it
+// was loaded
+func (m *Model) ShowDiskDiffView() tea.Cmd {
+	current := m.GetCurrentEnvFile()
+	if current == nil {
+		return nil
+	}
+	name := filepath.Base(current.Path)
+	if current.Path == storage.StdinPath {
+		return m.listView.ShowStatus("stdin input has no file on disk to compare with", true)
+	}
+	if storage.IsNewFile(current) {
+		return m.listView.ShowStatus(fmt.Sprintf("%s has not been saved to disk yet", name), true)
+	}
+	onDisk, err := storage.ReadFile(current.Path)
+	if err != nil {
+		return m.listView.ShowStatus(fmt.Sprintf("Could not read %s: %v", name, err), true)
+	}
+	m.diffView = views.NewDiffView(current, onDisk)
+	m.diffView.SetLabels("Changes vs Disk", fmt.Sprintf("No differences - %s on disk matches what envtui has", name))
+	m.diffView.SetSize(m.listView.Width(), m.listView.Height())
+	m.diffView.SetExportable(true)
+	m.viewMode = ViewModeDiff
+	return nil
+}
+
 // GetCurrentFileName returns the filename of the current env file
 func (m Model) GetCurrentFileName() string {
 	if envFile := m.GetCurrentEnvFile(); envFile != nil {
@@ -133,166 +311,811 @@ func (m Model) GetCurrentFileName() string {
 
 // SwitchToFile switches to the env file at the given index
 func (m *Model) SwitchToFile(index int) {
-	// Preserve dimensions when switching files
-	oldWidth := m.listView.Width()
-	oldHeight := m.listView.Height()
 	m.currentFileIndex = index
-	m.listView = views.NewListView(m.GetCurrentEnvFile().FilterEntries(""))
-	m.listView.SetSize(oldWidth, oldHeight)
-	// Set files for copy operations
-	m.listView.SetFiles(m.envFiles, index)
+	m.refreshListView()
 }
 
-// TrackChange records a change for undo/redo
-func (m *Model) TrackChange(changeType model.ChangeType, entry *model.Entry, oldValue string) {
-	if m.changeStack == nil {
+// isReadOnly reports whether path is one of the ReadOnly files, or in a
+// directory or glob listed there
+func (opts Options) isReadOnly(path string) bool {
+	readOnly, _ := storage.ExpandPaths(opts.ReadOnly)
+	for _, p := range readOnly {
+		if filepath.Clean(p) == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyMergeTarget returns the read-only file a merge would change, if any
+func readOnlyMergeTarget(msg views.MergeFilesMsg) *model.EnvFile {
+	if len(msg.IntoCurrent) > 0 && msg.Current.ReadOnly {
+		return msg.Current
+	}
+	if len(msg.IntoOther) > 0 && msg.Other.ReadOnly {
+		return msg.Other
+	}
+	return nil
+}
+
+// readOrCreateFile reads the env file at path. With create set, a file that
+// doesn't exist yet is started empty and created on the first save.
+func readOrCreateFile(path string, create bool) (*model.EnvFile, error) {
+	envFile, err := storage.ReadFile(path)
+	if err != nil && create && path != storage.StdinPath && errors.Is(err, os.ErrNotExist) {
+		logging.Infof("%s does not exist, starting a new file", path)
+		return storage.NewFile(path), nil
+	}
+	if err == nil {
+		loadIncludes(envFile)
+	}
+	return envFile, err
+}
+
+// loadIncludes reads the files envFile includes. One that cannot be read is
+// reported as a validation error; the file works without it.
+func loadIncludes(envFile *model.EnvFile) {
+	if err := storage.LoadIncludes(envFile); err != nil {
+		envFile.ParseIssues = append(envFile.ParseIssues, model.ValidationIssue{
+			Level:   model.ValidationError,
+			Message: err.Error(),
+		})
+	}
+}
+
+// loadLastModified reads when the keys of envFile were last changed from its
+// sidecar metadata file. A damaged file only loses the timestamps.
+func loadLastModified(envFile *model.EnvFile) {
+	lastModified, err := storage.LoadLastModified(envFile.Path)
+	if err != nil {
+		logging.Infof("Ignoring metadata of %s: %v", envFile.Path, err)
 		return
 	}
+	envFile.LastModified = lastModified
+}
+
+// OpenFile reads the env file at path and makes it the current file. A file
+// that is already open is switched to rather than opened twice.
+func (m *Model) OpenFile(path string) error {
+	for i, envFile := range m.envFiles {
+		if filepath.Clean(envFile.Path) == filepath.Clean(path) {
+			m.SwitchToFile(i)
+			return nil
+		}
+	}
 
+	envFile, err := readOrCreateFile(path, m.options.Create)
+	if err != nil {
+		return err
+	}
+	envFile.ReadOnly = m.options.isReadOnly(path)
+	if m.options.TrackModified {
+		loadLastModified(envFile)
+	}
+	m.envFiles = append(m.envFiles, envFile)
+	m.originalStates = append(m.originalStates, envFile.Clone())
+	m.refreshGitInfo(path)
+	m.SwitchToFile(len(m.envFiles) - 1)
+	logging.Infof("Opened %s", path)
+	return nil
+}
+
+// CloseFile closes the file at index, keeping the current file current, or
+// moving to its neighbour when it is the one closed. Every change is already
+// saved, so nothing is lost. The last open file is never closed; quit instead.
+func (m *Model) CloseFile(index int) {
+	if index < 0 || index >= len(m.envFiles) || len(m.envFiles) == 1 {
+		return
+	}
+
+	if m.failedSave == m.envFiles[index].Path {
+		m.bannerErr = nil
+		m.failedSave = ""
+	}
+	logging.Infof("Closed %s", m.envFiles[index].Path)
+	m.envFiles = append(m.envFiles[:index], m.envFiles[index+1:]...)
+	m.originalStates = append(m.originalStates[:index], m.originalStates[index+1:]...)
+	if m.currentFileIndex > index || m.currentFileIndex >= len(m.envFiles) {
+		m.currentFileIndex--
+	}
+	m.refreshListView()
+}
+
+// refreshGitInfo reads the git status of the files at paths, or of every open
+// file when none are given. It runs git, so it is called when a file is opened,
+// saved or committed and by the watch check, never while rendering.
+func (m *Model) refreshGitInfo(paths ...string) {
+	if len(paths) == 0 {
+		for _, envFile := range m.envFiles {
+			paths = append(paths, envFile.Path)
+		}
+	}
+	if m.gitInfo == nil {
+		m.gitInfo = make(map[string]storage.FileGitInfo)
+	}
+	for _, path := range paths {
+		m.gitInfo[path] = storage.GetFileGitInfo(path)
+	}
+}
+
+// refreshListView rebuilds the list view from the current file, preserving its
+// dimensions and the file list and history it needs for copy, compare and sort,
+// and revalidates the file
+func (m *Model) refreshListView() {
 	envFile := m.GetCurrentEnvFile()
 	if envFile == nil {
 		return
 	}
 
+	oldWidth := m.listView.Width()
+	oldHeight := m.listView.Height()
+	showDetails := m.listView.DetailsShown()
+	partialReveal := m.listView.PartialReveal()
+	sortMode, sorted := m.listView.SortMode()
+	// Entries inherited from included files are listed after the file's own
+	inherited, sources := envFile.InheritedEntries()
+	m.listView = views.NewListView(append(envFile.FilterEntries(""), inherited...))
+	m.listView.SetInherited(sources)
+	if oldWidth > 0 && oldHeight > 0 {
+		m.listView.SetSize(oldWidth, oldHeight)
+	}
+	// The detail panel stays open, to see when an edited entry changed
+	m.listView.ShowDetails(showDetails)
+	m.listView.SetPartialReveal(partialReveal)
+	m.listView.SetFiles(m.envFiles, m.currentFileIndex)
+	m.listView.SetChangeStack(m.changeStack)
+	m.listView.SetConfirmDelete(!m.options.NoConfirmDelete)
+	m.listView.SetComments(envFile.EntryComments())
+	m.validationIssues = m.validate(envFile)
+	m.listView.SetValidationIssues(m.validationIssues)
+	// Sort last, as the recently changed sort reads the files and the change stack
+	if sorted {
+		m.listView.SetSortMode(sortMode)
+	}
+}
+
+// copyEntryTo adds a copy of entry to the target file unless it already has the
+// key, and reports the outcome in the status bar
+func (m *Model) copyEntryTo(entry *model.Entry, targetFile *model.EnvFile, overwrite bool) tea.Cmd {
+	target := filepath.Base(targetFile.Path)
+	result, err := m.copyInto(entry, targetFile, overwrite)
+	switch {
+	case result == copyReadOnly:
+		return m.listView.ShowStatus(fmt.Sprintf("%s is read-only - not copied", target), true)
+	case errors.Is(err, errSaveHeld):
+		// The conflict prompt explains why nothing was saved
+		return nil
+	case err != nil:
+		return m.listView.ShowStatus(fmt.Sprintf("Could not save %s: %v", target, err), true)
+	case result == copySkipped:
+		return m.listView.ShowStatus(fmt.Sprintf("%s already has %s - not copied (o to overwrite)", target, entry.Key), true)
+	case result == copyUnchanged:
+		return m.listView.ShowStatus(fmt.Sprintf("%s already has the same %s", target, entry.Key), false)
+	case result == copyUpdated:
+		return m.savedStatus(targetFile, fmt.Sprintf("Overwrote %s in %s", entry.Key, target))
+	}
+	return m.savedStatus(targetFile, fmt.Sprintf("Copied %s to %s", entry.Key, target))
+}
+
+// copyResult is what copying an entry into a file did
+type copyResult int
+
+const (
+	copyAdded     copyResult = iota
+	copyUpdated              // The key existed and its value was overwritten
+	copyUnchanged            // The key existed with the same value
+	copySkipped              // The key existed and overwriting was off
+	copyReadOnly             // The target file is read-only
+)
+
+// copyInto copies the entry into targetFile, overwriting an existing value if
+// overwrite is set, and saves the file. The change is tracked for undo on the
+// target file.
+func (m *Model) copyInto(entry *model.Entry, targetFile *model.EnvFile, overwrite bool) (copyResult, error) {
+	if targetFile.ReadOnly {
+		return copyReadOnly, nil
+	}
+	existing := targetFile.GetEntry(entry.Key)
+	switch {
+	case existing == nil:
+		added := &model.Entry{
+			Type:     model.KeyValueEntry,
+			Key:      entry.Key,
+			Value:    entry.Value,
+			IsSecret: entry.IsSecret,
+		}
+		targetFile.AddEntry(added)
+		m.trackChangeIn(targetFile, model.ChangeTypeAdd, added, "")
+		return copyAdded, m.saveFile(targetFile)
+	case !overwrite:
+		return copySkipped, nil
+	case existing.Value == entry.Value:
+		return copyUnchanged, nil
+	}
+	oldValue := existing.Value
+	targetFile.UpdateEntry(entry.Key, entry.Value)
+	m.trackChangeIn(targetFile, model.ChangeTypeUpdate, existing, oldValue)
+	return copyUpdated, m.saveFile(targetFile)
+}
+
+// copyEntryToAll copies the entry into every other open file that lacks the key,
+// saving each file it changes
+func (m *Model) copyEntryToAll(entry *model.Entry, overwrite bool) tea.Cmd {
+	copied, updated, skipped, readOnly, held := 0, 0, 0, 0, 0
+	var failed []string
+	// One undo reverts the copy in every file
+	m.changeStack.BeginTransaction()
+	for i, targetFile := range m.envFiles {
+		if i == m.currentFileIndex {
+			continue
+		}
+		result, err := m.copyInto(entry, targetFile, overwrite)
+		if errors.Is(err, errSaveHeld) {
+			held++
+			continue
+		}
+		if err != nil {
+			logging.Errorf("copy %s to %s failed: %v", entry.Key, targetFile.Path, err)
+			failed = append(failed, filepath.Base(targetFile.Path))
+			continue
+		}
+		switch result {
+		case copyAdded:
+			copied++
+		case copyUpdated:
+			updated++
+		case copyReadOnly:
+			readOnly++
+		default:
+			skipped++
+		}
+	}
+	m.changeStack.Commit()
+
+	status := fmt.Sprintf("Added %s to %d files", entry.Key, copied)
+	if copied == 1 {
+		status = fmt.Sprintf("Added %s to 1 file", entry.Key)
+	}
+	if updated > 0 {
+		status += fmt.Sprintf(", overwrote it in %d", updated)
+	}
+	if skipped > 0 {
+		status += fmt.Sprintf(", %d already had it", skipped)
+	}
+	if readOnly > 0 {
+		status += fmt.Sprintf(", %d read-only", readOnly)
+	}
+	if held > 0 {
+		status += fmt.Sprintf(", %d not saved yet (changed on disk)", held)
+	}
+	if len(failed) > 0 {
+		return m.listView.ShowStatus(fmt.Sprintf("%s - could not save %s", status, strings.Join(failed, ", ")), true)
+	}
+	return m.listView.ShowStatus(status, false)
+}
+
+// savedStatus reports a change that was just written in the status bar
+func (m *Model) savedStatus(envFile *model.EnvFile, change string) tea.Cmd {
+	if m.hasConflict(envFile) {
+		// Undo and redo keep going when a save is held back; the conflict
+		// prompt explains why this file was not saved
+		return nil
+	}
+	if envFile.Path == storage.StdinPath {
+		return m.listView.ShowStatus(change+" (stdin input is not saved)", false)
+	}
+	return m.listView.ShowStatus(fmt.Sprintf("%s - saved %s", change, filepath.Base(envFile.Path)), false)
+}
+
+// sameOrder returns true if both entry lists hold the same lines in the same order
+func sameOrder(a, b []*model.Entry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// currentKeys returns the keys of the current env file in file order
+func (m Model) currentKeys() []string {
+	var keys []string
+	if envFile := m.GetCurrentEnvFile(); envFile != nil {
+		for _, entry := range envFile.FilterEntries("") {
+			keys = append(keys, entry.Key)
+		}
+	}
+	return keys
+}
+
+// validate returns the issues found in envFile, including schema violations
+func (m Model) validate(envFile *model.EnvFile) []model.ValidationIssue {
+	return append(envFile.Validate(), envFile.ValidateSchema(m.schema)...)
+}
+
+// applyLogging turns on diagnostic logging when a log file is given, either as
+// an option or through the environment
+func applyLogging(path, levelName string) error {
+	if path == "" {
+		return logging.EnableFromEnv()
+	}
+	level := logging.LevelDebug
+	if levelName != "" {
+		var err error
+		if level, err = logging.ParseLevel(levelName); err != nil {
+			return err
+		}
+	}
+	return logging.Enable(path, level)
+}
+
+// applyKeyBindings loads custom key bindings, or restores the built-in keys when
+// no file is given. Unknown actions and keys bound twice are reported.
+func applyKeyBindings(path string) error {
+	var bindings views.KeyBindings
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read key bindings: %w", err)
+		}
+		if bindings, err = views.ParseKeyBindings(string(data)); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := views.SetKeyBindings(bindings); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// applyTemplates loads user templates from path, or from the default location
+// when no path is given; a missing default file just leaves the built-ins
+func applyTemplates(path string) error {
+	if path == "" {
+		defaultPath, err := views.DefaultTemplatesPath()
+		if err == nil {
+			_, err = os.Stat(defaultPath)
+		}
+		if err != nil {
+			views.SetUserTemplates(nil)
+			return nil
+		}
+		path = defaultPath
+	}
+	templates, err := views.LoadTemplates(path)
+	if err != nil {
+		return err
+	}
+	views.SetUserTemplates(templates)
+	return nil
+}
+
+// writePatch writes the differences of msg to its path, readable only by the
+// owner when it holds real secret values
+func writePatch(msg views.ExportDiffMsg) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", msg.Header)
+	if err := views.ExportDiff(msg.Diffs, &buf, msg.MaskSecrets); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if !msg.MaskSecrets {
+		perm = 0600
+	}
+	return os.WriteFile(msg.Path, buf.Bytes(), perm)
+}
+
+// applyTheme selects the color theme. Turning colors off wins over the theme
+// asked for, as NO_COLOR is meant to.
+func applyTheme(name string, noColor bool) error {
+	theme, err := styles.ParseTheme(name)
+	if err != nil {
+		return err
+	}
+	if noColor || styles.NoColorRequested() {
+		theme = styles.ThemeMonochrome
+	}
+	styles.SetTheme(theme)
+	return nil
+}
+
+// applyCategoryRules loads custom category rules and their colors, or restores
+// the built-in categories when no file is given
+func applyCategoryRules(path string) error {
+	var rules model.CategoryRules
+	if path != "" {
+		var err error
+		if rules, err = storage.LoadCategoryRules(path); err != nil {
+			return err
+		}
+	}
+
+	colors := make(map[string]string)
+	for _, rule := range rules {
+		if rule.Color != "" {
+			colors[rule.Category] = rule.Color
+		}
+	}
+	model.SetCategoryRules(rules)
+	styles.SetCategoryColors(colors)
+	return nil
+}
+
+// errSaveHeld is returned by saveFile when the file changed on disk since it was
+// read. Nothing was written; the conflict prompt asks the user to reload or
+// overwrite, so callers must not report the change as saved.
+var errSaveHeld = errors.New("changed on disk, save held back")
+
+// saveFile writes envFile to disk. If the file was changed by another program since
+// it was read, the save is held back with errSaveHeld and the file is queued for
+// the conflict prompt. Files read from stdin are never saved; their edits stay in memory.
+func (m *Model) saveFile(envFile *model.EnvFile) error {
+	err := storage.WriteFile(envFile)
+	if err == nil {
+		m.refreshGitInfo(envFile.Path)
+	}
+	if err == nil && m.failedSave == envFile.Path {
+		m.bannerErr = nil
+		m.failedSave = ""
+	}
+	if errors.Is(err, storage.ErrExternalChange) {
+		logging.Debugf("External change detected: %v", err)
+		if !m.hasConflict(envFile) {
+			m.conflictFiles = append(m.conflictFiles, envFile)
+		}
+		return errSaveHeld
+	}
+	if errors.Is(err, storage.ErrStdinReadOnly) {
+		logging.Debugf("Skipped save of stdin input")
+		return nil
+	}
+	if err == nil && m.options.TrackModified {
+		if err := storage.SaveLastModified(envFile); err != nil {
+			logging.Errorf("Failed to save metadata of %s: %v", envFile.Path, err)
+		}
+	}
+	return err
+}
+
+// reportSaveError shows a failed write in the error banner. The change stays in
+// memory, so the user can fix the cause and save again.
+func (m *Model) reportSaveError(envFile *model.EnvFile, err error) {
+	if errors.Is(err, errSaveHeld) {
+		// The conflict prompt explains why nothing was saved
+		return
+	}
+	logging.Errorf("Save of %s failed: %v", envFile.Path, err)
+	m.bannerErr = fmt.Errorf("could not save %s: %w", filepath.Base(envFile.Path), err)
+	m.failedSave = envFile.Path
+}
+
+// hasConflict reports whether envFile is waiting in the conflict prompt
+func (m *Model) hasConflict(envFile *model.EnvFile) bool {
+	for _, conflict := range m.conflictFiles {
+		if conflict == envFile {
+			return true
+		}
+	}
+	return false
+}
+
+// handleConflictKeys resolves the oldest pending external-change conflict
+func (m Model) handleConflictKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	envFile := m.conflictFiles[0]
+	m.watchNotice = ""
+	switch msg.String() {
+	case "o":
+		// Keep our version and overwrite the external edits
+		m.conflictFiles = m.conflictFiles[1:]
+		if err := storage.ForceWriteFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
+		}
+		m.refreshGitInfo(envFile.Path)
+	case "r":
+		// Discard our unsaved edits and load what is on disk
+		m.conflictFiles = m.conflictFiles[1:]
+		reloaded, err := storage.ReadFile(envFile.Path)
+		if err != nil {
+			m.bannerErr = fmt.Errorf("could not reload %s: %w", filepath.Base(envFile.Path), err)
+			return m, nil
+		}
+		loadIncludes(reloaded)
+		reloaded.LastModified = envFile.LastModified
+		for i, ef := range m.envFiles {
+			if ef == envFile {
+				m.envFiles[i] = reloaded
+				m.originalStates[i] = reloaded.Clone()
+			}
+		}
+		m.refreshGitInfo(reloaded.Path)
+		m.viewMode = ViewModeList
+	default:
+		return m, nil
+	}
+
+	m.refreshListView()
+	return m, nil
+}
+
+// TrackChange records a change for undo/redo
+func (m *Model) TrackChange(changeType model.ChangeType, entry *model.Entry, oldValue string) {
+	m.trackChangeIn(m.GetCurrentEnvFile(), changeType, entry, oldValue)
+}
+
+// trackChangeIn records a change made to envFile, which need not be the current file
+func (m *Model) trackChangeIn(envFile *model.EnvFile, changeType model.ChangeType, entry *model.Entry, oldValue string) {
+	if m.changeStack == nil || envFile == nil {
+		return
+	}
+
 	change := model.Change{
 		Type:     changeType,
 		FilePath: envFile.Path,
 		Entry: &model.Entry{
-			Type:     entry.Type,
-			Key:      entry.Key,
-			Value:    entry.Value,
-			Comment:  entry.Comment,
-			Line:     entry.Line,
-			Exported: entry.Exported,
-			IsSecret: entry.IsSecret,
+			Type:       entry.Type,
+			Key:        entry.Key,
+			Value:      entry.Value,
+			Comment:    entry.Comment,
+			Line:       entry.Line,
+			Exported:   entry.Exported,
+			IsSecret:   entry.IsSecret,
+			QuoteStyle: entry.QuoteStyle,
+
+			LeadingComments: entry.LeadingComments,
 		},
-		OldValue: oldValue,
+		OldValue:  oldValue,
+		Timestamp: time.Now(),
 	}
 
 	m.changeStack.Push(change)
-	logDebug(fmt.Sprintf("Tracked change: %v for key %s", changeType, entry.Key))
+	envFile.MarkModified(entry.Key, change.Timestamp)
+	logging.Debugf("Tracked change: %v for key %s", changeType, entry.Key)
+
+	if m.options.PersistHistory {
+		if err := storage.AppendHistory(envFile.Path, change); err != nil {
+			logging.Debugf("Failed to persist history: %v", err)
+		}
+	}
 }
 
-// Undo reverts the last change
+// trackReorder records a reorder of the current file for undo/redo; previous
+// holds copies of the entries in their old order
+func (m *Model) trackReorder(previous []*model.Entry) {
+	m.trackSnapshot(model.ChangeTypeReorder, previous)
+}
+
+// trackSnapshot records a change to the current file's entries as a whole (a
+// reorder or dedupe); previous holds copies of the entries before it
+func (m *Model) trackSnapshot(changeType model.ChangeType, previous []*model.Entry) {
+	envFile := m.GetCurrentEnvFile()
+	if m.changeStack == nil || envFile == nil {
+		return
+	}
+
+	change := model.Change{
+		Type:      changeType,
+		FilePath:  envFile.Path,
+		Snapshot:  previous,
+		Timestamp: time.Now(),
+	}
+	if changeType == model.ChangeTypeDedupe {
+		change.Result = envFile.Clone().Entries
+	}
+	m.changeStack.Push(change)
+
+	if m.options.PersistHistory {
+		if err := storage.AppendHistory(envFile.Path, change); err != nil {
+			logging.Debugf("Failed to persist history: %v", err)
+		}
+	}
+}
+
+// changedFile returns the open file a tracked change was made in, or nil if it
+// has been closed
+func (m *Model) changedFile(change model.Change) *model.EnvFile {
+	for _, envFile := range m.envFiles {
+		if envFile.Path == change.FilePath {
+			return envFile
+		}
+	}
+	return nil
+}
+
+// containsFile reports whether files holds envFile
+func containsFile(files []*model.EnvFile, envFile *model.EnvFile) bool {
+	for _, f := range files {
+		if f == envFile {
+			return true
+		}
+	}
+	return false
+}
+
+// Undo reverts the last change, or the whole last transaction
 func (m *Model) Undo() bool {
 	if m.changeStack == nil || !m.changeStack.CanUndo() {
 		return false
 	}
 
-	change, ok := m.changeStack.Undo()
+	changes, ok := m.changeStack.Undo()
 	if !ok {
 		return false
 	}
 
-	envFile := m.GetCurrentEnvFile()
-	if envFile == nil {
-		return false
-	}
+	// A transaction is reverted most recent change first. Changes apply to the
+	// file they were made in, e.g. the target of a copy.
+	var touched []*model.EnvFile
+	for _, change := range changes {
+		envFile := m.changedFile(change)
+		if envFile == nil {
+			logging.Debugf("Undo: %s is no longer open", change.FilePath)
+			continue
+		}
+		if !containsFile(touched, envFile) {
+			touched = append(touched, envFile)
+		}
+		switch change.Type {
+		case model.ChangeTypeAdd:
+			// Undo add = delete the entry
+			envFile.DeleteEntry(change.Entry.Key)
+			logging.Debugf("Undo add: deleted %s", change.Entry.Key)
+		case model.ChangeTypeUpdate:
+			// Undo update = restore old value
+			envFile.UpdateEntry(change.Entry.Key, change.OldValue)
+			logging.Debugf("Undo update: restored %s", change.Entry.Key)
+		case model.ChangeTypeRename:
+			// Undo rename = restore the old key
+			envFile.RenameEntry(change.Entry.Key, change.OldValue)
+			logging.Debugf("Undo rename: %s back to %s", change.Entry.Key, change.OldValue)
+		case model.ChangeTypeDelete:
+			// Undo delete = re-add the entry
+			envFile.AddEntry(&model.Entry{
+				Type:       change.Entry.Type,
+				Key:        change.Entry.Key,
+				Value:      change.Entry.Value,
+				Comment:    change.Entry.Comment,
+				Line:       change.Entry.Line,
+				Exported:   change.Entry.Exported,
+				IsSecret:   change.Entry.IsSecret,
+				QuoteStyle: change.Entry.QuoteStyle,
 
-	switch change.Type {
-	case model.ChangeTypeAdd:
-		// Undo add = delete the entry
-		envFile.DeleteEntry(change.Entry.Key)
-		logDebug(fmt.Sprintf("Undo add: deleted %s", change.Entry.Key))
-	case model.ChangeTypeUpdate:
-		// Undo update = restore old value
-		envFile.UpdateEntry(change.Entry.Key, change.OldValue)
-		logDebug(fmt.Sprintf("Undo update: restored %s to %s", change.Entry.Key, change.OldValue))
-	case model.ChangeTypeDelete:
-		// Undo delete = re-add the entry
-		envFile.AddEntry(&model.Entry{
-			Type:     change.Entry.Type,
-			Key:      change.Entry.Key,
-			Value:    change.Entry.Value,
-			Comment:  change.Entry.Comment,
-			Line:     change.Entry.Line,
-			Exported: change.Entry.Exported,
-			IsSecret: change.Entry.IsSecret,
-		})
-		logDebug(fmt.Sprintf("Undo delete: restored %s", change.Entry.Key))
+				LeadingComments: change.Entry.LeadingComments,
+			})
+			logging.Debugf("Undo delete: restored %s", change.Entry.Key)
+		case model.ChangeTypeExport:
+			// Undo export toggle = restore the previous flag
+			envFile.SetExported(change.Entry.Key, !change.Entry.Exported)
+			logging.Debugf("Undo export: %s", change.Entry.Key)
+		case model.ChangeTypeReorder, model.ChangeTypeDedupe:
+			// Undo reorder or dedupe = restore the previous entries (copied, so redo can apply it again)
+			envFile.Entries = (&model.EnvFile{Entries: change.Snapshot}).Clone().Entries
+			logging.Debugf("Undo reorder: restored previous order")
+		}
+		if change.Entry != nil {
+			// Undoing changes the key again; a rename is back under its old name
+			changedKey := change.Entry.Key
+			if change.Type == model.ChangeTypeRename {
+				changedKey = change.OldValue
+			}
+			envFile.MarkModified(changedKey, time.Now())
+		}
 	}
 
-	// Save the file
-	if err := storage.WriteFile(envFile); err != nil {
-		m.err = err
-		return false
+	// Save the changed files
+	for _, envFile := range touched {
+		if err := m.saveFile(envFile); err != nil && !errors.Is(err, errSaveHeld) {
+			m.reportSaveError(envFile, err)
+			return false
+		}
 	}
 
 	// Refresh the list view
-	oldWidth := m.listView.Width()
-	oldHeight := m.listView.Height()
-	m.listView = views.NewListView(envFile.FilterEntries(""))
-	m.listView.SetSize(oldWidth, oldHeight)
-	m.validationIssues = envFile.Validate()
+	m.refreshListView()
 
 	return true
 }
 
-// Redo re-applies the last undone change
+// Redo re-applies the last undone change or transaction
 func (m *Model) Redo() bool {
 	if m.changeStack == nil || !m.changeStack.CanRedo() {
 		return false
 	}
 
-	change, ok := m.changeStack.Redo()
+	changes, ok := m.changeStack.Redo()
 	if !ok {
 		return false
 	}
 
-	envFile := m.GetCurrentEnvFile()
-	if envFile == nil {
-		return false
-	}
+	// A transaction is re-applied in its original order. Changes apply to the
+	// file they were made in, e.g. the target of a copy.
+	var touched []*model.EnvFile
+	for _, change := range changes {
+		envFile := m.changedFile(change)
+		if envFile == nil {
+			logging.Debugf("Redo: %s is no longer open", change.FilePath)
+			continue
+		}
+		if !containsFile(touched, envFile) {
+			touched = append(touched, envFile)
+		}
+		switch change.Type {
+		case model.ChangeTypeAdd:
+			// Redo add = add the entry back
+			envFile.AddEntry(&model.Entry{
+				Type:       change.Entry.Type,
+				Key:        change.Entry.Key,
+				Value:      change.Entry.Value,
+				Comment:    change.Entry.Comment,
+				Line:       change.Entry.Line,
+				Exported:   change.Entry.Exported,
+				IsSecret:   change.Entry.IsSecret,
+				QuoteStyle: change.Entry.QuoteStyle,
 
-	switch change.Type {
-	case model.ChangeTypeAdd:
-		// Redo add = add the entry back
-		envFile.AddEntry(&model.Entry{
-			Type:     change.Entry.Type,
-			Key:      change.Entry.Key,
-			Value:    change.Entry.Value,
-			Comment:  change.Entry.Comment,
-			Line:     change.Entry.Line,
-			Exported: change.Entry.Exported,
-			IsSecret: change.Entry.IsSecret,
-		})
-		logDebug(fmt.Sprintf("Redo add: restored %s", change.Entry.Key))
-	case model.ChangeTypeUpdate:
-		// Redo update = apply the new value
-		envFile.UpdateEntry(change.Entry.Key, change.Entry.Value)
-		logDebug(fmt.Sprintf("Redo update: set %s to %s", change.Entry.Key, change.Entry.Value))
-	case model.ChangeTypeDelete:
-		// Redo delete = delete the entry
-		envFile.DeleteEntry(change.Entry.Key)
-		logDebug(fmt.Sprintf("Redo delete: removed %s", change.Entry.Key))
-	}
-
-	// Save the file
-	if err := storage.WriteFile(envFile); err != nil {
-		m.err = err
-		return false
+				LeadingComments: change.Entry.LeadingComments,
+			})
+			logging.Debugf("Redo add: restored %s", change.Entry.Key)
+		case model.ChangeTypeUpdate:
+			// Redo update = apply the new value
+			envFile.UpdateEntry(change.Entry.Key, change.Entry.Value)
+			logging.Debugf("Redo update: set %s", change.Entry.Key)
+		case model.ChangeTypeRename:
+			// Redo rename = apply the new key
+			envFile.RenameEntry(change.OldValue, change.Entry.Key)
+			logging.Debugf("Redo rename: %s to %s", change.OldValue, change.Entry.Key)
+		case model.ChangeTypeDelete:
+			// Redo delete = delete the entry
+			envFile.DeleteEntry(change.Entry.Key)
+			logging.Debugf("Redo delete: removed %s", change.Entry.Key)
+		case model.ChangeTypeExport:
+			// Redo export toggle = apply the new flag
+			envFile.SetExported(change.Entry.Key, change.Entry.Exported)
+			logging.Debugf("Redo export: %s", change.Entry.Key)
+		case model.ChangeTypeDedupe:
+			// Redo dedupe = restore the entries left by it
+			envFile.Entries = (&model.EnvFile{Entries: change.Result}).Clone().Entries
+			logging.Debugf("Redo dedupe: removed repeated keys")
+		case model.ChangeTypeReorder:
+			// Redo reorder = sort again
+			envFile.SortEntries()
+			logging.Debugf("Redo reorder: sorted entries")
+		}
+		if change.Entry != nil {
+			envFile.MarkModified(change.Entry.Key, time.Now())
+		}
+	}
+
+	// Save the changed files
+	for _, envFile := range touched {
+		if err := m.saveFile(envFile); err != nil && !errors.Is(err, errSaveHeld) {
+			m.reportSaveError(envFile, err)
+			return false
+		}
 	}
 
 	// Refresh the list view
-	oldWidth := m.listView.Width()
-	oldHeight := m.listView.Height()
-	m.listView = views.NewListView(envFile.FilterEntries(""))
-	m.listView.SetSize(oldWidth, oldHeight)
-	m.validationIssues = envFile.Validate()
+	m.refreshListView()
 
 	return true
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	if m.options.Watch && len(m.envFiles) > 0 {
+		return tea.Batch(watchCmd(), m.startupCmd)
+	}
+	return m.startupCmd
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case views.BulkDeleteMsg:
-		// Handle bulk delete
+		// Delete the confirmed keys, whether one entry or a bulk selection
 		envFile := m.GetCurrentEnvFile()
 		if envFile != nil && len(msg.Keys) > 0 {
+			// One undo restores every deleted entry
+			m.changeStack.BeginTransaction()
 			for _, key := range msg.Keys {
 				entry := envFile.GetEntry(key)
 				if entry != nil {
@@ -300,53 +1123,371 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					envFile.DeleteEntry(key)
 				}
 			}
-			if err := storage.WriteFile(envFile); err != nil {
-				m.err = err
+			m.changeStack.Commit()
+			if err := m.saveFile(envFile); err != nil {
+				m.reportSaveError(envFile, err)
+				return m, nil
+			}
+			m.refreshListView()
+			deleted := msg.Keys[0]
+			if len(msg.Keys) > 1 {
+				deleted = fmt.Sprintf("%d entries", len(msg.Keys))
+			}
+			return m, m.savedStatus(envFile, fmt.Sprintf("Deleted %s (u to undo)", deleted))
+		}
+		return m, nil
+	case views.BulkUpdateMsg:
+		// Apply a find-and-replace (or common value) to every selected entry
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil || len(msg.Keys) == 0 {
+			return m, nil
+		}
+		updated := 0
+		m.changeStack.BeginTransaction()
+		for _, key := range msg.Keys {
+			entry := envFile.GetEntry(key)
+			if entry == nil {
+				continue
+			}
+			newValue := msg.Replace
+			if msg.Find != "" {
+				newValue = strings.ReplaceAll(entry.Value, msg.Find, msg.Replace)
+			}
+			if newValue == entry.Value {
+				continue
+			}
+			oldValue := entry.Value
+			envFile.UpdateEntry(key, newValue)
+			m.TrackChange(model.ChangeTypeUpdate, entry, oldValue)
+			updated++
+		}
+		m.changeStack.Commit()
+		if updated > 0 {
+			if err := m.saveFile(envFile); err != nil {
+				m.reportSaveError(envFile, err)
 				return m, nil
 			}
-			oldWidth := m.listView.Width()
-			oldHeight := m.listView.Height()
-			m.listView = views.NewListView(envFile.FilterEntries(""))
-			m.listView.SetSize(oldWidth, oldHeight)
-			m.validationIssues = envFile.Validate()
+		}
+		m.refreshListView()
+		return m, m.listView.ShowStatus(fmt.Sprintf("Updated %d of %d selected values", updated, len(msg.Keys)), false)
+	case watchTickMsg:
+		return m, m.checkWatchedFiles()
+	case watchCheckedMsg:
+		return m.handleWatchChecked(msg)
+	case FileChangedMsg:
+		return m.handleFileChanged(msg)
+	case views.GitCommitMsg:
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		if err := storage.GitCommitFile(envFile.Path, msg.Message); err != nil {
+			return m, m.listView.ShowStatus(err.Error(), true)
+		}
+		m.refreshGitInfo(envFile.Path)
+		return m, m.listView.ShowStatus(fmt.Sprintf("Committed %s", filepath.Base(envFile.Path)), false)
+	case views.ExportEntriesMsg:
+		if err := storage.ExportEntries(msg.Entries, storage.FormatForPath(msg.Path), msg.Path); err != nil {
+			return m, m.listView.ShowStatus(fmt.Sprintf("Export failed: %v", err), true)
+		}
+		return m, m.listView.ShowStatus(fmt.Sprintf("Exported %d entries to %s", len(msg.Entries), msg.Path), false)
+	case views.ExportDiffMsg:
+		status, isErr := fmt.Sprintf("Wrote %d differences to %s", len(msg.Diffs), msg.Path), false
+		if err := writePatch(msg); err != nil {
+			status, isErr = fmt.Sprintf("Patch export failed: %v", err), true
+		} else if !msg.MaskSecrets {
+			status += " (with real secret values)"
+		}
+		if m.viewMode == ViewModeCompare {
+			m.compareView.SetMessage(status)
+			return m, nil
+		}
+		m.viewMode = ViewModeList
+		return m, m.listView.ShowStatus(status, isErr)
+	case views.ToggleExportMsg:
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		entry := envFile.GetEntry(msg.Key)
+		if entry == nil {
+			return m, nil
+		}
+		envFile.SetExported(msg.Key, !entry.Exported)
+		m.TrackChange(model.ChangeTypeExport, entry, "")
+		if err := m.saveFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
+			return m, nil
+		}
+		m.refreshListView()
+		change := fmt.Sprintf("Exported %s", msg.Key)
+		if !entry.Exported {
+			change = fmt.Sprintf("%s is no longer exported", msg.Key)
+		}
+		return m, m.savedStatus(envFile, change)
+	case views.BulkExportToggleMsg:
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil || len(msg.Keys) == 0 {
+			return m, nil
+		}
+		changed := 0
+		m.changeStack.BeginTransaction()
+		for _, key := range msg.Keys {
+			entry := envFile.GetEntry(key)
+			if entry == nil || entry.Exported == msg.Exported {
+				continue
+			}
+			envFile.SetExported(key, msg.Exported)
+			m.TrackChange(model.ChangeTypeExport, entry, "")
+			changed++
+		}
+		m.changeStack.Commit()
+		if changed == 0 {
+			return m, nil
+		}
+		if err := m.saveFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
+			return m, nil
+		}
+		m.refreshListView()
+		change := fmt.Sprintf("Exported %d of %d selected keys", changed, len(msg.Keys))
+		if !msg.Exported {
+			change = fmt.Sprintf("%d of %d selected keys are no longer exported", changed, len(msg.Keys))
+		}
+		return m, m.savedStatus(envFile, change)
+	case views.DedupeMsg:
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		previous := envFile.Clone().Entries
+		removed := envFile.Deduplicate(msg.Strategy)
+		if removed == 0 {
+			return m, m.listView.ShowStatus("No repeated keys", false)
+		}
+		m.trackSnapshot(model.ChangeTypeDedupe, previous)
+		if err := m.saveFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
+			return m, nil
+		}
+		m.refreshListView()
+		return m, m.savedStatus(envFile, fmt.Sprintf("Removed %d repeated entries (%s, u to undo)", removed, msg.Strategy))
+	case views.InsertBundleMsg:
+		m.viewMode = ViewModeList
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		var added, existing []string
+		// One undo removes the whole bundle
+		m.changeStack.BeginTransaction()
+		for _, template := range msg.Bundle.Templates {
+			if envFile.GetEntry(template.Key) != nil {
+				existing = append(existing, template.Key)
+				continue
+			}
+			entry := &model.Entry{
+				Type:     model.KeyValueEntry,
+				Key:      template.Key,
+				Value:    template.Value,
+				IsSecret: parser.IsSecretKey(template.Key),
+			}
+			envFile.AddEntry(entry)
+			m.TrackChange(model.ChangeTypeAdd, entry, "")
+			added = append(added, template.Key)
+		}
+		m.changeStack.Commit()
+		if len(added) == 0 {
+			return m, m.listView.ShowStatus(fmt.Sprintf("The file already has every key of %s", msg.Bundle.Name), false)
+		}
+		if err := m.saveFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
+			return m, nil
+		}
+		m.refreshListView()
+		change := fmt.Sprintf("Added %d keys from %s", len(added), msg.Bundle.Name)
+		if len(existing) > 0 {
+			change += fmt.Sprintf(", skipped %s (u to undo)", strings.Join(existing, ", "))
+		} else {
+			change += " (u to undo)"
+		}
+		return m, m.savedStatus(envFile, change)
+	case views.SortFileMsg:
+		// Sort the file itself, unlike the view-only sort modes
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		previous := envFile.Clone().Entries
+		envFile.SortEntries()
+		if sameOrder(previous, envFile.Entries) {
+			return m, m.listView.ShowStatus("Keys are already in alphabetical order", false)
+		}
+		m.trackReorder(previous)
+		if err := m.saveFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
+			return m, nil
+		}
+		m.refreshListView()
+		return m, m.listView.ShowStatus(fmt.Sprintf("Sorted %s alphabetically (u to undo)", filepath.Base(envFile.Path)), false)
+	case views.BackupNowMsg:
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		if envFile.Path == storage.StdinPath {
+			return m, m.listView.ShowStatus(storage.ErrStdinReadOnly.Error(), true)
+		}
+		if err := storage.CreateBackup(envFile.Path); err != nil {
+			return m, m.listView.ShowStatus(fmt.Sprintf("Backup failed: %v", err), true)
+		}
+		return m, m.listView.ShowStatus(fmt.Sprintf("Backed up %s", filepath.Base(envFile.Path)), false)
+	case views.GitIgnoreMsg:
+		envFile := m.GetCurrentEnvFile()
+		if envFile == nil {
+			return m, nil
+		}
+		if !storage.IsGitRepository(envFile.Path) {
+			return m, m.listView.ShowStatus(fmt.Sprintf("%s is not in a git repository", filepath.Base(envFile.Path)), true)
+		}
+		if err := storage.AddToGitignore(envFile.Path); err != nil {
+			return m, m.listView.ShowStatus(err.Error(), true)
+		}
+		// A .gitignore rule can match the other open files too
+		m.refreshGitInfo()
+		return m, m.listView.ShowStatus(fmt.Sprintf("Added %s to .gitignore", filepath.Base(envFile.Path)), false)
+	case views.StatusTimeoutMsg, views.RevealTimeoutMsg, views.JumpTimeoutMsg:
+		var cmd tea.Cmd
+		m.listView, cmd = m.listView.Update(msg)
+		return m, cmd
+	case views.MergeFilesMsg:
+		// Apply the compare view's per-key decisions to both files
+		if readOnly := readOnlyMergeTarget(msg); readOnly != nil {
+			m.compareView.Refresh(fmt.Sprintf("%s is read-only - nothing merged", filepath.Base(readOnly.Path)))
+			return m, nil
+		}
+		if err := storage.MergeResolve(msg.Current, msg.Other, msg.IntoCurrent); err != nil {
+			m.compareView.Refresh(err.Error())
+			return m, nil
+		}
+		if err := storage.MergeResolve(msg.Other, msg.Current, msg.IntoOther); err != nil {
+			m.compareView.Refresh(err.Error())
+			return m, nil
+		}
+		now := time.Now()
+		for key := range msg.IntoCurrent {
+			msg.Current.MarkModified(key, now)
+		}
+		for key := range msg.IntoOther {
+			msg.Other.MarkModified(key, now)
+		}
+		// Both files are merged in memory, so both views are refreshed even when
+		// a save fails or is held by the conflict prompt
+		var unsaved []string
+		save := func(envFile *model.EnvFile, merged int) {
+			if merged == 0 {
+				return
+			}
+			if err := m.saveFile(envFile); err != nil && !errors.Is(err, errSaveHeld) {
+				m.reportSaveError(envFile, err)
+				unsaved = append(unsaved, filepath.Base(envFile.Path))
+			}
+		}
+		save(msg.Current, len(msg.IntoCurrent))
+		save(msg.Other, len(msg.IntoOther))
+		m.refreshListView()
+		status := fmt.Sprintf("Merged %d keys into %s and %d into %s",
+			len(msg.IntoCurrent), filepath.Base(msg.Current.Path), len(msg.IntoOther), filepath.Base(msg.Other.Path))
+		if len(unsaved) > 0 {
+			status += " - could not save " + strings.Join(unsaved, ", ")
+		}
+		m.compareView.Refresh(status)
+		return m, nil
+	case views.JumpToIssueMsg:
+		m.viewMode = ViewModeList
+		if msg.Issue.Key == "" {
+			return m, nil
+		}
+		if !m.listView.SelectKey(msg.Issue.Key) {
+			return m, m.listView.ShowStatus(fmt.Sprintf("%s is not in this file", msg.Issue.Key), true)
+		}
+		return m, nil
+	case views.FilePickedMsg:
+		if msg.Index < 0 || msg.Index >= len(m.envFiles) {
+			return m, nil
+		}
+		switch msg.Purpose {
+		case views.FileCopyTarget:
+			if selected := m.listView.GetSelected(); selected != nil && msg.Index != m.currentFileIndex {
+				return m, m.copyEntryTo(selected, m.envFiles[msg.Index], m.listView.CopyOverwrite())
+			}
+		case views.FileCompare:
+			if msg.Index != m.currentFileIndex {
+				m.compareView = views.NewCompareView(m.GetCurrentEnvFile(), m.envFiles[msg.Index])
+				m.compareView.SetSize(m.listView.Width(), m.listView.Height())
+				m.viewMode = ViewModeCompare
+			}
+		default:
+			logging.Debugf("Switching to file %d: %s", msg.Index+1, m.envFiles[msg.Index].Path)
+			m.SwitchToFile(msg.Index)
 		}
 		return m, nil
+	case views.OpenFileMsg:
+		paths, err := storage.ExpandPath(msg.Path)
+		for _, path := range paths {
+			if openErr := m.OpenFile(path); openErr != nil && err == nil {
+				err = openErr
+			}
+		}
+		if err != nil {
+			return m, m.listView.ShowStatus(fmt.Sprintf("Could not open %s: %v", msg.Path, err), true)
+		}
+		if len(paths) > 1 {
+			return m, m.listView.ShowStatus(fmt.Sprintf("Opened %d files", len(paths)), false)
+		}
+		return m, m.listView.ShowStatus("Opened "+m.GetCurrentFileName(), false)
+	case views.CloseFileMsg:
+		if len(m.envFiles) == 1 {
+			return m, m.listView.ShowStatus("This is the only open file - press q to quit", true)
+		}
+		name := m.GetCurrentFileName()
+		m.CloseFile(m.currentFileIndex)
+		return m, m.listView.ShowStatus("Closed "+name, false)
 	case views.CopyEntryMsg:
 		// Handle copy entry to another file
 		if msg.TargetIndex >= 0 && msg.TargetIndex < len(m.envFiles) && msg.Entry != nil {
-			targetFile := m.envFiles[msg.TargetIndex]
-			// Check if entry already exists
-			existing := targetFile.GetEntry(msg.Entry.Key)
-			if existing == nil {
-				newEntry := &model.Entry{
-					Type:     model.KeyValueEntry,
-					Key:      msg.Entry.Key,
-					Value:    msg.Entry.Value,
-					IsSecret: msg.Entry.IsSecret,
-				}
-				targetFile.AddEntry(newEntry)
-				if err := storage.WriteFile(targetFile); err != nil {
-					m.err = err
-				}
-			}
 			m.listView.SetCopyMode(false)
+			return m, m.copyEntryTo(msg.Entry, m.envFiles[msg.TargetIndex], m.listView.CopyOverwrite())
 		}
 		return m, nil
 	case tea.KeyMsg:
 		keyStr := msg.String()
-		logDebug(fmt.Sprintf("Key pressed: '%s' (Type: %v, Runes: %v)", msg.String(), msg.Type, msg.Runes))
+		// Keys are not logged as they are: typed into an input they spell out
+		// values, secrets included. The list logs the actions they resolve to.
 		// Global quit
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
 
+		// A save conflict must be resolved before anything else
+		if len(m.conflictFiles) > 0 {
+			return m.handleConflictKeys(msg)
+		}
+
+		// The first esc in the list dismisses the error banner
+		if keyStr == "esc" && m.bannerErr != nil && m.viewMode == ViewModeList && !m.listView.CapturesInput() {
+			m.bannerErr = nil
+			m.failedSave = ""
+			return m, nil
+		}
+
 		// File switching with number keys (only when NOT in copy mode)
-		if m.viewMode == ViewModeList && !m.listView.IsCopyMode() {
+		if m.viewMode == ViewModeList && !m.listView.IsCopyMode() && !m.listView.IsCompareMode() && !m.listView.CapturesInput() {
 			switch keyStr {
 			case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 				idx := int(keyStr[0] - '1') // Convert '1' to 0, '2' to 1, etc.
 				if idx < len(m.envFiles) {
-					logDebug(fmt.Sprintf("Switching to file %d: %s", idx+1, m.envFiles[idx].Path))
+					logging.Debugf("Switching to file %d: %s", idx+1, m.envFiles[idx].Path)
 					m.SwitchToFile(idx)
 					return m, nil
 				}
@@ -359,43 +1500,88 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case ViewModeEdit, ViewModeAdd:
 			// Handle enter/esc at app level first
 			keyStr := msg.String()
-			logDebug(fmt.Sprintf("Checking key: '%s'", keyStr))
-			if keyStr == "enter" || keyStr == "esc" {
-				logDebug("Key is enter or esc, calling handleEditKeys")
+			// In multiline mode Enter adds a line to the value and ctrl+s saves
+			isSave := (keyStr == "enter" && !m.editView.CapturesEnter()) || keyStr == "ctrl+s"
+			if (isSave || keyStr == "esc") && !m.editView.IsPickerActive() {
+				logging.Debugf("Key is enter or esc, calling handleEditKeys")
 				return m.handleEditKeys(msg)
 			}
 			// Pass other keys to edit view
-			logDebug("Passing key to editView")
+			logging.Debugf("Passing key to editView")
 			var cmd tea.Cmd
 			m.editView, cmd = m.editView.Update(msg)
-			logDebug(fmt.Sprintf("After editView.Update: key='%s' value='%s'", m.editView.GetKey(), m.editView.GetValue()))
+			logging.Debugf("After editView.Update: key='%s'", m.editView.GetKey())
 			return m, cmd
 		case ViewModeDiff:
 			// Handle esc/q to return to list view
-			if keyStr == "esc" || keyStr == "q" {
-				logDebug("Leaving diff view, returning to list")
+			if (keyStr == "esc" || keyStr == "q") && !m.diffView.CapturesInput() {
+				logging.Debugf("Leaving diff view, returning to list")
 				m.viewMode = ViewModeList
 				return m, nil
 			}
+			var cmd tea.Cmd
+			m.diffView, cmd = m.diffView.Update(msg)
+			return m, cmd
 		case ViewModeBackup:
-			// Handle esc/q to return to list view
-			if keyStr == "esc" || keyStr == "q" {
-				logDebug("Leaving backup view, returning to list")
+			// Handle esc/q to return to list view (dialogs and previews close themselves)
+			if (keyStr == "esc" || keyStr == "q") && m.backupView.IsListMode() {
+				logging.Debugf("Leaving backup view, returning to list")
+				m.viewMode = ViewModeList
 				// Reload the file in case a backup was restored
-				if envFile := m.GetCurrentEnvFile(); envFile != nil {
-					oldWidth := m.listView.Width()
-					oldHeight := m.listView.Height()
-					m.envFiles[m.currentFileIndex], _ = storage.ReadFile(envFile.Path)
-					m.listView = views.NewListView(m.envFiles[m.currentFileIndex].FilterEntries(""))
-					m.listView.SetSize(oldWidth, oldHeight)
+				envFile := m.GetCurrentEnvFile()
+				if envFile == nil {
+					return m, nil
+				}
+				reloaded, err := storage.ReadFile(envFile.Path)
+				if err != nil {
+					return m, m.listView.ShowStatus(fmt.Sprintf("Could not reload %s: %v", filepath.Base(envFile.Path), err), true)
+				}
+				loadIncludes(reloaded)
+				reloaded.LastModified = envFile.LastModified
+				m.envFiles[m.currentFileIndex] = reloaded
+				m.refreshGitInfo(reloaded.Path)
+				m.refreshListView()
+				if reloaded.OriginalHash() != envFile.OriginalHash() {
+					return m, m.listView.ShowStatus(fmt.Sprintf("Reloaded %s from the restored backup", filepath.Base(envFile.Path)), false)
 				}
-				m.viewMode = ViewModeList
 				return m, nil
 			}
 			// Pass other keys to backup view
 			var cmd tea.Cmd
 			m.backupView, cmd = m.backupView.Update(msg)
 			return m, cmd
+		case ViewModeHistory:
+			if keyStr == "esc" || keyStr == "q" {
+				m.viewMode = ViewModeList
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.historyView, cmd = m.historyView.Update(msg)
+			return m, cmd
+		case ViewModeCompare:
+			if (keyStr == "esc" || keyStr == "q") && !m.compareView.CapturesInput() {
+				m.viewMode = ViewModeList
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.compareView, cmd = m.compareView.Update(msg)
+			return m, cmd
+		case ViewModeIssues:
+			if keyStr == "esc" || keyStr == "q" {
+				m.viewMode = ViewModeList
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.issuesView, cmd = m.issuesView.Update(msg)
+			return m, cmd
+		case ViewModeEffective:
+			if keyStr == "esc" || keyStr == "q" {
+				m.viewMode = ViewModeList
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.effectiveView, cmd = m.effectiveView.Update(msg)
+			return m, cmd
 		}
 
 	case tea.WindowSizeMsg:
@@ -410,6 +1596,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.diffView.SetSize(msg.Width, msg.Height)
 			case ViewModeBackup:
 				m.backupView.SetSize(msg.Width, msg.Height)
+			case ViewModeHistory:
+				m.historyView.SetSize(msg.Width, msg.Height)
+			case ViewModeCompare:
+				m.compareView.SetSize(msg.Width, msg.Height)
+			case ViewModeIssues:
+				m.issuesView.SetSize(msg.Width, msg.Height)
+			case ViewModeEffective:
+				m.effectiveView.SetSize(msg.Width, msg.Height)
 			}
 			return m, cmd
 		}
@@ -420,7 +1614,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keyStr := msg.String()
-	logDebug(fmt.Sprintf("handleListKeys: key='%s'", keyStr))
+
+	// Search, prompts and questions take their own keys
+	if m.listView.CapturesInput() {
+		var cmd tea.Cmd
+		m.listView, cmd = m.listView.Update(msg)
+		return m, cmd
+	}
+
+	// In copy and compare mode the file picker reaches files past the ninth
+	if (m.listView.IsCopyMode() || m.listView.IsCompareMode()) && views.KeyAction(msg) == "files" {
+		m.listView.OpenFilePicker()
+		return m, nil
+	}
 
 	// Handle copy mode file selection
 	if m.listView.IsCopyMode() {
@@ -432,104 +1638,225 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			idx := int(keyStr[0] - '1')
 			if idx < len(m.envFiles) && idx != m.currentFileIndex {
 				// Copy the selected entry to the target file
-				selected := m.listView.GetSelected()
-				if selected != nil {
-					targetFile := m.envFiles[idx]
-					existing := targetFile.GetEntry(selected.Key)
-					if existing == nil {
-						newEntry := &model.Entry{
-							Type:     model.KeyValueEntry,
-							Key:      selected.Key,
-							Value:    selected.Value,
-							IsSecret: selected.IsSecret,
-						}
-						targetFile.AddEntry(newEntry)
-						if err := storage.WriteFile(targetFile); err != nil {
-							m.err = err
-						}
-					}
-				}
 				m.listView.SetCopyMode(false)
+				if selected := m.listView.GetSelected(); selected != nil {
+					return m, m.copyEntryTo(selected, m.envFiles[idx], m.listView.CopyOverwrite())
+				}
 				return m, nil
 			}
+		case "a":
+			// Copy the selected entry to every file that lacks it
+			m.listView.SetCopyMode(false)
+			if selected := m.listView.GetSelected(); selected != nil {
+				return m, m.copyEntryToAll(selected, m.listView.CopyOverwrite())
+			}
+			return m, nil
+		case "o":
+			m.listView.ToggleCopyOverwrite()
+			return m, nil
 		}
 		// In copy mode, only allow the above keys
 		return m, nil
 	}
 
-	switch keyStr {
-	case "q":
-		logDebug("'q' pressed - quitting")
+	// Handle compare mode file selection
+	if m.listView.IsCompareMode() {
+		switch keyStr {
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			idx := int(keyStr[0] - '1')
+			if idx < len(m.envFiles) && idx != m.currentFileIndex {
+				m.listView.SetCompareMode(false)
+				m.compareView = views.NewCompareView(m.GetCurrentEnvFile(), m.envFiles[idx])
+				m.compareView.SetSize(m.listView.Width(), m.listView.Height())
+				m.viewMode = ViewModeCompare
+				return m, nil
+			}
+			return m, nil
+		case "e":
+			if envFile := m.GetCurrentEnvFile(); envFile != nil {
+				m.listView.SetCompareMode(false)
+				m.compareView = views.NewEnvironCompareView(envFile, model.EnvironMap(os.Environ()))
+				m.compareView.SetSize(m.listView.Width(), m.listView.Height())
+				m.viewMode = ViewModeCompare
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.listView, cmd = m.listView.Update(msg)
+		return m, cmd
+	}
+
+	if envFile := m.GetCurrentEnvFile(); envFile != nil && envFile.ReadOnly && readOnlyActions[views.KeyAction(msg)] {
+		return m, m.listView.ShowStatus(fmt.Sprintf("%s is read-only (%s to unlock)", filepath.Base(envFile.Path), views.ReadOnlyKey()), true)
+	}
+	if selected := m.listView.GetSelected(); selected != nil && inheritedActions[views.KeyAction(msg)] && len(m.listView.GetSelectedItems()) == 0 {
+		if source := m.listView.InheritedFrom(selected); source != "" {
+			return m, m.listView.ShowStatus(fmt.Sprintf("%s comes from %s - edit it there, or add it here to override it", selected.Key, filepath.Base(source)), true)
+		}
+	}
+
+	switch views.KeyAction(msg) {
+	case "dedupe":
+		if envFile := m.GetCurrentEnvFile(); envFile != nil {
+			duplicates := envFile.DuplicateKeys()
+			if len(duplicates) == 0 {
+				return m, m.listView.ShowStatus("No repeated keys", false)
+			}
+			m.listView.AskDedupe(duplicates)
+		}
+		return m, nil
+	case "read-only":
+		if envFile := m.GetCurrentEnvFile(); envFile != nil {
+			envFile.ReadOnly = !envFile.ReadOnly
+			if envFile.ReadOnly {
+				return m, m.listView.ShowStatus(fmt.Sprintf("Locked %s - it is read-only", filepath.Base(envFile.Path)), false)
+			}
+			return m, m.listView.ShowStatus(fmt.Sprintf("Unlocked %s", filepath.Base(envFile.Path)), false)
+		}
+		return m, nil
+	case "quit":
+		logging.Debugf("'%s' pressed - quitting", keyStr)
 		return m, tea.Quit
-	case "a":
-		logDebug("'a' pressed - switching to add mode")
+	case "add":
+		logging.Debugf("'%s' pressed - switching to add mode", keyStr)
 		m.viewMode = ViewModeAdd
 		m.editView = views.NewEditView(views.EditModeAdd, nil, m.listView.Width())
+		m.editView.SetAvailableKeys(m.currentKeys())
+		if envFile := m.GetCurrentEnvFile(); envFile != nil {
+			m.editView.SetExampleFile(envFile.IsExample())
+		}
 		return m, m.editView.Init()
-	case "e":
-		logDebug("'e' pressed - switching to edit mode")
+	case "edit":
+		logging.Debugf("'%s' pressed - switching to edit mode", keyStr)
 		// Get selected entry and edit
 		if selected := m.listView.GetSelected(); selected != nil {
 			m.viewMode = ViewModeEdit
 			m.editView = views.NewEditView(views.EditModeEdit, selected, m.listView.Width())
+			m.editView.SetAvailableKeys(m.currentKeys())
+			if envFile := m.GetCurrentEnvFile(); envFile != nil {
+				m.editView.SetExampleFile(envFile.IsExample())
+			}
 			return m, m.editView.Init()
 		}
-	case "d":
-		logDebug("'d' pressed - deleting entry")
-		// Delete selected entry
-		envFile := m.GetCurrentEnvFile()
-		if selected := m.listView.GetSelected(); selected != nil && envFile != nil {
-			// Track the delete for undo
-			m.TrackChange(model.ChangeTypeDelete, selected, "")
-			envFile.DeleteEntry(selected.Key)
-			if err := storage.WriteFile(envFile); err != nil {
-				m.err = err
-				return m, nil
+	case "duplicate":
+		logging.Debugf("'%s' pressed - duplicating entry", keyStr)
+		if selected := m.listView.GetSelected(); selected != nil {
+			m.viewMode = ViewModeAdd
+			m.editView = views.NewEditView(views.EditModeDuplicate, selected, m.listView.Width())
+			m.editView.SetAvailableKeys(m.currentKeys())
+			if envFile := m.GetCurrentEnvFile(); envFile != nil {
+				m.editView.SetExampleFile(envFile.IsExample())
 			}
-			// Preserve dimensions when recreating list view
-			oldWidth := m.listView.Width()
-			oldHeight := m.listView.Height()
-			m.listView = views.NewListView(envFile.FilterEntries(""))
-			m.listView.SetSize(oldWidth, oldHeight)
-			m.validationIssues = envFile.Validate()
+			return m, m.editView.Init()
+		}
+	case "rename":
+		logging.Debugf("'%s' pressed - switching to rename mode", keyStr)
+		if selected := m.listView.GetSelected(); selected != nil {
+			m.viewMode = ViewModeEdit
+			m.editView = views.NewEditView(views.EditModeRename, selected, m.listView.Width())
+			return m, m.editView.Init()
+		}
+	case "delete":
+		logging.Debugf("'%s' pressed - deleting entry", keyStr)
+		// Delete selected entry; BulkDeleteMsg applies it once confirmed
+		if selected := m.listView.GetSelected(); selected != nil {
+			return m, m.listView.RequestDelete([]string{selected.Key})
 		}
 		return m, nil
-	case "u":
-		logDebug("'u' pressed - undoing")
+	case "undo":
+		logging.Debugf("'%s' pressed - undoing", keyStr)
+		failed := m.bannerErr
 		if m.Undo() {
-			logDebug("Undo successful")
-		} else {
-			logDebug("Nothing to undo")
+			logging.Debugf("Undo successful")
+			return m, m.savedStatus(m.GetCurrentEnvFile(), "Undid last change")
+		}
+		logging.Debugf("Nothing to undo")
+		if m.bannerErr == failed {
+			return m, m.listView.ShowStatus("Nothing to undo", false)
 		}
 		return m, nil
-	case "r":
-		logDebug("'r' pressed - redoing")
+	case "redo":
+		logging.Debugf("'%s' pressed - redoing", keyStr)
+		failed := m.bannerErr
 		if m.Redo() {
-			logDebug("Redo successful")
-		} else {
-			logDebug("Nothing to redo")
+			logging.Debugf("Redo successful")
+			return m, m.savedStatus(m.GetCurrentEnvFile(), "Redid change")
+		}
+		logging.Debugf("Nothing to redo")
+		if m.bannerErr == failed {
+			return m, m.listView.ShowStatus("Nothing to redo", false)
 		}
 		return m, nil
-	case "v":
-		logDebug("'v' pressed - showing diff view")
+	case "view-diff":
+		logging.Debugf("'%s' pressed - showing diff view", keyStr)
 		m.ShowDiffView()
 		return m, nil
-	case "b":
-		logDebug("'b' pressed - showing backup view")
+	case "disk-diff":
+		logging.Debugf("'%s' pressed - showing diff against disk", keyStr)
+		return m, m.ShowDiskDiffView()
+	case "backups":
+		logging.Debugf("'%s' pressed - showing backup view", keyStr)
 		envFile := m.GetCurrentEnvFile()
 		if envFile != nil {
 			backups, err := storage.ListBackups(envFile.Path)
 			if err != nil {
-				m.err = err
-				return m, nil
+				return m, m.listView.ShowStatus(fmt.Sprintf("Could not list backups: %v", err), true)
 			}
 			m.backupView = views.NewBackupView(envFile.Path, backups)
 			m.backupView.SetSize(m.listView.Width(), m.listView.Height())
 			m.viewMode = ViewModeBackup
 		}
 		return m, nil
+	case "history":
+		logging.Debugf("'%s' pressed - showing history view", keyStr)
+		envFile := m.GetCurrentEnvFile()
+		if envFile != nil {
+			records, err := storage.LoadHistory(envFile.Path)
+			if err != nil {
+				return m, m.listView.ShowStatus(fmt.Sprintf("Could not load history: %v", err), true)
+			}
+			m.historyView = views.NewHistoryView(envFile.Path, records)
+			m.historyView.SetSize(m.listView.Width(), m.listView.Height())
+			m.viewMode = ViewModeHistory
+		}
+		return m, nil
+	case "issues":
+		logging.Debugf("'%s' pressed - showing validation issues", keyStr)
+		if envFile := m.GetCurrentEnvFile(); envFile != nil {
+			m.issuesView = views.NewIssuesView(envFile.Path, m.validationIssues)
+			m.issuesView.SetSize(m.listView.Width(), m.listView.Height())
+			m.viewMode = ViewModeIssues
+		}
+		return m, nil
+	case "files":
+		logging.Debugf("'%s' pressed - opening the file picker", keyStr)
+		m.listView.OpenFilePicker()
+		return m, nil
+	case "next-file", "prev-file":
+		// Reaches every file, including those past the 1-9 shortcuts
+		if len(m.envFiles) > 1 {
+			step := 1
+			if views.KeyAction(msg) == "prev-file" {
+				step = len(m.envFiles) - 1
+			}
+			m.SwitchToFile((m.currentFileIndex + step) % len(m.envFiles))
+		}
+		return m, nil
+	case "effective":
+		logging.Debugf("'%s' pressed - showing effective config", keyStr)
+		if len(m.envFiles) < 2 {
+			return m, m.listView.ShowStatus("Open more than one file to see which values win", true)
+		}
+		precedence := m.options.Precedence
+		if precedence == nil {
+			precedence = model.DefaultPrecedence
+		}
+		m.effectiveView = views.NewEffectiveView(m.envFiles, precedence)
+		m.effectiveView.SetSize(m.listView.Width(), m.listView.Height())
+		m.viewMode = ViewModeEffective
+		return m, nil
 	default:
-		logDebug(fmt.Sprintf("Passing key '%s' to listView", keyStr))
+		logging.Debugf("Passing key '%s' to listView", keyStr)
 		var cmd tea.Cmd
 		m.listView, cmd = m.listView.Update(msg)
 		return m, cmd
@@ -539,7 +1866,7 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keyStr := msg.String()
-	logDebug(fmt.Sprintf("handleEditKeys: key='%s'", keyStr))
+	logging.Debugf("handleEditKeys: key='%s'", keyStr)
 	envFile := m.GetCurrentEnvFile()
 	if envFile == nil {
 		return m, nil
@@ -547,35 +1874,71 @@ func (m Model) handleEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch keyStr {
 	case "esc":
-		logDebug("ESC pressed - returning to list")
+		logging.Debugf("ESC pressed - returning to list")
 		m.viewMode = ViewModeList
 		return m, nil
-	case "enter":
+	case "enter", "ctrl+s":
 		key := m.editView.GetKey()
 		value := m.editView.GetValue()
-		logDebug(fmt.Sprintf("ENTER pressed - key='%s' value='%s' editMode=%d", key, value, m.editView.GetMode()))
+		logging.Debugf("ENTER pressed - key='%s' editMode=%d", key, m.editView.GetMode())
 
 		if key == "" {
-			logDebug("Empty key, canceling")
+			logging.Debugf("Empty key, canceling")
 			m.viewMode = ViewModeList
 			return m, nil
 		}
+		// The problems are shown under the fields; stay until they are fixed
+		if m.editView.HasErrors() {
+			return m, nil
+		}
 
 		// Check the edit view mode before changing viewMode
-		if m.editView.GetMode() == views.EditModeAdd {
-			logDebug(fmt.Sprintf("Adding new entry: Key='%s' Value='%s'", key, value))
+		change := fmt.Sprintf("Updated %s", key)
+		adding := m.editView.GetMode() == views.EditModeAdd || m.editView.GetMode() == views.EditModeDuplicate
+		if adding && envFile.GetEntry(key) != nil {
+			if m.editView.GetMode() == views.EditModeDuplicate {
+				// Stay in the view until the copy gets a key of its own
+				m.editView.SetError(fmt.Sprintf("%s already exists - change the key to create the copy", key))
+				return m, nil
+			}
+			// Adding the key again would create a duplicate, so offer to update it instead
+			if !m.editView.UpdateConfirmed() {
+				m.editView.ConfirmUpdate(fmt.Sprintf("%s already exists - press enter again to update its value, or change the key", key))
+				return m, nil
+			}
+			adding = false
+		}
+
+		if m.editView.GetMode() == views.EditModeRename {
+			oldKey := m.editView.GetOriginalKey()
+			if err := envFile.RenameEntry(oldKey, key); err != nil {
+				// Stay in the rename view so the name can be fixed
+				m.editView.SetError(err.Error())
+				return m, nil
+			}
+			if oldKey == key {
+				m.viewMode = ViewModeList
+				return m, nil
+			}
+			m.TrackChange(model.ChangeTypeRename, envFile.GetEntry(key), oldKey)
+			change = fmt.Sprintf("Renamed %s to %s", oldKey, key)
+		} else if adding {
+			logging.Debugf("Adding new entry: Key='%s'", key)
 			entry := &model.Entry{
 				Type:     model.KeyValueEntry,
 				Key:      key,
 				Value:    value,
 				IsSecret: parser.IsSecretKey(key),
 			}
-			logDebug(fmt.Sprintf("Entry String() output: '%s'", entry.String()))
+			if source := envFile.GetEntry(m.editView.GetOriginalKey()); source != nil {
+				entry.Exported = source.Exported
+			}
 			envFile.AddEntry(entry)
 			// Track the add for undo
 			m.TrackChange(model.ChangeTypeAdd, entry, "")
+			change = fmt.Sprintf("Added %s", key)
 		} else {
-			logDebug("Updating existing entry")
+			logging.Debugf("Updating existing entry")
 			// Get old value before updating for undo tracking
 			oldEntry := envFile.GetEntry(key)
 			oldValue := ""
@@ -593,30 +1956,18 @@ func (m Model) handleEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.TrackChange(model.ChangeTypeUpdate, updatedEntry, oldValue)
 		}
 
-		logDebug(fmt.Sprintf("Saving file with %d entries", len(envFile.Entries)))
-		if err := storage.WriteFile(envFile); err != nil {
-			logDebug(fmt.Sprintf("Save error: %v", err))
-			m.err = err
+		logging.Debugf("Saving file with %d entries", len(envFile.Entries))
+		if err := m.saveFile(envFile); err != nil {
+			m.reportSaveError(envFile, err)
 			m.viewMode = ViewModeList
 			return m, nil
 		}
-		logDebug("File saved successfully")
+		logging.Debugf("File saved successfully")
 
 		m.viewMode = ViewModeList
 
-		// Preserve the width/height from the old list view
-		oldWidth := m.listView.Width()
-		oldHeight := m.listView.Height()
-
-		m.listView = views.NewListView(envFile.FilterEntries(""))
-
-		// Restore dimensions if we had them
-		if oldWidth > 0 && oldHeight > 0 {
-			m.listView.SetSize(oldWidth, oldHeight)
-		}
-
-		m.validationIssues = envFile.Validate()
-		return m, nil
+		m.refreshListView()
+		return m, m.savedStatus(envFile, change)
 	}
 	return m, nil
 }
@@ -626,21 +1977,49 @@ func (m Model) View() string {
 		return fmt.Sprintf("Error: %v\n\nPress q to quit", m.err)
 	}
 
+	view := m.renderView()
+	if styles.Monochrome() {
+		// Views still set some colors inline
+		view = styles.Decolor(view)
+	}
+	// The notice only matters while the edit that blocks the reload is open
+	if m.watchNotice != "" && m.hasUnsavedEdits() && len(m.conflictFiles) == 0 {
+		view = m.watchNotice + "\n" + view
+	}
+	if m.bannerErr != nil && len(m.conflictFiles) == 0 {
+		view = fmt.Sprintf("✗ Error: %v - esc to dismiss", m.bannerErr) + "\n" + view
+	}
+	return view
+}
+
+// renderView renders the active view
+func (m Model) renderView() string {
+	if len(m.conflictFiles) > 0 {
+		return fmt.Sprintf("⚠ %s was changed on disk by another program since envtui loaded it.\n\n"+
+			"Your last change has not been saved.\n\n"+
+			"  r  reload the file from disk (discard your change)\n"+
+			"  o  overwrite the file with your version\n", m.conflictFiles[0].Path)
+	}
+
 	envFile := m.GetCurrentEnvFile()
-	if envFile == nil || len(envFile.Entries) == 0 {
+	// An empty file still has to reach the add view to get its first entry
+	if envFile == nil || (len(envFile.Entries) == 0 && m.viewMode == ViewModeList) {
 		fileName := m.GetCurrentFileName()
 		if fileName == "" {
 			fileName = "No file"
 		}
+		if envFile != nil && storage.IsNewFile(envFile) {
+			return fmt.Sprintf("[%s] New file - it is created when you save the first entry\n\nPress a to add, q to quit", fileName)
+		}
 		return fmt.Sprintf("[%s] No entries found\n\nPress a to add, q to quit", fileName)
 	}
 
 	switch m.viewMode {
 	case ViewModeList:
-		// Collect git info for all files
+		// Git info is cached: running git for every file on each render is too slow
 		var gitInfos []storage.FileGitInfo
 		for _, ef := range m.envFiles {
-			gitInfos = append(gitInfos, storage.GetFileGitInfo(ef.Path))
+			gitInfos = append(gitInfos, m.gitInfo[ef.Path])
 		}
 		return m.listView.ViewWithFiles(m.envFiles, m.currentFileIndex, gitInfos)
 	case ViewModeEdit, ViewModeAdd:
@@ -649,6 +2028,14 @@ func (m Model) View() string {
 		return m.diffView.View()
 	case ViewModeBackup:
 		return m.backupView.View()
+	case ViewModeHistory:
+		return m.historyView.View()
+	case ViewModeCompare:
+		return m.compareView.View()
+	case ViewModeIssues:
+		return m.issuesView.View()
+	case ViewModeEffective:
+		return m.effectiveView.View()
 	}
 
 	return ""
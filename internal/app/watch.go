@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/envtui/envtui/internal/logging"
+	"github.com/envtui/envtui/internal/storage"
+)
+
+// watchInterval is how often watched files are checked for changes on disk.
+// Watch mode polls on purpose rather than using fsnotify: it avoids the extra
+// dependency, and a stat per file each second also catches editors that replace
+// the file by renaming over it.
+const watchInterval = time.Second
+
+// FileChangedMsg reports that an open file was changed on disk by another program
+type FileChangedMsg struct {
+	Path string
+}
+
+// watchTickMsg triggers the next check of the watched files
+type watchTickMsg struct{}
+
+// watchCheckedMsg carries the result of a check of the watched files
+type watchCheckedMsg struct {
+	stamps  map[string]storage.FileStamp   // Size and modification time of every file
+	changed []string                       // Files whose content no longer matches
+	gitInfo map[string]storage.FileGitInfo // Git status of the files whose stamp changed
+}
+
+// watchCmd schedules the next check of the watched files
+func watchCmd() tea.Cmd {
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// checkWatchedFiles checks the open files for changes on disk off the update
+// loop. A file is only hashed, and its git status only read, when its size or
+// modification time differs from the last check. A changed file is hashed on
+// every check until it is reloaded.
+func (m Model) checkWatchedFiles() tea.Cmd {
+	type watched struct {
+		path string
+		hash string
+	}
+	files := make([]watched, 0, len(m.envFiles))
+	for _, envFile := range m.envFiles {
+		files = append(files, watched{path: envFile.Path, hash: envFile.OriginalHash()})
+	}
+	previous, pending := m.watchStamps, m.watchChanged
+
+	return func() tea.Msg {
+		msg := watchCheckedMsg{
+			stamps:  make(map[string]storage.FileStamp, len(files)),
+			gitInfo: make(map[string]storage.FileGitInfo),
+		}
+		for _, file := range files {
+			stamp := storage.StampFile(file.path)
+			msg.stamps[file.path] = stamp
+			last, ok := previous[file.path]
+			unchanged := ok && last.Equal(stamp)
+			if !unchanged {
+				msg.gitInfo[file.path] = storage.GetFileGitInfo(file.path)
+			}
+			if unchanged && !pending[file.path] {
+				continue
+			}
+			if storage.ContentChanged(file.path, file.hash) {
+				msg.changed = append(msg.changed, file.path)
+			}
+		}
+		return msg
+	}
+}
+
+// handleWatchChecked emits a FileChangedMsg for every changed file and
+// schedules the next check
+func (m Model) handleWatchChecked(msg watchCheckedMsg) (tea.Model, tea.Cmd) {
+	m.watchStamps = msg.stamps
+	m.watchChanged = make(map[string]bool, len(msg.changed))
+	if m.gitInfo == nil {
+		m.gitInfo = make(map[string]storage.FileGitInfo)
+	}
+	for path, info := range msg.gitInfo {
+		m.gitInfo[path] = info
+	}
+	cmds := []tea.Cmd{watchCmd()}
+	for _, path := range msg.changed {
+		path := path
+		m.watchChanged[path] = true
+		cmds = append(cmds, func() tea.Msg { return FileChangedMsg{Path: path} })
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// hasUnsavedEdits returns true while the user is in the middle of a change that
+// has not been written yet, so reloading would throw it away
+func (m Model) hasUnsavedEdits() bool {
+	return m.viewMode == ViewModeEdit || m.viewMode == ViewModeAdd || len(m.conflictFiles) > 0
+}
+
+// handleFileChanged reloads a file changed on disk, or only warns about it when
+// reloading would discard unsaved edits
+func (m Model) handleFileChanged(msg FileChangedMsg) (tea.Model, tea.Cmd) {
+	index := -1
+	for i, envFile := range m.envFiles {
+		if envFile.Path == msg.Path {
+			index = i
+		}
+	}
+	if index < 0 || !storage.ChangedOnDisk(m.envFiles[index]) {
+		return m, nil
+	}
+
+	if m.hasUnsavedEdits() {
+		m.watchNotice = fmt.Sprintf("⚠ %s changed on disk - finish or cancel your edit to reload it", filepath.Base(msg.Path))
+		return m, nil
+	}
+
+	reloaded, err := storage.ReadFile(msg.Path)
+	if err != nil {
+		// The file may be mid-write; the next check will try again
+		logging.Infof("Watch reload of %s failed: %v", msg.Path, err)
+		return m, nil
+	}
+	loadIncludes(reloaded)
+	reloaded.LastModified = m.envFiles[index].LastModified
+	m.envFiles[index] = reloaded
+	m.refreshGitInfo(reloaded.Path)
+	m.originalStates[index] = reloaded.Clone()
+	m.watchNotice = ""
+
+	if index != m.currentFileIndex {
+		m.listView.SetFiles(m.envFiles, m.currentFileIndex)
+		return m, nil
+	}
+
+	m.refreshListView()
+	if m.viewMode != ViewModeList {
+		// Other views hold the old file; go back to the refreshed list
+		m.viewMode = ViewModeList
+	}
+	return m, m.listView.ShowStatus(fmt.Sprintf("Reloaded %s (changed on disk)", filepath.Base(msg.Path)), false)
+}
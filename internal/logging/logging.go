@@ -0,0 +1,119 @@
+// Package logging writes optional diagnostic logs. Logging is off unless
+// enabled with Enable or the ENVTUI_DEBUG environment variable, and the log
+// file is only readable by its owner.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvVar names the environment variable holding a log file path; setting it
+// enables debug logging without a flag
+const EnvVar = "ENVTUI_DEBUG"
+
+// Level is the most detailed kind of message that gets written
+type Level int
+
+const (
+	LevelOff   Level = iota // Write nothing (default)
+	LevelError              // Failures only
+	LevelInfo               // Failures and notable events
+	LevelDebug              // Everything, e.g. every list action
+)
+
+var (
+	mu    sync.Mutex
+	file  *os.File
+	level = LevelOff
+)
+
+// ParseLevel parses a level name: "off", "error", "info" or "debug"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "off":
+		return LevelOff, nil
+	case "error":
+		return LevelError, nil
+	case "info":
+		return LevelInfo, nil
+	case "debug":
+		return LevelDebug, nil
+	}
+	return LevelOff, fmt.Errorf("unknown log level %q (expected off, error, info or debug)", name)
+}
+
+// Enable appends messages up to the given level to the file at path, creating
+// it if needed. The file is restricted to its owner (0600).
+func Enable(path string, lvl Level) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	// An existing file keeps its mode on open, so tighten it
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to restrict log file: %w", err)
+	}
+
+	Disable()
+	mu.Lock()
+	defer mu.Unlock()
+	file = f
+	level = lvl
+	return nil
+}
+
+// EnableFromEnv enables debug logging to the path in EnvVar, if it is set
+func EnableFromEnv() error {
+	path := os.Getenv(EnvVar)
+	if path == "" {
+		return nil
+	}
+	return Enable(path, LevelDebug)
+}
+
+// Disable stops logging and closes the log file
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil {
+		file.Close()
+	}
+	file = nil
+	level = LevelOff
+}
+
+// Enabled reports whether messages at lvl are written
+func Enabled(lvl Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return file != nil && lvl <= level
+}
+
+// Errorf logs a failure
+func Errorf(format string, args ...any) {
+	write(LevelError, "ERROR", format, args...)
+}
+
+// Infof logs a notable event
+func Infof(format string, args ...any) {
+	write(LevelInfo, "INFO", format, args...)
+}
+
+// Debugf logs a detail only useful when tracking down a problem
+func Debugf(format string, args ...any) {
+	write(LevelDebug, "DEBUG", format, args...)
+}
+
+func write(lvl Level, tag, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil || lvl > level {
+		return
+	}
+	fmt.Fprintf(file, "[%s] %-5s %s\n", time.Now().Format("15:04:05"), tag, fmt.Sprintf(format, args...))
+}
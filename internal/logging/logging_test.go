@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoggingIsOptIn(t *testing.T) {
+	// Nothing is written, or created, until logging is enabled
+	Debugf("ignored %d", 1)
+	if Enabled(LevelError) {
+		t.Fatal("logging should be off by default")
+	}
+
+	path := filepath.Join(t.TempDir(), "envtui.log")
+	os.WriteFile(path, nil, 0644)
+	if err := Enable(path, LevelInfo); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	defer Disable()
+
+	Debugf("too detailed")
+	Infof("loaded %s", ".env")
+	Errorf("save failed")
+	Disable()
+	Errorf("after disable")
+
+	content, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "INFO  loaded .env") || !strings.HasSuffix(lines[1], "ERROR save failed") {
+		t.Errorf("unexpected log:\n%s", content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("log file mode = %o, want 600", perm)
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CategoryRule assigns a category to keys starting with Prefix
+type CategoryRule struct {
+	Prefix   string
+	Category string
+	Color    string // Optional hex color for the category's dot, e.g. "#635BFF"
+}
+
+// CategoryRules is an ordered list of prefix rules; the first match wins
+type CategoryRules []CategoryRule
+
+// defaultCategoryRules are the built-in rules, checked after any custom rules
+var defaultCategoryRules = CategoryRules{
+	{Prefix: "DB_", Category: "database"},
+	{Prefix: "DATABASE_", Category: "database"},
+	{Prefix: "AWS_", Category: "aws"},
+	{Prefix: "S3_", Category: "aws"},
+	{Prefix: "API_", Category: "api"},
+	{Prefix: "HTTP_", Category: "api"},
+}
+
+// customCategoryRules are checked before the built-in rules
+var customCategoryRules CategoryRules
+
+// SetCategoryRules sets custom prefix rules that take precedence over the built-in
+// ones. Passing nil restores the built-in behavior.
+func SetCategoryRules(rules CategoryRules) {
+	customCategoryRules = rules
+}
+
+// Match returns the category of the first rule whose prefix the key starts with
+func (rules CategoryRules) Match(key string) (string, bool) {
+	for _, rule := range rules {
+		if strings.HasPrefix(key, rule.Prefix) {
+			return rule.Category, true
+		}
+	}
+	return "", false
+}
+
+// ParseCategoryRules parses "PREFIX = category [#color]" lines, e.g.
+// "STRIPE_ = payments #635BFF". Blank lines and lines starting with # are ignored.
+func ParseCategoryRules(content string) (CategoryRules, error) {
+	var rules CategoryRules
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prefix, rest, ok := strings.Cut(line, "=")
+		prefix = strings.TrimSpace(prefix)
+		fields := strings.Fields(rest)
+		if !ok || prefix == "" || len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected PREFIX = category [#color]", i+1)
+		}
+		rule := CategoryRule{Prefix: prefix, Category: strings.ToLower(fields[0])}
+		if len(fields) == 2 {
+			if !isHexColor(fields[1]) {
+				return nil, fmt.Errorf("line %d: invalid color %q, expected #RRGGBB", i+1, fields[1])
+			}
+			rule.Color = fields[1]
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// isHexColor reports whether s is a #RGB or #RRGGBB color
+func isHexColor(s string) bool {
+	if !strings.HasPrefix(s, "#") || (len(s) != 4 && len(s) != 7) {
+		return false
+	}
+	for _, ch := range s[1:] {
+		if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// Category returns the category used to color and group the entry: the first
+// matching custom rule, then the built-in rules, then "secret" or "other"
+func (e *Entry) Category() string {
+	if len(e.Key) == 0 {
+		return "other"
+	}
+
+	if category, ok := customCategoryRules.Match(e.Key); ok {
+		return category
+	}
+	if category, ok := defaultCategoryRules.Match(e.Key); ok {
+		return category
+	}
+	if e.IsSecret {
+		return "secret"
+	}
+
+	return "other"
+}
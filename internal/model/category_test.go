@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+func TestCustomCategoryRules(t *testing.T) {
+	rules, err := ParseCategoryRules("# project categories\nSTRIPE_ = payments #635BFF\n\nSMTP_ = Email\nDB_REPLICA_ = replica\n")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(rules) != 3 || rules[0].Color != "#635BFF" || rules[1].Category != "email" {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	SetCategoryRules(rules)
+	defer SetCategoryRules(nil)
+
+	tests := map[string]string{
+		"STRIPE_KEY":      "payments",
+		"SMTP_HOST":       "email",
+		"DB_REPLICA_HOST": "replica",  // custom rules win over built-ins
+		"DB_HOST":         "database", // built-ins still apply
+		"PASSWORD":        "secret",
+		"PORT":            "other",
+	}
+	for key, want := range tests {
+		entry := &Entry{Type: KeyValueEntry, Key: key, IsSecret: IsSecretKey(key)}
+		if got := entry.Category(); got != want {
+			t.Errorf("Category(%s) = %s, want %s", key, got, want)
+		}
+	}
+
+	for _, bad := range []string{"STRIPE_", "= payments", "STRIPE_ = payments blue", "A = b c d"} {
+		if _, err := ParseCategoryRules(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // ChangeType represents the type of change made
 type ChangeType int
 
@@ -7,14 +9,44 @@ const (
 	ChangeTypeAdd ChangeType = iota
 	ChangeTypeUpdate
 	ChangeTypeDelete
+	ChangeTypeRename
+	ChangeTypeReorder
+	ChangeTypeExport
+	ChangeTypeDedupe
 )
 
+func (ct ChangeType) String() string {
+	switch ct {
+	case ChangeTypeAdd:
+		return "add"
+	case ChangeTypeUpdate:
+		return "update"
+	case ChangeTypeDelete:
+		return "delete"
+	case ChangeTypeRename:
+		return "rename"
+	case ChangeTypeReorder:
+		return "reorder"
+	case ChangeTypeExport:
+		return "export"
+	case ChangeTypeDedupe:
+		return "dedupe"
+	default:
+		return "unknown"
+	}
+}
+
 // Change represents a single change to an env file
 type Change struct {
-	Type     ChangeType
-	FilePath string
-	Entry    *Entry
-	OldValue string // For updates: the previous value
+	Type      ChangeType
+	FilePath  string
+	Entry     *Entry
+	OldValue  string    // For updates: the previous value; for renames: the previous key
+	Snapshot  []*Entry  // For reorders and dedupes: copies of every entry before the change
+	Result    []*Entry  // For dedupes: copies of every entry after the change
+	Timestamp time.Time // When the change was made
+	// Transaction groups changes pushed between BeginTransaction and Commit (0 = none)
+	Transaction int
 }
 
 // ChangeStack tracks changes for undo/redo functionality
@@ -22,6 +54,8 @@ type ChangeStack struct {
 	changes []Change
 	current int // Index of current position in stack (-1 means no changes)
 	maxSize int // Maximum number of changes to track
+	lastTx  int // Last transaction ID handed out
+	txDepth int // Nesting depth of open transactions
 }
 
 // NewChangeStack creates a new change stack with a max size
@@ -33,6 +67,22 @@ func NewChangeStack(maxSize int) *ChangeStack {
 	}
 }
 
+// BeginTransaction starts grouping pushed changes so they undo and redo as one step.
+// Transactions may be nested; only the outermost Commit closes the group.
+func (cs *ChangeStack) BeginTransaction() {
+	if cs.txDepth == 0 {
+		cs.lastTx++
+	}
+	cs.txDepth++
+}
+
+// Commit ends the transaction started by BeginTransaction
+func (cs *ChangeStack) Commit() {
+	if cs.txDepth > 0 {
+		cs.txDepth--
+	}
+}
+
 // Push adds a new change to the stack
 func (cs *ChangeStack) Push(change Change) {
 	// Remove any redo history
@@ -40,36 +90,69 @@ func (cs *ChangeStack) Push(change Change) {
 		cs.changes = cs.changes[:cs.current+1]
 	}
 
+	change.Transaction = 0
+	if cs.txDepth > 0 {
+		change.Transaction = cs.lastTx
+	}
+
 	// Add new change
 	cs.changes = append(cs.changes, change)
 	cs.current++
 
-	// Trim if exceeds max size
-	if len(cs.changes) > cs.maxSize {
-		cs.changes = cs.changes[1:]
-		cs.current--
+	// Trim whole steps if exceeds max size, never splitting a transaction
+	for len(cs.changes) > cs.maxSize && cs.current > 0 {
+		n := cs.stepSize(0, 1)
+		if n > cs.current {
+			break
+		}
+		cs.changes = cs.changes[n:]
+		cs.current -= n
+	}
+}
+
+// stepSize returns how many changes starting at index i, walking in direction dir,
+// belong to the same undo step
+func (cs *ChangeStack) stepSize(i, dir int) int {
+	tx := cs.changes[i].Transaction
+	if tx == 0 {
+		return 1
 	}
+	n := 1
+	for j := i + dir; j >= 0 && j < len(cs.changes) && cs.changes[j].Transaction == tx; j += dir {
+		n++
+	}
+	return n
 }
 
-// Undo reverts the last change and returns it
-func (cs *ChangeStack) Undo() (*Change, bool) {
+// Undo reverts the last step and returns its changes, most recent first,
+// in the order they must be reverted
+func (cs *ChangeStack) Undo() ([]Change, bool) {
 	if cs.current < 0 {
 		return nil, false
 	}
 
-	change := cs.changes[cs.current]
-	cs.current--
-	return &change, true
+	n := cs.stepSize(cs.current, -1)
+	changes := make([]Change, 0, n)
+	for i := 0; i < n; i++ {
+		changes = append(changes, cs.changes[cs.current])
+		cs.current--
+	}
+	return changes, true
 }
 
-// Redo re-applies the last undone change
-func (cs *ChangeStack) Redo() (*Change, bool) {
+// Redo re-applies the last undone step and returns its changes in their original order
+func (cs *ChangeStack) Redo() ([]Change, bool) {
 	if cs.current >= len(cs.changes)-1 {
 		return nil, false
 	}
 
-	cs.current++
-	return &cs.changes[cs.current], true
+	n := cs.stepSize(cs.current+1, 1)
+	changes := make([]Change, 0, n)
+	for i := 0; i < n; i++ {
+		cs.current++
+		changes = append(changes, cs.changes[cs.current])
+	}
+	return changes, true
 }
 
 // CanUndo returns true if there's something to undo
@@ -0,0 +1,62 @@
+package model
+
+import "testing"
+
+func pushUpdate(cs *ChangeStack, key string) {
+	cs.Push(Change{Type: ChangeTypeUpdate, Entry: &Entry{Key: key}})
+}
+
+func TestChangeStackTransactionUndoesAsOneStep(t *testing.T) {
+	cs := NewChangeStack(100)
+	pushUpdate(cs, "BEFORE")
+
+	cs.BeginTransaction()
+	pushUpdate(cs, "A")
+	pushUpdate(cs, "B")
+	pushUpdate(cs, "C")
+	cs.Commit()
+
+	changes, ok := cs.Undo()
+	if !ok || len(changes) != 3 {
+		t.Fatalf("expected one undo to return the 3 grouped changes, got %d", len(changes))
+	}
+	if changes[0].Entry.Key != "C" || changes[2].Entry.Key != "A" {
+		t.Errorf("undo should return the most recent change first")
+	}
+	if !cs.CanUndo() || !cs.CanRedo() {
+		t.Errorf("the change before the transaction should still be undoable")
+	}
+
+	changes, ok = cs.Redo()
+	if !ok || len(changes) != 3 || changes[0].Entry.Key != "A" {
+		t.Fatalf("expected redo to re-apply the transaction in order, got %+v", changes)
+	}
+	if cs.CanRedo() {
+		t.Errorf("nothing should be left to redo")
+	}
+
+	cs.Undo()
+	changes, _ = cs.Undo()
+	if len(changes) != 1 || changes[0].Entry.Key != "BEFORE" {
+		t.Errorf("expected the single change before the transaction, got %+v", changes)
+	}
+	if cs.CanUndo() {
+		t.Errorf("nothing should be left to undo")
+	}
+}
+
+func TestChangeStackTrimKeepsTransactionsWhole(t *testing.T) {
+	cs := NewChangeStack(3)
+	cs.BeginTransaction()
+	pushUpdate(cs, "A")
+	pushUpdate(cs, "B")
+	cs.Commit()
+	pushUpdate(cs, "C")
+	pushUpdate(cs, "D")
+
+	// The oldest step (the A+B transaction) is dropped as a whole
+	history := cs.GetHistory()
+	if len(history) != 2 || history[0].Entry.Key != "C" {
+		t.Fatalf("expected only C and D to remain, got %d changes", len(history))
+	}
+}
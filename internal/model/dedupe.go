@@ -0,0 +1,86 @@
+package model
+
+// DedupStrategy decides which occurrence of a repeated key Deduplicate keeps
+type DedupStrategy int
+
+const (
+	DedupKeepFirst     DedupStrategy = iota // Keep the first occurrence
+	DedupKeepLast                           // Keep the last occurrence, where it is
+	DedupKeepLastValue                      // Keep the first occurrence with the last value, the one a shell ends up with
+)
+
+func (ds DedupStrategy) String() string {
+	switch ds {
+	case DedupKeepFirst:
+		return "keep first"
+	case DedupKeepLast:
+		return "keep last"
+	case DedupKeepLastValue:
+		return "keep last value"
+	default:
+		return "unknown"
+	}
+}
+
+// DuplicateKeys returns the keys defined more than once, in file order
+func (ef *EnvFile) DuplicateKeys() []string {
+	var keys []string
+	counts := make(map[string]int)
+	for _, entry := range ef.Entries {
+		if entry.Type != KeyValueEntry {
+			continue
+		}
+		counts[entry.Key]++
+		if counts[entry.Key] == 2 {
+			keys = append(keys, entry.Key)
+		}
+	}
+	return keys
+}
+
+// Deduplicate removes repeated keys, keeping one entry per key as chosen by
+// strategy, and returns how many entries were removed. The retained entries keep
+// their order and comments; the comment lines above a removed entry stay in the
+// file where it was.
+func (ef *EnvFile) Deduplicate(strategy DedupStrategy) int {
+	first := make(map[string]*Entry)
+	last := make(map[string]*Entry)
+	for _, entry := range ef.Entries {
+		if entry.Type != KeyValueEntry {
+			continue
+		}
+		if first[entry.Key] == nil {
+			first[entry.Key] = entry
+		}
+		last[entry.Key] = entry
+	}
+
+	removed := 0
+	kept := make([]*Entry, 0, len(ef.Entries))
+	for _, entry := range ef.Entries {
+		if entry.Type != KeyValueEntry || first[entry.Key] == last[entry.Key] {
+			kept = append(kept, entry)
+			continue
+		}
+
+		keep := first[entry.Key]
+		if strategy == DedupKeepLast {
+			keep = last[entry.Key]
+		}
+		if entry == keep {
+			if strategy == DedupKeepLastValue {
+				entry.Value = last[entry.Key].Value
+				entry.QuoteStyle = last[entry.Key].QuoteStyle
+			}
+			kept = append(kept, entry)
+			continue
+		}
+
+		removed++
+		for _, comment := range entry.LeadingComments {
+			kept = append(kept, &Entry{Type: CommentEntry, Comment: comment, Line: entry.Line})
+		}
+	}
+	ef.Entries = kept
+	return removed
+}
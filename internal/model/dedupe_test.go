@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	newFile := func() *EnvFile {
+		return &EnvFile{Entries: []*Entry{
+			{Type: KeyValueEntry, Key: "PORT", Value: "8080", LeadingComments: []string{"# http port"}},
+			{Type: KeyValueEntry, Key: "HOST", Value: "localhost"},
+			{Type: BlankEntry},
+			{Type: KeyValueEntry, Key: "PORT", Value: "9090", LeadingComments: []string{"# override"}},
+			{Type: KeyValueEntry, Key: "DEBUG", Value: "true"},
+			{Type: KeyValueEntry, Key: "PORT", Value: "7070"},
+		}}
+	}
+	render := func(ef *EnvFile) string {
+		var lines []string
+		for _, entry := range ef.Entries {
+			lines = append(lines, entry.String())
+		}
+		return strings.Join(lines, "|")
+	}
+
+	if got := newFile().DuplicateKeys(); len(got) != 1 || got[0] != "PORT" {
+		t.Errorf("DuplicateKeys() = %v", got)
+	}
+
+	tests := map[DedupStrategy]string{
+		DedupKeepFirst:     "# http port\nPORT=8080|HOST=localhost||# override|DEBUG=true",
+		DedupKeepLast:      "# http port|HOST=localhost||# override|DEBUG=true|PORT=7070",
+		DedupKeepLastValue: "# http port\nPORT=7070|HOST=localhost||# override|DEBUG=true",
+	}
+	for strategy, want := range tests {
+		ef := newFile()
+		if removed := ef.Deduplicate(strategy); removed != 2 {
+			t.Errorf("%s: removed %d, want 2", strategy, removed)
+		}
+		if got := render(ef); got != want {
+			t.Errorf("%s:\ngot  %q\nwant %q", strategy, got, want)
+		}
+		if len(ef.DuplicateKeys()) != 0 {
+			t.Errorf("%s left duplicates", strategy)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// DefaultPrecedence is the dotenv loading order by file name, highest priority
+// first: values in .env.local override .env, which override .env.development
+var DefaultPrecedence = []string{".env.local", ".env", ".env.development"}
+
+// ResolvedEntry is the value a key ends up with once every file is loaded
+type ResolvedEntry struct {
+	Key        string
+	Value      string
+	IsSecret   bool
+	Source     string   // Path of the file the value comes from
+	Overridden []string // Paths of lower-priority files that also set the key
+}
+
+// SortByPrecedence returns the files highest priority first. Files are ranked by
+// the position of their name in precedence; files not listed come last, in the
+// order given.
+func SortByPrecedence(files []*EnvFile, precedence []string) []*EnvFile {
+	rank := func(file *EnvFile) int {
+		name := filepath.Base(file.Path)
+		for i, p := range precedence {
+			if p == name {
+				return i
+			}
+		}
+		return len(precedence)
+	}
+
+	sorted := append([]*EnvFile(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+	return sorted
+}
+
+// ResolveEffective computes the value each key has once all files are loaded:
+// the value from the highest-priority file that sets it (see SortByPrecedence).
+// Within one file the last definition of a key wins, as dotenv loaders assign
+// each line in turn.
+func ResolveEffective(files []*EnvFile, precedence []string) map[string]ResolvedEntry {
+	resolved := make(map[string]ResolvedEntry)
+	for _, file := range SortByPrecedence(files, precedence) {
+		for _, entry := range file.Entries {
+			if entry.Type != KeyValueEntry {
+				continue
+			}
+			existing, ok := resolved[entry.Key]
+			if !ok {
+				resolved[entry.Key] = ResolvedEntry{
+					Key:      entry.Key,
+					Value:    entry.Value,
+					IsSecret: entry.IsSecret,
+					Source:   file.Path,
+				}
+				continue
+			}
+			if existing.Source == file.Path {
+				existing.Value = entry.Value
+				existing.IsSecret = entry.IsSecret
+				resolved[entry.Key] = existing
+				continue
+			}
+			if n := len(existing.Overridden); n == 0 || existing.Overridden[n-1] != file.Path {
+				existing.Overridden = append(existing.Overridden, file.Path)
+				resolved[entry.Key] = existing
+			}
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,60 @@
+package model
+
+import "testing"
+
+// effectiveFile builds a file at path setting each key/value pair in turn
+func effectiveFile(path string, pairs ...string) *EnvFile {
+	envFile := &EnvFile{Path: path}
+	for i := 0; i < len(pairs); i += 2 {
+		envFile.Entries = append(envFile.Entries, &Entry{Type: KeyValueEntry, Key: pairs[i], Value: pairs[i+1]})
+	}
+	return envFile
+}
+
+func TestResolveEffective(t *testing.T) {
+	development := effectiveFile("app/.env.development", "PORT", "3000", "DEBUG", "true", "ONLY_DEV", "1")
+	base := effectiveFile("app/.env", "PORT", "8080", "DB_HOST", "db")
+	local := effectiveFile("app/.env.local", "DB_HOST", "ignored", "DB_HOST", "localhost")
+	other := effectiveFile("app/extra.env", "PORT", "1", "EXTRA", "x")
+
+	resolved := ResolveEffective([]*EnvFile{development, other, base, local}, DefaultPrecedence)
+
+	tests := map[string]struct {
+		value, source string
+		overridden    int
+	}{
+		"PORT":     {"8080", "app/.env", 2},
+		"DB_HOST":  {"localhost", "app/.env.local", 1},
+		"DEBUG":    {"true", "app/.env.development", 0},
+		"ONLY_DEV": {"1", "app/.env.development", 0},
+		"EXTRA":    {"x", "app/extra.env", 0},
+	}
+	if len(resolved) != len(tests) {
+		t.Fatalf("got %d keys, want %d: %+v", len(resolved), len(tests), resolved)
+	}
+	for key, want := range tests {
+		got := resolved[key]
+		if got.Value != want.value || got.Source != want.source || len(got.Overridden) != want.overridden {
+			t.Errorf("%s = %+v, want value %s from %s overriding %d files", key, got, want.value, want.source, want.overridden)
+		}
+	}
+	if got := resolved["PORT"].Overridden; got[0] != "app/.env.development" || got[1] != "app/extra.env" {
+		t.Errorf("PORT should override files in precedence order, got %v", got)
+	}
+}
+
+func TestResolveEffectiveLastDefinitionWins(t *testing.T) {
+	local := effectiveFile(".env.local", "API_URL", "http://old", "TOKEN", "a", "API_URL", "http://new")
+	base := effectiveFile(".env", "API_URL", "http://base", "TOKEN", "b")
+
+	resolved := ResolveEffective([]*EnvFile{base, local}, DefaultPrecedence)
+	if got := resolved["API_URL"]; got.Value != "http://new" || got.Source != ".env.local" {
+		t.Errorf("API_URL = %+v, want the later definition in .env.local", got)
+	}
+	if got := resolved["API_URL"].Overridden; len(got) != 1 || got[0] != ".env" {
+		t.Errorf("a key set twice in one file should not override itself, got %v", got)
+	}
+	if got := resolved["TOKEN"]; got.Value != "a" {
+		t.Errorf("TOKEN = %q, want a", got.Value)
+	}
+}
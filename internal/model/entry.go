@@ -1,11 +1,19 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
 type EntryType int
 
 const (
 	KeyValueEntry EntryType = iota
 	CommentEntry
 	BlankEntry
+	// BareKeyEntry is a key without a value, like "export FOO" exporting a
+	// variable set elsewhere; it is kept so the line round-trips
+	BareKeyEntry
 )
 
 func (et EntryType) String() string {
@@ -16,26 +24,72 @@ func (et EntryType) String() string {
 		return "CommentEntry"
 	case BlankEntry:
 		return "BlankEntry"
+	case BareKeyEntry:
+		return "BareKeyEntry"
 	default:
 		return "Unknown"
 	}
 }
 
+// QuoteStyle is how a value was quoted in the file, kept so it is written back
+// the same way
+type QuoteStyle int
+
+const (
+	QuoteNone QuoteStyle = iota
+	// QuoteSingle values are literal: dotenv does not interpolate them
+	QuoteSingle
+	QuoteDouble
+)
+
+func (qs QuoteStyle) String() string {
+	switch qs {
+	case QuoteNone:
+		return "none"
+	case QuoteSingle:
+		return "single"
+	case QuoteDouble:
+		return "double"
+	default:
+		return "unknown"
+	}
+}
+
 type Entry struct {
-	Type     EntryType
-	Key      string
-	Value    string
-	Comment  string
-	Line     int
-	Exported bool
-	IsSecret bool
+	Type       EntryType
+	Key        string
+	Value      string
+	Comment    string
+	Line       int
+	Exported   bool
+	IsSecret   bool
+	QuoteStyle QuoteStyle
+	// LeadingComments are the comment lines (with their #) directly above a
+	// key/value entry; they move, and are deleted, together with the key
+	LeadingComments []string
+}
+
+// SkipInfo is a line the parser could not make sense of. It is not kept as an
+// entry, so it is lost when the file is saved.
+type SkipInfo struct {
+	Line   int
+	Text   string
+	Reason string
 }
 
 type EnvFile struct {
-	Path         string
-	Entries      []*Entry
-	originalHash string // Hash of original file content for detecting changes
-	isModified   bool   // Track if file has unsaved changes
+	Path               string
+	Entries            []*Entry
+	ParseIssues        []ValidationIssue    // Issues found while parsing, e.g. unresolved references
+	SkippedLines       []SkipInfo           // Lines that could not be parsed, in file order
+	LineEnding         string               // "\r\n" for files written with Windows line endings; empty means "\n"
+	TrailingBlankLines int                  // Blank lines after the last entry, kept there when entries are added
+	NoFinalNewline     bool                 // The last line had no newline, and is saved without one
+	ReadOnly           bool                 // Edits are refused, e.g. for a reference file only copied from
+	LastModified       map[string]time.Time // When each key was last changed, kept in a sidecar file (see storage.SaveLastModified)
+	Layers             []*EnvFile           // Included files, highest priority first; inherited read-only and never saved here (see storage.LoadIncludes)
+	originalHash       string               // Hash of original file content for detecting changes
+	isModified         bool                 // Track if file has unsaved changes
 }
 
 // SetModified marks the file as having unsaved changes
@@ -53,28 +107,75 @@ func (ef *EnvFile) IsModified() bool {
 	return ef.isModified
 }
 
+// SetOriginalHash records the hash of the file content as last read or written
+func (ef *EnvFile) SetOriginalHash(hash string) {
+	ef.originalHash = hash
+}
+
+// OriginalHash returns the hash of the file content as last read or written
+func (ef *EnvFile) OriginalHash() string {
+	return ef.originalHash
+}
+
+// String renders the file content as it is written to disk, keeping the line
+// endings, trailing blank lines and missing final newline it was read with.
+// Parsing the result gives back the same entries.
+func (ef *EnvFile) String() string {
+	var content strings.Builder
+	for _, entry := range ef.Entries {
+		content.WriteString(entry.String() + "\n")
+	}
+	content.WriteString(strings.Repeat("\n", ef.TrailingBlankLines))
+	data := content.String()
+	if ef.NoFinalNewline {
+		data = strings.TrimSuffix(data, "\n")
+	}
+	if ef.LineEnding == "\r\n" {
+		data = strings.ReplaceAll(data, "\n", "\r\n")
+	}
+	return data
+}
+
 // Clone creates a deep copy of the EnvFile
 func (ef *EnvFile) Clone() *EnvFile {
 	clone := &EnvFile{
-		Path:         ef.Path,
-		originalHash: ef.originalHash,
-		isModified:   ef.isModified,
-		Entries:      make([]*Entry, len(ef.Entries)),
+		Path:               ef.Path,
+		LineEnding:         ef.LineEnding,
+		TrailingBlankLines: ef.TrailingBlankLines,
+		NoFinalNewline:     ef.NoFinalNewline,
+		ReadOnly:           ef.ReadOnly,
+		originalHash:       ef.originalHash,
+		isModified:         ef.isModified,
+		Entries:            make([]*Entry, len(ef.Entries)),
+		ParseIssues:        append([]ValidationIssue(nil), ef.ParseIssues...),
+		SkippedLines:       append([]SkipInfo(nil), ef.SkippedLines...),
+		Layers:             ef.Layers, // Read-only, so shared
+	}
+	if ef.LastModified != nil {
+		clone.LastModified = make(map[string]time.Time, len(ef.LastModified))
+		for key, at := range ef.LastModified {
+			clone.LastModified[key] = at
+		}
 	}
 	for i, entry := range ef.Entries {
 		clone.Entries[i] = &Entry{
-			Type:     entry.Type,
-			Key:      entry.Key,
-			Value:    entry.Value,
-			Comment:  entry.Comment,
-			Line:     entry.Line,
-			Exported: entry.Exported,
-			IsSecret: entry.IsSecret,
+			Type:       entry.Type,
+			Key:        entry.Key,
+			Value:      entry.Value,
+			Comment:    entry.Comment,
+			Line:       entry.Line,
+			Exported:   entry.Exported,
+			IsSecret:   entry.IsSecret,
+			QuoteStyle: entry.QuoteStyle,
+
+			LeadingComments: append([]string(nil), entry.LeadingComments...),
 		}
 	}
 	return clone
 }
 
+// String renders the entry as it is written to a file: the line, preceded by
+// its leading comments. Parsing the result gives back the same entry.
 func (e *Entry) String() string {
 	switch e.Type {
 	case KeyValueEntry:
@@ -88,7 +189,20 @@ func (e *Entry) String() string {
 			suffix = " " + e.Comment
 		}
 
-		return prefix + e.Key + "=" + e.Value + suffix
+		line := prefix + e.Key + "=" + FormatValue(e.Value, e.QuoteStyle) + suffix
+		if len(e.LeadingComments) > 0 {
+			return strings.Join(e.LeadingComments, "\n") + "\n" + line
+		}
+		return line
+	case BareKeyEntry:
+		line := e.Key
+		if e.Exported {
+			line = "export " + line
+		}
+		if e.Comment != "" {
+			line += " " + e.Comment
+		}
+		return line
 	case CommentEntry:
 		return e.Comment
 	case BlankEntry:
@@ -97,39 +211,33 @@ func (e *Entry) String() string {
 	return ""
 }
 
-func (e *Entry) DisplayValue() string {
-	if e.IsSecret {
-		return "••••••••"
-	}
-	return e.Value
-}
-
-func (e *Entry) Category() string {
-	if len(e.Key) == 0 {
-		return "other"
+// FormatValue renders a value for writing in the given quote style. Values that
+// other dotenv parsers could misread unquoted (spaces, newlines, comments, quotes)
+// are double-quoted with escapes so they read back unchanged, as are single-quoted
+// values that cannot be written literally.
+func FormatValue(value string, style QuoteStyle) string {
+	switch {
+	case style == QuoteSingle && !strings.ContainsAny(value, "'\\\n\r"):
+		return "'" + value + "'"
+	case style == QuoteNone && !strings.ContainsAny(value, " \n\r\t#\"'"):
+		return value
 	}
 
-	if idx := findPrefix(e.Key, []string{"DB_", "DATABASE_"}); idx != -1 {
-		return "database"
-	}
-	if idx := findPrefix(e.Key, []string{"AWS_", "S3_"}); idx != -1 {
-		return "aws"
-	}
-	if idx := findPrefix(e.Key, []string{"API_", "HTTP_"}); idx != -1 {
-		return "api"
-	}
-	if e.IsSecret {
-		return "secret"
-	}
-
-	return "other"
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"\n", "\\n",
+		"\r", "\\r",
+		"\t", "\\t",
+	)
+	return "\"" + replacer.Replace(value) + "\""
 }
 
-func findPrefix(key string, prefixes []string) int {
-	for i, prefix := range prefixes {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
-			return i
-		}
+// DisplayValue returns the value to show on screen, masked if it is a secret
+// (see SetMaskStyle)
+func (e *Entry) DisplayValue() string {
+	if e.IsSecret {
+		return MaskValue(e.Value)
 	}
-	return -1
+	return e.Value
 }
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -28,6 +30,17 @@ func (ef *EnvFile) UpdateEntry(key, value string) bool {
 	return false
 }
 
+// SetExported sets whether the key is written with an "export " prefix
+func (ef *EnvFile) SetExported(key string, exported bool) bool {
+	for _, entry := range ef.Entries {
+		if entry.Type == KeyValueEntry && entry.Key == key {
+			entry.Exported = exported
+			return true
+		}
+	}
+	return false
+}
+
 func (ef *EnvFile) DeleteEntry(key string) bool {
 	for i, entry := range ef.Entries {
 		if entry.Type == KeyValueEntry && entry.Key == key {
@@ -38,47 +51,158 @@ func (ef *EnvFile) DeleteEntry(key string) bool {
 	return false
 }
 
-func (ef *EnvFile) FilterEntries(query string) []*Entry {
-	var kvEntries []*Entry
-	for _, entry := range ef.Entries {
+// SortEntries reorders the file so keys are in alphabetical order. Leading comments
+// and any comment lines directly above a key move with it, and blank lines between
+// keys are dropped.
+// A header before the first key (ending in a blank line) stays at the top, and
+// comments after the last key stay at the bottom.
+func (ef *EnvFile) SortEntries() {
+	// The header ends at the last blank line before the first key
+	first := len(ef.Entries)
+	for i, entry := range ef.Entries {
 		if entry.Type == KeyValueEntry {
-			kvEntries = append(kvEntries, entry)
+			first = i
+			break
+		}
+	}
+	headerEnd := 0
+	for i := 0; i < first; i++ {
+		if ef.Entries[i].Type == BlankEntry {
+			headerEnd = i + 1
 		}
 	}
 
-	if query == "" {
-		return kvEntries
+	var groups [][]*Entry
+	var pending []*Entry // Comments waiting for the key they document
+	for _, entry := range ef.Entries[headerEnd:] {
+		switch entry.Type {
+		case CommentEntry:
+			pending = append(pending, entry)
+		case KeyValueEntry, BareKeyEntry:
+			groups = append(groups, append(pending, entry))
+			pending = nil
+		}
 	}
 
-	query = strings.ToLower(query)
-	var filtered []*Entry
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i][len(groups[i])-1].Key < groups[j][len(groups[j])-1].Key
+	})
 
-	for _, entry := range kvEntries {
-		// Simple fuzzy matching: check if all characters in query appear in order
-		key := strings.ToLower(entry.Key)
-		value := strings.ToLower(entry.Value)
+	sorted := make([]*Entry, 0, len(ef.Entries))
+	sorted = append(sorted, ef.Entries[:headerEnd]...)
+	for _, group := range groups {
+		sorted = append(sorted, group...)
+	}
+	ef.Entries = append(sorted, pending...)
+}
 
-		if fuzzyMatch(key, query) || fuzzyMatch(value, query) {
-			filtered = append(filtered, entry)
-		}
+// RenameEntry renames a key in place, keeping its position, comment and exported flag.
+// The secret flag is re-evaluated for the new name.
+func (ef *EnvFile) RenameEntry(oldKey, newKey string) error {
+	if !IsValidKey(newKey) {
+		return fmt.Errorf("invalid key name %q", newKey)
+	}
+	entry := ef.GetEntry(oldKey)
+	if entry == nil {
+		return fmt.Errorf("key %s not found", oldKey)
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if ef.GetEntry(newKey) != nil {
+		return fmt.Errorf("key %s already exists", newKey)
 	}
 
-	return filtered
+	entry.Key = newKey
+	entry.IsSecret = IsSecretKey(newKey)
+	if at, ok := ef.LastModified[oldKey]; ok {
+		delete(ef.LastModified, oldKey)
+		ef.LastModified[newKey] = at
+	}
+	return nil
 }
 
-func fuzzyMatch(text, pattern string) bool {
-	if pattern == "" {
-		return true
+// EntryComments returns the documentation of each key/value entry: the comment
+// lines directly above it and its inline comment, without the leading #
+func (ef *EnvFile) EntryComments() map[*Entry]string {
+	comments := make(map[*Entry]string)
+	var block []string
+	for _, entry := range ef.Entries {
+		switch entry.Type {
+		case CommentEntry:
+			block = append(block, commentText(entry.Comment))
+			continue
+		case KeyValueEntry:
+			for _, comment := range entry.LeadingComments {
+				block = append(block, commentText(comment))
+			}
+			if entry.Comment != "" {
+				block = append(block, commentText(entry.Comment))
+			}
+			if len(block) > 0 {
+				comments[entry] = strings.Join(block, " ")
+			}
+		}
+		block = nil
 	}
+	return comments
+}
+
+// commentText strips the # marker and surrounding space from a comment
+func commentText(comment string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(comment), "#"))
+}
 
-	pi := 0 // pattern index
-	for ti := 0; ti < len(text) && pi < len(pattern); ti++ {
-		if text[ti] == pattern[pi] {
-			pi++
+// FilterEntries returns the key/value entries fuzzy-matching query, best match first
+// (see RankEntries). An empty query returns every entry in file order.
+func (ef *EnvFile) FilterEntries(query string) []*Entry {
+	var kvEntries []*Entry
+	for _, entry := range ef.Entries {
+		if entry.Type == KeyValueEntry {
+			kvEntries = append(kvEntries, entry)
 		}
 	}
 
-	return pi == len(pattern)
+	return RankEntries(kvEntries, query, nil, false)
+}
+
+// Stats summarizes what a file is made of
+type Stats struct {
+	Keys       int // Key/value entries, counting duplicates
+	Secrets    int // Keys detected as secret
+	Exported   int // Keys with an export prefix
+	Duplicates int // Definitions of a key that already appeared earlier
+	Comments   int // Comment lines, including those attached to keys
+	Blanks     int // Blank lines
+}
+
+// Stats counts the file's keys, secrets, exported keys, duplicates, comments and blank lines
+func (ef *EnvFile) Stats() Stats {
+	var stats Stats
+	seen := make(map[string]bool)
+	for _, entry := range ef.Entries {
+		switch entry.Type {
+		case KeyValueEntry:
+			stats.Keys++
+			stats.Comments += len(entry.LeadingComments)
+			if entry.IsSecret {
+				stats.Secrets++
+			}
+			if entry.Exported {
+				stats.Exported++
+			}
+			if seen[entry.Key] {
+				stats.Duplicates++
+			}
+			seen[entry.Key] = true
+		case CommentEntry:
+			stats.Comments++
+		case BlankEntry:
+			stats.Blanks++
+		}
+	}
+	stats.Blanks += ef.TrailingBlankLines
+	return stats
 }
 
 // FileDiff represents a comparison between two env files
@@ -164,6 +288,40 @@ func (ef *EnvFile) CompareWith(other *EnvFile) *EnvFileCompare {
 	return compare
 }
 
+// EnvironName is the OtherFile of a comparison with the process environment
+const EnvironName = "environment"
+
+// EnvironMap turns "KEY=value" pairs, as returned by os.Environ, into a map
+func EnvironMap(environ []string) map[string]string {
+	values := make(map[string]string, len(environ))
+	for _, pair := range environ {
+		key, value, ok := strings.Cut(pair, "=")
+		// Windows keeps per-drive directories in variables like "=C:"
+		if !ok || key == "" {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// CompareWithEnviron compares envFile with environment variables, e.g. those
+// exported in the current shell. Keys only set in the environment are reported
+// as OnlyInOther.
+func CompareWithEnviron(envFile *EnvFile, environ map[string]string) *EnvFileCompare {
+	keys := make([]string, 0, len(environ))
+	for key := range environ {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	other := &EnvFile{Path: EnvironName}
+	for _, key := range keys {
+		other.Entries = append(other.Entries, &Entry{Type: KeyValueEntry, Key: key, Value: environ[key]})
+	}
+	return envFile.CompareWith(other)
+}
+
 // HasDifferences returns true if there are any differences with the other file
 func (ec *EnvFileCompare) HasDifferences() bool {
 	return ec.DifferentValues > 0 || ec.OnlyInCurrent > 0 || ec.OnlyInOther > 0
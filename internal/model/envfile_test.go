@@ -0,0 +1,70 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSortEntriesKeepsCommentsWithKeys(t *testing.T) {
+	envFile := &EnvFile{Entries: []*Entry{
+		{Type: CommentEntry, Comment: "# App config"},
+		{Type: BlankEntry},
+		{Type: CommentEntry, Comment: "# Port to listen on"},
+		{Type: KeyValueEntry, Key: "PORT", Value: "8080"},
+		{Type: BlankEntry},
+		{Type: CommentEntry, Comment: "# Database"},
+		{Type: KeyValueEntry, Key: "DB_HOST", Value: "localhost"},
+		{Type: KeyValueEntry, Key: "API_URL", Value: "http://api"},
+		{Type: BareKeyEntry, Key: "EDITOR", Exported: true},
+		{Type: CommentEntry, Comment: "# end"},
+	}}
+
+	envFile.SortEntries()
+
+	var lines []string
+	for _, entry := range envFile.Entries {
+		lines = append(lines, entry.String())
+	}
+	want := "# App config\n\nAPI_URL=http://api\n# Database\nDB_HOST=localhost\nexport EDITOR\n# Port to listen on\nPORT=8080\n# end"
+	if got := strings.Join(lines, "\n"); got != want {
+		t.Errorf("unexpected order:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStats(t *testing.T) {
+	envFile := &EnvFile{Entries: []*Entry{
+		{Type: CommentEntry, Comment: "# App"},
+		{Type: BlankEntry},
+		{Type: KeyValueEntry, Key: "PORT", Value: "8080", Exported: true, LeadingComments: []string{"# port", "# (http)"}},
+		{Type: KeyValueEntry, Key: "API_KEY", Value: "x", IsSecret: true},
+		{Type: KeyValueEntry, Key: "PORT", Value: "9090"},
+		{Type: BlankEntry},
+	}}
+
+	want := Stats{Keys: 3, Secrets: 1, Exported: 1, Duplicates: 1, Comments: 3, Blanks: 2}
+	if got := envFile.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompareWithEnviron(t *testing.T) {
+	envFile := &EnvFile{Entries: []*Entry{
+		{Type: KeyValueEntry, Key: "PORT", Value: "8080"},
+		{Type: KeyValueEntry, Key: "DEBUG", Value: "true"},
+		{Type: KeyValueEntry, Key: "DB_URL", Value: "postgres://a=b"},
+	}}
+	environ := EnvironMap([]string{"PORT=3000", "DB_URL=postgres://a=b", "HOME=/home/me", "=C:=C:\\"})
+
+	compare := CompareWithEnviron(envFile, environ)
+	if compare.OtherFile != EnvironName {
+		t.Errorf("OtherFile = %q, want %q", compare.OtherFile, EnvironName)
+	}
+	if compare.DifferentValues != 1 || compare.MatchingKeys != 1 || compare.OnlyInCurrent != 1 || compare.OnlyInOther != 1 {
+		t.Errorf("unexpected counts: %+v", compare)
+	}
+	for _, diff := range compare.Differences {
+		if diff.Key == "HOME" && (!diff.OnlyInOther || diff.OtherValue != "/home/me") {
+			t.Errorf("HOME should only be in the environment: %+v", diff)
+		}
+	}
+}
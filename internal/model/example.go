@@ -0,0 +1,100 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// exampleSuffixes are file extensions that mark an env file as an example/template
+var exampleSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// placeholderMarkers are substrings that indicate a value is a placeholder, not a real secret
+var placeholderMarkers = []string{
+	"changeme", "change-me", "change_me", "your-", "your_", "-here", "_here",
+	"example", "placeholder", "xxx", "todo", "replace", "secret", "password", "<", "${",
+}
+
+// IsExampleFile returns true if the path looks like an example or template env file
+func IsExampleFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, suffix := range exampleSuffixes {
+		if ext == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExample returns true if this env file is an example or template file
+func (ef *EnvFile) IsExample() bool {
+	return IsExampleFile(ef.Path)
+}
+
+// IsPlaceholderValue returns true if the value is empty or an obvious placeholder
+func IsPlaceholderValue(value string) bool {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	if lower == "" {
+		return true
+	}
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// LooksLikeRealSecret returns true if the value has the shape of a real credential:
+// long enough, no whitespace, mixing letters and digits, and not a placeholder
+func LooksLikeRealSecret(value string) bool {
+	if len(value) < 16 || IsPlaceholderValue(value) {
+		return false
+	}
+
+	hasLetter, hasDigit := false, false
+	for _, ch := range value {
+		switch {
+		case unicode.IsSpace(ch):
+			return false
+		case unicode.IsLetter(ch):
+			hasLetter = true
+		case unicode.IsDigit(ch):
+			hasDigit = true
+		}
+	}
+
+	// URLs and paths are configuration, not credentials
+	if strings.Contains(value, "://") || strings.HasPrefix(value, "/") {
+		return false
+	}
+
+	return hasLetter && hasDigit
+}
+
+// SuspectedExampleSecrets returns the entries of an example file whose values look real
+func (ef *EnvFile) SuspectedExampleSecrets() []*Entry {
+	if !ef.IsExample() {
+		return nil
+	}
+
+	var suspects []*Entry
+	for _, entry := range ef.Entries {
+		if entry.Type == KeyValueEntry && IsSuspectedRealSecret(entry.IsSecret, entry.Value) {
+			suspects = append(suspects, entry)
+		}
+	}
+	return suspects
+}
+
+// IsSuspectedRealSecret returns true if a value should not be stored in an example file.
+// Secret keys only need a non-placeholder value of some length; other keys must look like a credential.
+func IsSuspectedRealSecret(isSecretKey bool, value string) bool {
+	if IsPlaceholderValue(value) {
+		return false
+	}
+	if isSecretKey && len(value) >= 8 {
+		return true
+	}
+	return LooksLikeRealSecret(value) || DetectSecretValue(value)
+}
@@ -0,0 +1,42 @@
+package model
+
+import "testing"
+
+func TestIsExampleFile(t *testing.T) {
+	for _, path := range []string{".env.example", "config/.env.sample", ".env.TEMPLATE", "app.env.dist"} {
+		if !IsExampleFile(path) {
+			t.Errorf("%s should be detected as an example file", path)
+		}
+	}
+	for _, path := range []string{".env", ".env.local", ".env.production", "example.env", "examples/.env"} {
+		if IsExampleFile(path) {
+			t.Errorf("%s is a real env file, not an example", path)
+		}
+	}
+}
+
+func TestSuspectedExampleSecrets(t *testing.T) {
+	entries := []*Entry{
+		{Type: KeyValueEntry, Key: "API_KEY", Value: "your-api-key-here", IsSecret: true},
+		{Type: KeyValueEntry, Key: "DB_PASSWORD", Value: "pr0d-passw0rd-9f8e", IsSecret: true},
+		{Type: KeyValueEntry, Key: "STRIPE_TOKEN", Value: "sk9f8e7d6c5b4a3210zz"},
+		{Type: KeyValueEntry, Key: "APP_URL", Value: "https://example.com/v1/app"},
+		{Type: KeyValueEntry, Key: "PORT", Value: "8080"},
+	}
+
+	example := &EnvFile{Path: ".env.example", Entries: entries}
+	suspects := example.SuspectedExampleSecrets()
+	if len(suspects) != 2 || suspects[0].Key != "DB_PASSWORD" || suspects[1].Key != "STRIPE_TOKEN" {
+		keys := make([]string, len(suspects))
+		for i, entry := range suspects {
+			keys[i] = entry.Key
+		}
+		t.Errorf("expected DB_PASSWORD and STRIPE_TOKEN to be suspected, got %v", keys)
+	}
+
+	// Real files are expected to hold real secrets
+	real := &EnvFile{Path: ".env", Entries: entries}
+	if real.IsExample() || len(real.SuspectedExampleSecrets()) != 0 {
+		t.Errorf("a real env file should not be checked for example secrets")
+	}
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Fuzzy match scoring weights
+const (
+	fuzzyMatchScore       = 1     // Every matched character
+	fuzzyConsecutiveBonus = 5     // Matched right after the previous match
+	fuzzyWordStartBonus   = 3     // Matched at the start of a word (after _ - . / : or space)
+	fuzzyPrefixBonus      = 10    // First character matched at the very start of the text
+	fuzzyKeyBonus         = 1000  // Any key match ranks above any value-only match
+	fuzzyCommentScore     = -1000 // Comment-only matches rank below every key and value match
+)
+
+// FuzzyMatch reports whether every rune of query appears in text in order,
+// ignoring case unless caseSensitive is set (then "Path" does not find "PATH").
+// The score rewards consecutive runs, word starts and prefixes and penalizes
+// gaps; positions are the rune indexes of the matched characters.
+func FuzzyMatch(text, query string, caseSensitive bool) (score int, positions []int, ok bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+
+	textRunes := []rune(text)
+	queryRunes := []rune(query)
+	if !caseSensitive {
+		for i := range textRunes {
+			textRunes[i] = unicode.ToLower(textRunes[i])
+		}
+		for i := range queryRunes {
+			queryRunes[i] = unicode.ToLower(queryRunes[i])
+		}
+	}
+
+	// Try every occurrence of the first query rune as a starting point and keep
+	// the best greedy alignment, so "url" prefers "_URL" over scattered letters
+	found := false
+	for start := range textRunes {
+		if textRunes[start] != queryRunes[0] {
+			continue
+		}
+		s, p, matched := fuzzyAlign(textRunes, queryRunes, start)
+		if matched && (!found || s > score) {
+			score, positions, found = s, p, true
+		}
+	}
+	return score, positions, found
+}
+
+// fuzzyAlign greedily matches query against text starting at start and scores it
+func fuzzyAlign(text, query []rune, start int) (int, []int, bool) {
+	positions := make([]int, 0, len(query))
+	score := 0
+	qi := 0
+	for ti := start; ti < len(text) && qi < len(query); ti++ {
+		if text[ti] != query[qi] {
+			continue
+		}
+
+		score += fuzzyMatchScore
+		if n := len(positions); n > 0 {
+			if gap := ti - positions[n-1] - 1; gap == 0 {
+				score += fuzzyConsecutiveBonus
+			} else {
+				score -= gap
+			}
+		}
+		if ti == 0 {
+			score += fuzzyPrefixBonus
+		} else if isWordSeparator(text[ti-1]) {
+			score += fuzzyWordStartBonus
+		}
+
+		positions = append(positions, ti)
+		qi++
+	}
+	return score, positions, qi == len(query)
+}
+
+func isWordSeparator(r rune) bool {
+	switch r {
+	case '_', '-', '.', '/', ':', ' ':
+		return true
+	}
+	return false
+}
+
+// RankEntries returns the entries matching query, best match first. Key matches
+// rank above value matches, which rank above entries whose comment (see
+// EnvFile.EntryComments) contains query; comments may be nil. Entries with
+// equal scores keep their order.
+func RankEntries(entries []*Entry, query string, comments map[*Entry]string, caseSensitive bool) []*Entry {
+	if query == "" {
+		return entries
+	}
+
+	type ranked struct {
+		entry *Entry
+		score int
+	}
+	var matches []ranked
+	for _, entry := range entries {
+		if score, _, ok := FuzzyMatch(entry.Key, query, caseSensitive); ok {
+			matches = append(matches, ranked{entry, score + fuzzyKeyBonus})
+		} else if score, _, ok := FuzzyMatch(entry.Value, query, caseSensitive); ok {
+			matches = append(matches, ranked{entry, score})
+		} else if _, ok := SubstringMatch(comments[entry], query, caseSensitive); ok {
+			matches = append(matches, ranked{entry, fuzzyCommentScore})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	filtered := make([]*Entry, len(matches))
+	for i, match := range matches {
+		filtered[i] = match.entry
+	}
+	return filtered
+}
+
+// SubstringMatch reports whether text contains query, ignoring case unless
+// caseSensitive is set, and returns the rune indexes of the first occurrence.
+// Comments are prose, where fuzzy matching would find scattered letters of
+// almost any query.
+func SubstringMatch(text, query string, caseSensitive bool) (positions []int, ok bool) {
+	textRunes := []rune(text)
+	queryRunes := []rune(query)
+	if len(queryRunes) == 0 {
+		return nil, false
+	}
+
+	for start := 0; start+len(queryRunes) <= len(textRunes); start++ {
+		matched := true
+		for i, qr := range queryRunes {
+			if tr := textRunes[start+i]; tr != qr && (caseSensitive || unicode.ToLower(tr) != unicode.ToLower(qr)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			positions = make([]int, len(queryRunes))
+			for i := range positions {
+				positions[i] = start + i
+			}
+			return positions, true
+		}
+	}
+	return nil, false
+}
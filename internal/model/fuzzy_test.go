@@ -0,0 +1,98 @@
+package model
+
+import "testing"
+
+func TestRankEntries(t *testing.T) {
+	entries := []*Entry{
+		{Type: KeyValueEntry, Key: "NOTES", Value: "do bring umbrellas later"},
+		{Type: KeyValueEntry, Key: "DEBUG_URL_LIST", Value: "x"},
+		{Type: KeyValueEntry, Key: "DATABASE_URL", Value: "postgres://localhost"},
+		{Type: KeyValueEntry, Key: "PORT", Value: "8080"},
+	}
+
+	ranked := RankEntries(entries, "dburl", nil, false)
+	if len(ranked) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(ranked))
+	}
+	if ranked[0].Key != "DATABASE_URL" && ranked[0].Key != "DEBUG_URL_LIST" {
+		t.Errorf("a key match should rank first, got %s", ranked[0].Key)
+	}
+	if ranked[2].Key != "NOTES" {
+		t.Errorf("the value-only match should rank last, got %s", ranked[2].Key)
+	}
+
+	ranked = RankEntries(entries, "port", nil, false)
+	if ranked[0].Key != "PORT" {
+		t.Errorf("an exact prefix match should rank first, got %s", ranked[0].Key)
+	}
+
+	if got := RankEntries(entries, "", nil, false); len(got) != len(entries) || got[0] != entries[0] {
+		t.Errorf("an empty query should keep every entry in order")
+	}
+}
+
+func TestFuzzyMatchPositions(t *testing.T) {
+	score, positions, ok := FuzzyMatch("DATABASE_URL", "url", false)
+	if !ok || len(positions) != 3 || positions[0] != 9 {
+		t.Fatalf("expected URL matched at 9, got ok=%v positions=%v", ok, positions)
+	}
+	scattered, _, _ := FuzzyMatch("uxrxl", "url", false)
+	if score <= scattered {
+		t.Errorf("consecutive match (%d) should outscore a scattered one (%d)", score, scattered)
+	}
+	if _, _, ok := FuzzyMatch("PORT", "prx", false); ok {
+		t.Errorf("missing characters should not match")
+	}
+}
+
+func TestRankEntriesWithComments(t *testing.T) {
+	envFile := &EnvFile{Entries: []*Entry{
+		{Type: CommentEntry, Comment: "# Rotated monthly by the platform team"},
+		{Type: KeyValueEntry, Key: "STRIPE_KEY", Value: "sk_test"},
+		{Type: BlankEntry},
+		{Type: KeyValueEntry, Key: "TIMEOUT", Value: "30", Comment: "# seconds, rotated never"},
+		{Type: KeyValueEntry, Key: "ROTATION", Value: "on"},
+	}}
+	entries := envFile.FilterEntries("")
+
+	comments := envFile.EntryComments()
+	if got := comments[entries[0]]; got != "Rotated monthly by the platform team" {
+		t.Errorf("expected the comment above STRIPE_KEY, got %q", got)
+	}
+	if _, ok := comments[entries[2]]; ok {
+		t.Errorf("ROTATION has no comment")
+	}
+
+	if ranked := RankEntries(entries, "rotated", nil, false); len(ranked) != 0 {
+		t.Errorf("comments must not be searched by default, got %d matches", len(ranked))
+	}
+
+	ranked := RankEntries(entries, "rotat", comments, false)
+	if len(ranked) != 3 || ranked[0].Key != "ROTATION" {
+		t.Fatalf("expected the key match first and both commented entries, got %v", ranked)
+	}
+	if ranked[1].Key != "STRIPE_KEY" || ranked[2].Key != "TIMEOUT" {
+		t.Errorf("comment matches should keep file order, got %s, %s", ranked[1].Key, ranked[2].Key)
+	}
+}
+
+func TestRankEntriesCaseSensitive(t *testing.T) {
+	entries := []*Entry{
+		{Type: KeyValueEntry, Key: "PATH", Value: "/usr/bin"},
+		{Type: KeyValueEntry, Key: "Path", Value: "/opt/bin"},
+		{Type: KeyValueEntry, Key: "HOME", Value: "/home/Path"},
+	}
+
+	if got := RankEntries(entries, "Path", nil, false); len(got) != 3 {
+		t.Errorf("ignoring case should match all 3 entries, got %d", len(got))
+	}
+	got := RankEntries(entries, "Path", nil, true)
+	if len(got) != 2 || got[0].Key != "Path" || got[1].Key != "HOME" {
+		t.Errorf("matching case should find Path and the value of HOME, got %v", got)
+	}
+
+	comments := map[*Entry]string{entries[0]: "Search Path for binaries"}
+	if got := RankEntries(entries[:1], "search path", comments, true); len(got) != 0 {
+		t.Errorf("comments should match case too, got %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+import "strings"
+
+// IncludeDirective marks a comment naming a file whose entries the file
+// inherits, e.g. "# envtui:include base.env"
+const IncludeDirective = "envtui:include"
+
+// Include is a file named by an include directive
+type Include struct {
+	Path string // As written, relative to the directory of the including file
+	Line int
+}
+
+// Includes returns the files named by the file's include directives, in file
+// order. Directives may stand alone or in the comments above a key.
+func (ef *EnvFile) Includes() []Include {
+	var includes []Include
+	add := func(comment string, line int) {
+		text := commentText(comment)
+		if !strings.HasPrefix(text, IncludeDirective) {
+			return
+		}
+		if path := strings.TrimSpace(strings.TrimPrefix(text, IncludeDirective)); path != "" {
+			includes = append(includes, Include{Path: path, Line: line})
+		}
+	}
+
+	for _, entry := range ef.Entries {
+		switch entry.Type {
+		case CommentEntry:
+			add(entry.Comment, entry.Line)
+		case KeyValueEntry:
+			// Leading comments are the lines directly above the key
+			for i, comment := range entry.LeadingComments {
+				add(comment, entry.Line-len(entry.LeadingComments)+i)
+			}
+		}
+	}
+	return includes
+}
+
+// InheritedEntries returns the key/value entries the included files provide for
+// keys the file does not set itself, with the path of the file each one comes
+// from. Layers earlier in Layers win. Within a layer the last definition of a
+// key wins, as dotenv loaders assign each line in turn; it is listed where the
+// key first appears.
+func (ef *EnvFile) InheritedEntries() ([]*Entry, map[*Entry]string) {
+	if len(ef.Layers) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range ef.Entries {
+		if entry.Type == KeyValueEntry {
+			seen[entry.Key] = true
+		}
+	}
+
+	var inherited []*Entry
+	sources := make(map[*Entry]string)
+	for _, layer := range ef.Layers {
+		last := make(map[string]*Entry)
+		for _, entry := range layer.Entries {
+			if entry.Type == KeyValueEntry {
+				last[entry.Key] = entry
+			}
+		}
+		for _, entry := range layer.Entries {
+			if entry.Type != KeyValueEntry || seen[entry.Key] {
+				continue
+			}
+			seen[entry.Key] = true
+			entry = last[entry.Key]
+			inherited = append(inherited, entry)
+			sources[entry] = layer.Path
+		}
+	}
+	return inherited, sources
+}
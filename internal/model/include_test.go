@@ -0,0 +1,28 @@
+package model
+
+import "testing"
+
+func TestInheritedEntriesLastDefinitionWins(t *testing.T) {
+	envFile := effectiveFile(".env", "PORT", "3000")
+	envFile.Layers = []*EnvFile{
+		effectiveFile("base.env", "REGION", "eu", "PORT", "1", "REGION", "us"),
+		effectiveFile("common.env", "REGION", "ap", "TIMEOUT", "30"),
+	}
+
+	inherited, sources := envFile.InheritedEntries()
+	if len(inherited) != 2 {
+		t.Fatalf("expected REGION and TIMEOUT to be inherited, got %d entries", len(inherited))
+	}
+	if inherited[0].Key != "REGION" || inherited[0].Value != "us" || sources[inherited[0]] != "base.env" {
+		t.Errorf("expected the last REGION in base.env to win, got %s=%s from %s",
+			inherited[0].Key, inherited[0].Value, sources[inherited[0]])
+	}
+	if inherited[1].Key != "TIMEOUT" || sources[inherited[1]] != "common.env" {
+		t.Errorf("expected TIMEOUT from common.env, got %s from %s", inherited[1].Key, sources[inherited[1]])
+	}
+
+	resolved := ResolveEffective([]*EnvFile{envFile.Layers[0]}, nil)
+	if resolved["REGION"].Value != inherited[0].Value {
+		t.Errorf("inherited REGION %q should match the effective value %q", inherited[0].Value, resolved["REGION"].Value)
+	}
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskStyle controls how secret values are hidden on screen
+type MaskStyle struct {
+	// Char is the glyph repeated in place of the value
+	Char string
+	// MatchLength shows one glyph per character of the value, up to
+	// MaxMaskLength, instead of a fixed DefaultMaskLength
+	MatchLength bool
+	// RevealSuffix leaves the last characters visible, e.g. 4 for "••••••••1234".
+	// Values no more than twice that long are masked completely.
+	RevealSuffix int
+}
+
+const (
+	// MaskedPlaceholder replaces secret values in exports and patches. Unlike the
+	// on-screen mask it does not depend on the mask style, so files written with
+	// it look the same everywhere and can be recognized on import.
+	MaskedPlaceholder = "********"
+	// DefaultMaskLength is the number of glyphs shown for any secret value
+	DefaultMaskLength = 8
+	// MaxMaskLength caps the glyphs shown when the mask matches the value length
+	MaxMaskLength = 32
+)
+
+// DefaultMaskStyle is eight bullets, whatever the value
+var DefaultMaskStyle = MaskStyle{Char: "•"}
+
+var maskStyle = DefaultMaskStyle
+
+// SetMaskStyle sets how secret values are masked. The glyph must be a single
+// character; an empty one keeps the default bullet.
+func SetMaskStyle(style MaskStyle) error {
+	if style.Char == "" {
+		style.Char = DefaultMaskStyle.Char
+	}
+	if utf8.RuneCountInString(style.Char) != 1 {
+		return fmt.Errorf("mask character must be a single character, got %q", style.Char)
+	}
+	if style.RevealSuffix < 0 {
+		return fmt.Errorf("reveal suffix must not be negative, got %d", style.RevealSuffix)
+	}
+	maskStyle = style
+	return nil
+}
+
+// MaskValue hides a secret value according to the mask style (see SetMaskStyle)
+func MaskValue(value string) string {
+	runes := []rune(value)
+	suffix := ""
+	if n := maskStyle.RevealSuffix; n > 0 && len(runes) > 2*n {
+		suffix = string(runes[len(runes)-n:])
+		runes = runes[:len(runes)-n]
+	}
+	return maskGlyphs(len(runes)) + suffix
+}
+
+// maskGlyphs returns the glyphs that stand for n hidden characters
+func maskGlyphs(n int) string {
+	if maskStyle.MatchLength {
+		return strings.Repeat(maskStyle.Char, min(n, MaxMaskLength))
+	}
+	return strings.Repeat(maskStyle.Char, DefaultMaskLength)
+}
+
+// MaskedPreview returns the value with only its first prefix and last suffix
+// characters visible, e.g. "ghp_••••••••3f2a", enough to tell tokens that share
+// a prefix apart. Values no more than twice as long as the visible part are
+// masked completely.
+func (e *Entry) MaskedPreview(prefix, suffix int) string {
+	runes := []rune(e.Value)
+	if prefix < 0 || suffix < 0 || len(runes) <= 2*(prefix+suffix) {
+		return maskGlyphs(len(runes))
+	}
+	hidden := runes[prefix : len(runes)-suffix]
+	return string(runes[:prefix]) + maskGlyphs(len(hidden)) + string(runes[len(runes)-suffix:])
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MarkModified records that key was changed at the given time
+func (ef *EnvFile) MarkModified(key string, at time.Time) {
+	if ef.LastModified == nil {
+		ef.LastModified = make(map[string]time.Time)
+	}
+	ef.LastModified[key] = at
+}
+
+// ModifiedAt returns when key was last changed, if that is known
+func (ef *EnvFile) ModifiedAt(key string) (time.Time, bool) {
+	at, ok := ef.LastModified[key]
+	return at, ok
+}
+
+// FormatAge renders how long ago t was, e.g. "just now", "2m ago" or "3d ago"
+func FormatAge(t, now time.Time) string {
+	age := now.Sub(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastModifiedFollowsRenames(t *testing.T) {
+	ef := &EnvFile{Entries: []*Entry{{Type: KeyValueEntry, Key: "PORT", Value: "8080"}}}
+	at := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	ef.MarkModified("PORT", at)
+
+	if err := ef.RenameEntry("PORT", "HTTP_PORT"); err != nil {
+		t.Fatalf("RenameEntry() error = %v", err)
+	}
+	if _, ok := ef.ModifiedAt("PORT"); ok {
+		t.Error("the old key should have no timestamp")
+	}
+	if got, ok := ef.ModifiedAt("HTTP_PORT"); !ok || !got.Equal(at) {
+		t.Errorf("ModifiedAt(HTTP_PORT) = %v, %v, want %v", got, ok, at)
+	}
+
+	clone := ef.Clone()
+	clone.MarkModified("HTTP_PORT", at.Add(time.Hour))
+	if got, _ := ef.ModifiedAt("HTTP_PORT"); !got.Equal(at) {
+		t.Error("marking a clone should not change the original")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{2 * time.Minute, "2m ago"},
+		{5 * time.Hour, "5h ago"},
+		{72 * time.Hour, "3d ago"},
+	}
+	for _, tt := range tests {
+		if got := FormatAge(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("FormatAge(%v ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package model
+
+import "strings"
+
+// References returns the names of the ${VAR} references in value, in order.
+// References with a default, such as ${VAR:-fallback}, and escaped ones (\${VAR})
+// are left out, since they never expand to an unexpected empty value.
+func References(value string) []string {
+	var names []string
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++ // Skip the escaped character
+			continue
+		}
+		if !strings.HasPrefix(value[i:], "${") {
+			continue
+		}
+		end := strings.IndexByte(value[i+2:], '}')
+		if end == -1 {
+			break
+		}
+		if name := value[i+2 : i+2+end]; IsValidKey(name) {
+			names = append(names, name)
+		}
+		i += end + 2
+	}
+	return names
+}
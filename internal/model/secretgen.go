@@ -0,0 +1,135 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// SecretKind is the format of a generated secret value
+type SecretKind int
+
+const (
+	SecretHex SecretKind = iota
+	SecretBase64
+	SecretUUID
+	SecretAlphanumeric
+)
+
+func (sk SecretKind) String() string {
+	switch sk {
+	case SecretHex:
+		return "hex"
+	case SecretBase64:
+		return "base64"
+	case SecretUUID:
+		return "uuid"
+	case SecretAlphanumeric:
+		return "alphanumeric"
+	default:
+		return "unknown"
+	}
+}
+
+// SecretSpec describes a secret to generate
+type SecretSpec struct {
+	Kind   SecretKind
+	Length int // Bytes for hex/base64, characters for alphanumeric, ignored for uuid
+}
+
+// Label returns a short human readable description of the spec
+func (s SecretSpec) Label() string {
+	switch s.Kind {
+	case SecretUUID:
+		return "UUID v4"
+	case SecretAlphanumeric:
+		return fmt.Sprintf("alphanumeric (%d chars)", s.Length)
+	default:
+		return fmt.Sprintf("%s (%d bytes)", s.Kind, s.Length)
+	}
+}
+
+// DefaultSecretSpecs lists the generator choices offered in the UI
+var DefaultSecretSpecs = []SecretSpec{
+	{Kind: SecretHex, Length: 32},
+	{Kind: SecretHex, Length: 16},
+	{Kind: SecretBase64, Length: 32},
+	{Kind: SecretBase64, Length: 64},
+	{Kind: SecretUUID},
+	{Kind: SecretAlphanumeric, Length: 24},
+}
+
+const alphanumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexPattern  = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// GenerateSecret returns a random value for the given spec using crypto/rand
+func GenerateSecret(spec SecretSpec) (string, error) {
+	if spec.Kind != SecretUUID && spec.Length <= 0 {
+		return "", fmt.Errorf("invalid %s secret length: %d", spec.Kind, spec.Length)
+	}
+	switch spec.Kind {
+	case SecretUUID:
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		b[6] = (b[6] & 0x0f) | 0x40 // version 4
+		b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	case SecretAlphanumeric:
+		var sb strings.Builder
+		limit := big.NewInt(int64(len(alphanumeric)))
+		for i := 0; i < spec.Length; i++ {
+			n, err := rand.Int(rand.Reader, limit)
+			if err != nil {
+				return "", fmt.Errorf("failed to read random bytes: %w", err)
+			}
+			sb.WriteByte(alphanumeric[n.Int64()])
+		}
+		return sb.String(), nil
+	case SecretHex, SecretBase64:
+		b := make([]byte, spec.Length)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		if spec.Kind == SecretHex {
+			return hex.EncodeToString(b), nil
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	default:
+		return "", fmt.Errorf("unsupported secret kind: %v", spec.Kind)
+	}
+}
+
+// SuggestSecretSpec guesses a suitable secret format from the key name and current value
+func SuggestSecretSpec(key, currentValue string) SecretSpec {
+	// Match the shape of an existing value first
+	switch {
+	case uuidPattern.MatchString(currentValue):
+		return SecretSpec{Kind: SecretUUID}
+	case len(currentValue) >= 16 && len(currentValue)%2 == 0 && hexPattern.MatchString(currentValue):
+		return SecretSpec{Kind: SecretHex, Length: len(currentValue) / 2}
+	}
+
+	upperKey := strings.ToUpper(key)
+	switch {
+	case strings.Contains(upperKey, "UUID") || strings.Contains(upperKey, "GUID"):
+		return SecretSpec{Kind: SecretUUID}
+	case strings.Contains(upperKey, "BASE64") || strings.Contains(upperKey, "B64"):
+		return SecretSpec{Kind: SecretBase64, Length: 32}
+	case strings.Contains(upperKey, "JWT") || strings.Contains(upperKey, "SESSION") || strings.Contains(upperKey, "COOKIE"):
+		return SecretSpec{Kind: SecretBase64, Length: 64}
+	case strings.Contains(upperKey, "PASSWORD") || strings.HasSuffix(upperKey, "_PASS"):
+		return SecretSpec{Kind: SecretAlphanumeric, Length: 24}
+	}
+
+	return SecretSpec{Kind: SecretHex, Length: 32}
+}
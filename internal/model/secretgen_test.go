@@ -0,0 +1,76 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	tests := []struct {
+		spec   SecretSpec
+		length int
+		valid  func(string) bool
+	}{
+		{SecretSpec{Kind: SecretHex, Length: 16}, 32, func(v string) bool {
+			b, err := hex.DecodeString(v)
+			return err == nil && len(b) == 16
+		}},
+		{SecretSpec{Kind: SecretBase64, Length: 32}, 44, func(v string) bool {
+			b, err := base64.StdEncoding.DecodeString(v)
+			return err == nil && len(b) == 32
+		}},
+		{SecretSpec{Kind: SecretUUID}, 36, func(v string) bool {
+			// Version 4, RFC 4122 variant
+			return uuidPattern.MatchString(v) && v[14] == '4' && strings.ContainsRune("89ab", rune(v[19]))
+		}},
+		{SecretSpec{Kind: SecretAlphanumeric, Length: 24}, 24, func(v string) bool {
+			return strings.Trim(v, alphanumeric) == ""
+		}},
+	}
+
+	for _, tt := range tests {
+		first, err := GenerateSecret(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.spec.Label(), err)
+		}
+		if len(first) != tt.length || !tt.valid(first) {
+			t.Errorf("%s: got %q (%d chars), want %d valid chars", tt.spec.Label(), first, len(first), tt.length)
+		}
+		if second, _ := GenerateSecret(tt.spec); second == first {
+			t.Errorf("%s: two secrets in a row were both %q", tt.spec.Label(), first)
+		}
+	}
+}
+
+func TestGenerateSecretErrors(t *testing.T) {
+	for _, spec := range []SecretSpec{
+		{Kind: SecretKind(99), Length: 16},
+		{Kind: SecretHex, Length: 0},
+		{Kind: SecretBase64, Length: -1},
+		{Kind: SecretAlphanumeric},
+	} {
+		if value, err := GenerateSecret(spec); err == nil {
+			t.Errorf("%+v: expected an error, got %q", spec, value)
+		}
+	}
+}
+
+func TestSuggestSecretSpec(t *testing.T) {
+	tests := []struct {
+		key, value string
+		want       SecretSpec
+	}{
+		{"REQUEST_ID", "123e4567-e89b-42d3-a456-426614174000", SecretSpec{Kind: SecretUUID}},
+		{"SIGNING_KEY", "00112233445566778899aabbccddeeff", SecretSpec{Kind: SecretHex, Length: 16}},
+		{"SESSION_SECRET", "", SecretSpec{Kind: SecretBase64, Length: 64}},
+		{"DB_PASSWORD", "hunter2", SecretSpec{Kind: SecretAlphanumeric, Length: 24}},
+		{"API_KEY", "", SecretSpec{Kind: SecretHex, Length: 32}},
+	}
+	for _, tt := range tests {
+		if got := SuggestSecretSpec(tt.key, tt.value); got != tt.want {
+			t.Errorf("SuggestSecretSpec(%q, %q) = %+v, want %+v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
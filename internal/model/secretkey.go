@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// secretKeywords are the built-in secret key substrings, always checked
+var secretKeywords = []string{
+	"PASSWORD", "SECRET", "TOKEN", "KEY", "PRIVATE",
+	"API_KEY", "AUTH", "CREDENTIAL", "CERT",
+}
+
+// regexPatternPrefix marks a custom secret pattern as a regular expression
+const regexPatternPrefix = "re:"
+
+// customSecretKeywords and customSecretRegexps extend the built-in keywords
+var (
+	customSecretKeywords []string
+	customSecretRegexps  []*regexp.Regexp
+)
+
+// SetSecretPatterns sets additional patterns that mark a key as secret, on top of
+// the built-in keywords. Patterns are case-insensitive substrings (e.g. "VAULT_", "_PW");
+// a "re:" prefix makes the rest a case-insensitive regular expression (e.g. "re:^VAULT_").
+// Passing nil restores the built-in behavior.
+func SetSecretPatterns(patterns []string) error {
+	var keywords []string
+	var regexps []*regexp.Regexp
+
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, regexPatternPrefix); ok {
+			re, err := regexp.Compile("(?i)" + expr)
+			if err != nil {
+				return fmt.Errorf("invalid secret pattern %q: %w", pattern, err)
+			}
+			regexps = append(regexps, re)
+			continue
+		}
+		if pattern != "" {
+			keywords = append(keywords, strings.ToUpper(pattern))
+		}
+	}
+
+	customSecretKeywords = keywords
+	customSecretRegexps = regexps
+	return nil
+}
+
+// IsSecretKey returns true if the key name marks its value as secret
+func IsSecretKey(key string) bool {
+	upperKey := strings.ToUpper(key)
+	for _, keyword := range secretKeywords {
+		if strings.Contains(upperKey, keyword) {
+			return true
+		}
+	}
+	for _, keyword := range customSecretKeywords {
+		if strings.Contains(upperKey, keyword) {
+			return true
+		}
+	}
+	for _, re := range customSecretRegexps {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidKey returns true if the key is a valid env variable name
+func IsValidKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+
+	for i, ch := range key {
+		if i == 0 && !unicode.IsLetter(ch) && ch != '_' {
+			return false
+		}
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '_' {
+			return false
+		}
+	}
+
+	return true
+}
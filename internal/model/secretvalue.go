@@ -0,0 +1,102 @@
+package model
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// knownTokenPatterns match well-known credential formats
+var knownTokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`),                           // AWS access key ID
+	regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`), // JWT
+	regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`),               // PEM private key
+	regexp.MustCompile(`^gh[pousr]_[A-Za-z0-9]{36,}$`),                        // GitHub token
+	regexp.MustCompile(`^xox[abposr]-[A-Za-z0-9-]{10,}$`),                     // Slack token
+	regexp.MustCompile(`^(sk|rk)_(live|test)_[A-Za-z0-9]{16,}$`),              // Stripe key
+	regexp.MustCompile(`^AIza[0-9A-Za-z_-]{35}$`),                             // Google API key
+}
+
+// minEntropyLength and minEntropyBits tune the high-entropy heuristic
+const (
+	minEntropyLength = 20
+	minEntropyBits   = 3.5
+)
+
+// DetectSecretValue returns true if the value matches a known token format
+// or looks like a high-entropy random string
+func DetectSecretValue(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+
+	for _, pattern := range knownTokenPatterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+
+	return isHighEntropy(value)
+}
+
+// CommentHasSecret reports whether a comment, such as "# old token was ghp_...",
+// contains a word that looks like a credential (see DetectSecretValue)
+func CommentHasSecret(comment string) bool {
+	words := strings.FieldsFunc(strings.TrimPrefix(strings.TrimSpace(comment), "#"), func(r rune) bool {
+		switch r {
+		case ' ', '\t', '=', ',', ';', '"', '\'', '`':
+			return true
+		}
+		return false
+	})
+	for _, word := range words {
+		if DetectSecretValue(strings.TrimRight(word, ".:)")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHighEntropy returns true for long, space-free, mixed-character strings with
+// high Shannon entropy. URLs, paths and UUIDs are treated as identifiers.
+func isHighEntropy(value string) bool {
+	if len(value) < minEntropyLength || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+	if strings.Contains(value, "://") || strings.HasPrefix(value, "/") || uuidPattern.MatchString(value) {
+		return false
+	}
+
+	hasLetter, hasDigit := false, false
+	for _, ch := range value {
+		switch {
+		case ch >= '0' && ch <= '9':
+			hasDigit = true
+		case (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+			hasLetter = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return false
+	}
+
+	return shannonEntropy(value) >= minEntropyBits
+}
+
+// shannonEntropy returns the entropy of the string in bits per character
+func shannonEntropy(value string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, ch := range value {
+		counts[ch]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -2,6 +2,9 @@ package model
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -36,21 +39,32 @@ func (e *Entry) Validate() []ValidationIssue {
 		})
 	}
 	
-	// Check for spaces in unquoted values
-	if strings.Contains(e.Value, " ") && !e.Exported {
+	// Check for suspicious patterns
+	if e.IsSecret && (e.Value == "" || e.Value == "changeme" || e.Value == "password") {
 		issues = append(issues, ValidationIssue{
 			Level:   ValidationWarning,
-			Message: fmt.Sprintf("Value contains spaces, consider quoting: %s", e.Key),
+			Message: fmt.Sprintf("Suspicious secret value: %s", e.Key),
 			Line:    e.Line,
 			Key:     e.Key,
 		})
 	}
 	
-	// Check for suspicious patterns
-	if e.IsSecret && (e.Value == "" || e.Value == "changeme" || e.Value == "password") {
+	// Empty values are often placeholders still to be filled in; a secret's
+	// is already suspicious
+	if !e.IsSecret && e.Value == "" {
+		issues = append(issues, ValidationIssue{
+			Level:   ValidationInfo,
+			Message: fmt.Sprintf("%s has an empty value", e.Key),
+			Line:    e.Line,
+			Key:     e.Key,
+		})
+	}
+	
+	// Check for secret-looking values under keys not treated as secret
+	if !e.IsSecret && DetectSecretValue(e.Value) {
 		issues = append(issues, ValidationIssue{
 			Level:   ValidationWarning,
-			Message: fmt.Sprintf("Suspicious secret value: %s", e.Key),
+			Message: fmt.Sprintf("Value of %s looks like a secret but the key is not masked", e.Key),
 			Line:    e.Line,
 			Key:     e.Key,
 		})
@@ -82,6 +96,248 @@ func (ef *EnvFile) Validate() []ValidationIssue {
 			keysSeen[entry.Key] = entry.Line
 		}
 	}
+
+	issues = append(issues, ef.undefinedReferences()...)
+	issues = append(issues, ef.commentSecrets()...)
+	issues = append(issues, ef.ParseIssues...)
+	issues = append(issues, ef.skippedLines()...)
 	
 	return issues
-}
\ No newline at end of file
+}
+
+// skippedLines warns about each line the parser could not read; saving the file
+// drops them
+func (ef *EnvFile) skippedLines() []ValidationIssue {
+	var issues []ValidationIssue
+	for _, skipped := range ef.SkippedLines {
+		issues = append(issues, ValidationIssue{
+			Level:   ValidationWarning,
+			Message: fmt.Sprintf("Line could not be parsed (%s) and is dropped on save: %s", skipped.Reason, strings.TrimSpace(skipped.Text)),
+			Line:    skipped.Line,
+		})
+	}
+	return issues
+}
+
+// undefinedReferences flags ${VAR} references to variables that are neither
+// defined earlier in the file nor set in the environment; they would expand to
+// an empty value. Single-quoted values are literal and not checked.
+func (ef *EnvFile) undefinedReferences() []ValidationIssue {
+	var issues []ValidationIssue
+	defined := make(map[string]bool)
+	for _, entry := range ef.Entries {
+		if entry.Type != KeyValueEntry {
+			continue
+		}
+		if entry.QuoteStyle != QuoteSingle {
+			for _, name := range References(entry.Value) {
+				if _, set := os.LookupEnv(name); defined[name] || set || ef.hasParseIssue(entry.Key, "${"+name+"}") {
+					continue
+				}
+				issues = append(issues, ValidationIssue{
+					Level:   ValidationWarning,
+					Message: fmt.Sprintf("%s references ${%s}, which is not defined earlier in the file or in the environment", entry.Key, name),
+					Line:    entry.Line,
+					Key:     entry.Key,
+				})
+			}
+		}
+		defined[entry.Key] = true
+	}
+	return issues
+}
+
+// commentSecrets flags comments that look like they hold a credential, e.g. an
+// old token pasted above a key. Comments of a key's entry are reported under
+// that key, so its row gets the marker.
+func (ef *EnvFile) commentSecrets() []ValidationIssue {
+	var issues []ValidationIssue
+	for _, entry := range ef.Entries {
+		var comments []string
+		switch entry.Type {
+		case CommentEntry:
+			comments = []string{entry.Comment}
+		case KeyValueEntry, BareKeyEntry:
+			comments = append(append(comments, entry.LeadingComments...), entry.Comment)
+		}
+		for _, comment := range comments {
+			if !CommentHasSecret(comment) {
+				continue
+			}
+			message := fmt.Sprintf("Comment on line %d may contain a secret", entry.Line)
+			if entry.Key != "" {
+				message = fmt.Sprintf("A comment of %s may contain a secret", entry.Key)
+			}
+			issues = append(issues, ValidationIssue{
+				Level:   ValidationWarning,
+				Message: message,
+				Line:    entry.Line,
+				Key:     entry.Key,
+			})
+			break
+		}
+	}
+	return issues
+}
+
+// hasParseIssue reports whether parsing already reported an issue about text in key,
+// e.g. an unresolved reference found while interpolating
+func (ef *EnvFile) hasParseIssue(key, text string) bool {
+	for _, issue := range ef.ParseIssues {
+		if issue.Key == key && strings.Contains(issue.Message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaType is the type a value must have to satisfy a schema
+type SchemaType string
+
+const (
+	SchemaString SchemaType = "string"
+	SchemaInt    SchemaType = "int"
+	SchemaBool   SchemaType = "bool"
+	SchemaURL    SchemaType = "url"
+	SchemaEnum   SchemaType = "enum"
+)
+
+// SchemaField describes one key expected in an env file
+type SchemaField struct {
+	Key      string
+	Type     SchemaType
+	Required bool
+	Enum     []string // Allowed values when Type is SchemaEnum
+}
+
+// Schema is the set of keys and value types an env file is checked against
+type Schema struct {
+	Fields []SchemaField
+}
+
+// ParseSchema parses schema lines of the form "KEY = type [required]", where type
+// is string, int, bool, url or enum(a,b,c). Blank lines and lines starting with #
+// are ignored.
+func ParseSchema(content string) (Schema, error) {
+	var schema Schema
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		fields := strings.Fields(rest)
+		if !ok || key == "" || len(fields) == 0 {
+			return Schema{}, fmt.Errorf("line %d: expected KEY = type [required]", i+1)
+		}
+
+		field := SchemaField{Key: key}
+		switch fields[len(fields)-1] {
+		case "required":
+			field.Required = true
+			fields = fields[:len(fields)-1]
+		case "optional":
+			fields = fields[:len(fields)-1]
+		}
+		typeName := strings.Join(fields, "")
+
+		switch {
+		case strings.HasPrefix(typeName, "enum(") && strings.HasSuffix(typeName, ")"):
+			field.Type = SchemaEnum
+			for _, value := range strings.Split(typeName[len("enum("):len(typeName)-1], ",") {
+				if value != "" {
+					field.Enum = append(field.Enum, value)
+				}
+			}
+			if len(field.Enum) == 0 {
+				return Schema{}, fmt.Errorf("line %d: enum for %s has no values", i+1, key)
+			}
+		case typeName == "", typeName == string(SchemaString):
+			field.Type = SchemaString
+		case typeName == string(SchemaInt), typeName == string(SchemaBool), typeName == string(SchemaURL):
+			field.Type = SchemaType(typeName)
+		default:
+			return Schema{}, fmt.Errorf("line %d: unknown type %q for %s", i+1, typeName, key)
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	return schema, nil
+}
+
+// ValidateSchema checks that every required key in the schema is present and
+// non-empty, and that values match their declared types
+func (ef *EnvFile) ValidateSchema(schema Schema) []ValidationIssue {
+	var issues []ValidationIssue
+
+	for _, field := range schema.Fields {
+		found := false
+		for _, entry := range ef.Entries {
+			if entry.Type != KeyValueEntry || entry.Key != field.Key {
+				continue
+			}
+			found = true
+
+			if entry.Value == "" {
+				if field.Required {
+					issues = append(issues, ValidationIssue{
+						Level:   ValidationError,
+						Message: fmt.Sprintf("Required key %s is empty", field.Key),
+						Line:    entry.Line,
+						Key:     entry.Key,
+					})
+				}
+				continue
+			}
+
+			if problem := field.check(entry.Value); problem != "" {
+				issues = append(issues, ValidationIssue{
+					Level:   ValidationError,
+					Message: fmt.Sprintf("%s %s", field.Key, problem),
+					Line:    entry.Line,
+					Key:     entry.Key,
+				})
+			}
+		}
+
+		if !found && field.Required {
+			issues = append(issues, ValidationIssue{
+				Level:   ValidationError,
+				Message: fmt.Sprintf("Missing required key %s", field.Key),
+				Key:     field.Key,
+			})
+		}
+	}
+
+	return issues
+}
+
+// check returns a description of why value does not match the field's type,
+// or "" if it does
+func (field SchemaField) check(value string) string {
+	switch field.Type {
+	case SchemaInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Sprintf("must be an integer, got %q", value)
+		}
+	case SchemaBool:
+		switch strings.ToLower(value) {
+		case "true", "false", "1", "0", "yes", "no", "on", "off":
+		default:
+			return fmt.Sprintf("must be a boolean, got %q", value)
+		}
+	case SchemaURL:
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Sprintf("must be a URL with a scheme and host, got %q", value)
+		}
+	case SchemaEnum:
+		for _, allowed := range field.Enum {
+			if value == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(field.Enum, ", "), value)
+	}
+	return ""
+}
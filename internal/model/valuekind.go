@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ValueKind is the kind of data a value appears to hold
+type ValueKind int
+
+const (
+	ValueEmpty ValueKind = iota
+	ValueText
+	ValueURL
+	ValueNumber
+	ValueBoolean
+	ValueUUID
+	ValueBase64
+)
+
+func (vk ValueKind) String() string {
+	switch vk {
+	case ValueEmpty:
+		return "empty"
+	case ValueText:
+		return "text"
+	case ValueURL:
+		return "url"
+	case ValueNumber:
+		return "number"
+	case ValueBoolean:
+		return "boolean"
+	case ValueUUID:
+		return "uuid"
+	case ValueBase64:
+		return "base64"
+	default:
+		return "unknown"
+	}
+}
+
+// LongValueLength is the length past which a value is flagged as unusually long
+const LongValueLength = 1024
+
+// minBase64Length keeps short words such as "test" from being taken for base64
+const minBase64Length = 16
+
+var (
+	numberPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	base64Pattern = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+)
+
+// InferValueType guesses what kind of data a value holds, from the most to the
+// least specific kind. It is a hint for the editor, not a validation.
+func InferValueType(value string) ValueKind {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return ValueEmpty
+	case isBooleanValue(value):
+		return ValueBoolean
+	case numberPattern.MatchString(value):
+		return ValueNumber
+	case uuidPattern.MatchString(value):
+		return ValueUUID
+	case isURLValue(value):
+		return ValueURL
+	case isBase64Value(value):
+		return ValueBase64
+	default:
+		return ValueText
+	}
+}
+
+func isBooleanValue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off":
+		return true
+	}
+	return false
+}
+
+func isURLValue(value string) bool {
+	if strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// isBase64Value reports whether value decodes as standard or URL-safe base64.
+// Plain words and hex strings also decode, so a value needs a digit or a base64
+// symbol along with upper and lower case letters.
+func isBase64Value(value string) bool {
+	if len(value) < minBase64Length || len(value)%4 != 0 || !base64Pattern.MatchString(value) {
+		return false
+	}
+	if !strings.ContainsAny(value, "0123456789+/_-=") || strings.ToLower(value) == value || strings.ToUpper(value) == value {
+		return false
+	}
+	if _, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return true
+	}
+	_, err := base64.URLEncoding.DecodeString(value)
+	return err == nil
+}
@@ -0,0 +1,5 @@
+// Package parser reads .env content into model.EnvFile values. ParseString and
+// ParseFile are the entry points; the result keeps comments, blank lines, quote
+// styles and line endings, so EnvFile.String writes the content back unchanged.
+// The envfile package exposes the same API outside this module.
+package parser
@@ -29,6 +29,9 @@ type Lexer struct {
 	line    int
 	col     int
 	current rune
+	// inValue is set after a KEY token so the next token is its raw value,
+	// which may contain '=' and other characters that are not identifiers
+	inValue bool
 }
 
 func NewLexer(input string) *Lexer {
@@ -91,6 +94,7 @@ func (l *Lexer) readIdentifier() string {
 }
 
 func (l *Lexer) ReadValue() string {
+	l.inValue = false
 	// We're positioned right after the = sign and any whitespace
 	// l.current points to the first character of the value
 	start := l.pos - 1 // Start from current character
@@ -155,6 +159,12 @@ func (l *Lexer) NextToken() Token {
 	
 	token := Token{Line: l.line, Col: l.col}
 	
+	if l.inValue {
+		token.Type = VALUE
+		token.Value = l.ReadValue()
+		return token
+	}
+	
 	switch l.current {
 	case 0:
 		token.Type = EOF
@@ -184,6 +194,7 @@ func (l *Lexer) NextToken() Token {
 				token.Value = identifier
 				l.readChar() // consume =
 				l.skipWhitespace()
+				l.inValue = true
 			} else {
 				// Not a key, treat as value
 				token.Type = VALUE
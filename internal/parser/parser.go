@@ -2,7 +2,6 @@ package parser
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/envtui/envtui/internal/model"
 )
@@ -13,6 +12,10 @@ type Parser struct {
 	peekToken    Token
 }
 
+// NewParser returns the older token based parser.
+//
+// Deprecated: use ParseString or ParseFile, which handle multiline values,
+// inline comments and line endings the way envtui reads and writes files.
 func NewParser(input string) *Parser {
 	p := &Parser{lexer: NewLexer(input)}
 	p.nextToken()
@@ -80,12 +83,14 @@ func (p *Parser) parseEntry(exported bool) (*model.Entry, error) {
 	key := p.currentToken.Value
 	line := p.currentToken.Line
 	
-	// Read the value directly from lexer
-	value := p.lexer.ReadValue()
-	
-	// Resync parser tokens with lexer
-	p.currentToken = p.lexer.NextToken()
-	p.peekToken = p.lexer.NextToken()
+	// The lexer emits the raw value as the token right after the key, so
+	// values containing '=' are not split into further keys
+	var value string
+	if p.peekToken.Type == VALUE {
+		value = p.peekToken.Value
+		p.nextToken()
+	}
+	p.nextToken()
 
 	var inlineComment string
 	// Check for inline comment
@@ -108,20 +113,9 @@ func (p *Parser) parseEntry(exported bool) (*model.Entry, error) {
 }
 
 func isSecretKey(key string) bool {
-	secretKeywords := []string{
-		"PASSWORD", "SECRET", "TOKEN", "KEY", "PRIVATE",
-		"API_KEY", "AUTH", "CREDENTIAL", "CERT",
-	}
-	
-	upperKey := strings.ToUpper(key)
-	for _, keyword := range secretKeywords {
-		if strings.Contains(upperKey, keyword) {
-			return true
-		}
-	}
-	return false
+	return model.IsSecretKey(key)
 }
 
 func IsSecretKey(key string) bool {
 	return isSecretKey(key)
-}
\ No newline at end of file
+}
@@ -65,4 +65,57 @@ export NODE_ENV=development`
 	if !nodeEnv.Exported {
 		t.Errorf("expected NODE_ENV to be exported")
 	}
-}
\ No newline at end of file
+}
+
+func TestParserKeepsEqualsInValues(t *testing.T) {
+	input := "TOKEN=abc==\nURL=a?b=c&d=e # query\nexport QUOTED=\"x=y\"\nEMPTY=\n"
+
+	envFile, err := NewParser(input).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %v", err)
+	}
+
+	want := map[string]string{
+		"TOKEN":  "abc==",
+		"URL":    "a?b=c&d=e",
+		"QUOTED": "x=y",
+		"EMPTY":  "",
+	}
+	if got := len(envFile.FilterEntries("")); got != len(want) {
+		t.Fatalf("expected %d key-value entries, got %d", len(want), got)
+	}
+	for key, value := range want {
+		entry := envFile.GetEntry(key)
+		if entry == nil || entry.Value != value {
+			t.Errorf("expected %s=%s, got %+v", key, value, entry)
+		}
+	}
+	if comment := envFile.GetEntry("URL").Comment; comment != "# query" {
+		t.Errorf("expected inline comment '# query', got '%s'", comment)
+	}
+}
+
+func TestCustomSecretPatterns(t *testing.T) {
+	if err := model.SetSecretPatterns([]string{"vault_", "_PW", "re:^ORG_.*_SEED$"}); err != nil {
+		t.Fatalf("SetSecretPatterns() error = %v", err)
+	}
+	defer model.SetSecretPatterns(nil)
+
+	envFile, _ := Parse("VAULT_ADDR=x\nDB_PW=x\nORG_APP_SEED=x\nORG_APP_NAME=x\nDB_PASSWORD=x")
+	want := map[string]bool{
+		"VAULT_ADDR":   true,
+		"DB_PW":        true,
+		"ORG_APP_SEED": true,
+		"ORG_APP_NAME": false,
+		"DB_PASSWORD":  true,
+	}
+	for key, secret := range want {
+		if got := envFile.GetEntry(key).IsSecret; got != secret {
+			t.Errorf("%s IsSecret = %v, want %v", key, got, secret)
+		}
+	}
+
+	if err := model.SetSecretPatterns([]string{"re:("}); err == nil {
+		t.Error("expected error for invalid regex pattern")
+	}
+}
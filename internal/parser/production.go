@@ -1,15 +1,62 @@
 package parser
 
 import (
+	"fmt"
+	"os"
 	"strings"
-	"unicode"
 
 	"github.com/envtui/envtui/internal/model"
 )
 
+// ParseOptions configures optional parser behavior
+type ParseOptions struct {
+	// Interpolate resolves ${VAR} and $VAR references in unquoted and double-quoted
+	// values against keys defined earlier in the file, then Environ.
+	// Interpolated values are resolved, so such a file should not be written back.
+	Interpolate bool
+	// Environ is consulted for references not defined earlier in the file (e.g. the process environment)
+	Environ map[string]string
+}
+
+// Parse parses env file content. It is the same as ParseString.
 func Parse(input string) (*model.EnvFile, error) {
+	return ParseWithOptions(input, ParseOptions{})
+}
+
+// ParseString parses env file content. The file's String method writes it back.
+func ParseString(input string) (*model.EnvFile, error) {
+	return ParseWithOptions(input, ParseOptions{})
+}
+
+// ParseFile reads and parses the env file at path, recording the path in the result
+func ParseFile(path string) (*model.EnvFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	envFile, err := ParseString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	envFile.Path = path
+	return envFile, nil
+}
+
+// ParseWithOptions parses env file content using the given options
+func ParseWithOptions(input string, opts ParseOptions) (*model.EnvFile, error) {
 	envFile := &model.EnvFile{Entries: make([]*model.Entry, 0)}
-	lines := strings.Split(input, "\n")
+	// Windows line endings are remembered for writing back, and dropped so no
+	// value or comment keeps a stray \r
+	if crlf := strings.Count(input, "\r\n"); crlf > 0 && crlf*2 >= strings.Count(input, "\n") {
+		envFile.LineEnding = "\r\n"
+	}
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+	// A trailing newline terminates the last line rather than starting a blank one
+	lines := strings.Split(strings.TrimSuffix(input, "\n"), "\n")
+	if input == "" {
+		lines = nil
+	}
+	defined := make(map[string]string) // Resolved values of keys seen so far
 	
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
@@ -45,54 +92,135 @@ func Parse(input string) (*model.EnvFile, error) {
 		// Key=Value
 		eqIdx := strings.Index(trimmed, "=")
 		if eqIdx == -1 {
+			// A bare key, e.g. "export FOO", is kept as written
+			key, comment := trimmed, ""
+			if idx := strings.Index(trimmed, "#"); idx != -1 {
+				key, comment = strings.TrimSpace(trimmed[:idx]), inlineComment(trimmed[idx:])
+			}
+			if isValidKey(key) {
+				envFile.Entries = append(envFile.Entries, &model.Entry{
+					Type:     model.BareKeyEntry,
+					Key:      key,
+					Comment:  comment,
+					Line:     i + 1,
+					Exported: exported,
+				})
+				continue
+			}
+			envFile.SkippedLines = append(envFile.SkippedLines, model.SkipInfo{
+				Line:   i + 1,
+				Text:   line,
+				Reason: "not a KEY=value line",
+			})
 			continue // Skip invalid lines
 		}
 		
 		key := strings.TrimSpace(trimmed[:eqIdx])
 		if key == "" || !isValidKey(key) {
+			reason := fmt.Sprintf("invalid key %q", key)
+			if key == "" {
+				reason = "missing key before ="
+			}
+			envFile.SkippedLines = append(envFile.SkippedLines, model.SkipInfo{
+				Line:   i + 1,
+				Text:   line,
+				Reason: reason,
+			})
 			continue // Skip invalid keys
 		}
 		
 		valueStr := trimmed[eqIdx+1:]
-		value, consumed := parseValue(valueStr, lines, i)
+		value, comment, consumed := parseValue(valueStr, lines, i, opts.Interpolate)
 		i += consumed // Skip consumed lines for multiline values
-		
-		envFile.Entries = append(envFile.Entries, &model.Entry{
-			Type:     model.KeyValueEntry,
-			Key:      key,
-			Value:    value,
-			Line:     i + 1,
-			Exported: exported,
-			IsSecret: isSecretKey(key),
-		})
+
+		// Single-quoted values are literal
+		if opts.Interpolate && !isSingleQuoted(valueStr) {
+			var unresolved []string
+			value, unresolved = interpolate(value, func(name string) (string, bool) {
+				if v, ok := defined[name]; ok {
+					return v, true
+				}
+				v, ok := opts.Environ[name]
+				return v, ok
+			})
+			for _, name := range unresolved {
+				envFile.ParseIssues = append(envFile.ParseIssues, model.ValidationIssue{
+					Level:   model.ValidationInfo,
+					Message: fmt.Sprintf("Unresolved reference ${%s} in %s", name, key),
+					Line:    i + 1,
+					Key:     key,
+				})
+			}
+		}
+		defined[key] = value
+
+		entry := &model.Entry{
+			Type:       model.KeyValueEntry,
+			Key:        key,
+			Value:      value,
+			Comment:    comment,
+			Line:       i + 1,
+			Exported:   exported,
+			IsSecret:   isSecretKey(key),
+			QuoteStyle: quoteStyle(valueStr),
+		}
+		// Comment lines directly above the key document it and move with it
+		for n := len(envFile.Entries); n > 0 && envFile.Entries[n-1].Type == model.CommentEntry; n-- {
+			entry.LeadingComments = append([]string{envFile.Entries[n-1].Comment}, entry.LeadingComments...)
+			envFile.Entries = envFile.Entries[:n-1]
+		}
+		envFile.Entries = append(envFile.Entries, entry)
+	}
+	
+	// Blank lines at the end stay at the end when entries are added
+	for n := len(envFile.Entries); n > 0 && envFile.Entries[n-1].Type == model.BlankEntry; n-- {
+		envFile.TrailingBlankLines++
+		envFile.Entries = envFile.Entries[:n-1]
 	}
+	envFile.NoFinalNewline = input != "" && !strings.HasSuffix(input, "\n")
 	
 	return envFile, nil
 }
 
-func parseValue(valueStr string, lines []string, currentLine int) (string, int) {
+// parseValue parses a raw value and its inline comment. When keepDollarEscapes is set, \$ is
+// kept escaped in double-quoted values so interpolation can tell it apart from a reference.
+func parseValue(valueStr string, lines []string, currentLine int, keepDollarEscapes bool) (string, string, int) {
 	valueStr = strings.TrimSpace(valueStr)
 	
 	// Empty value
 	if valueStr == "" {
-		return "", 0
+		return "", "", 0
 	}
 	
 	// Quoted value (single or double)
 	if len(valueStr) > 0 && (valueStr[0] == '"' || valueStr[0] == '\'') {
 		quote := valueStr[0]
-		return parseQuotedValue(valueStr, quote, lines, currentLine)
+		value, rest, consumed := parseQuotedValue(valueStr, quote, lines, currentLine, keepDollarEscapes && quote == '"')
+		return value, inlineComment(rest), consumed
 	}
 	
 	// Unquoted value - read until comment or end
+	comment := ""
 	if idx := strings.Index(valueStr, "#"); idx != -1 {
+		comment = inlineComment(valueStr[idx:])
 		valueStr = strings.TrimSpace(valueStr[:idx])
 	}
 	
-	return valueStr, 0
+	return valueStr, comment, 0
 }
 
-func parseQuotedValue(valueStr string, quote byte, lines []string, currentLine int) (string, int) {
+// inlineComment returns the trimmed comment in the text following a value, if any
+func inlineComment(rest string) string {
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "#") {
+		return ""
+	}
+	return rest
+}
+
+// parseQuotedValue parses a quoted value, returning the value, the text after the
+// closing quote and the number of extra lines consumed
+func parseQuotedValue(valueStr string, quote byte, lines []string, currentLine int, keepDollarEscapes bool) (string, string, int) {
 	var result strings.Builder
 	i := 1 // Skip opening quote
 	linesConsumed := 0
@@ -116,6 +244,11 @@ func parseQuotedValue(valueStr string, quote byte, lines []string, currentLine i
 					result.WriteByte('\\')
 				case '"', '\'':
 					result.WriteByte(next)
+				case '$':
+					if keepDollarEscapes {
+						result.WriteByte('\\')
+					}
+					result.WriteByte(next)
 				default:
 					result.WriteByte(next)
 				}
@@ -124,7 +257,7 @@ func parseQuotedValue(valueStr string, quote byte, lines []string, currentLine i
 			}
 			
 			if ch == quote {
-				return result.String(), linesConsumed
+				return result.String(), currentLineStr[i+1:], linesConsumed
 			}
 			
 			result.WriteByte(ch)
@@ -142,22 +275,96 @@ func parseQuotedValue(valueStr string, quote byte, lines []string, currentLine i
 		}
 	}
 	
-	return result.String(), linesConsumed
+	return result.String(), "", linesConsumed
 }
 
-func isValidKey(key string) bool {
-	if len(key) == 0 {
-		return false
+func isSingleQuoted(valueStr string) bool {
+	valueStr = strings.TrimSpace(valueStr)
+	return len(valueStr) > 0 && valueStr[0] == '\''
+}
+
+// quoteStyle returns how a raw value is quoted
+func quoteStyle(valueStr string) model.QuoteStyle {
+	valueStr = strings.TrimSpace(valueStr)
+	switch {
+	case strings.HasPrefix(valueStr, "'"):
+		return model.QuoteSingle
+	case strings.HasPrefix(valueStr, "\""):
+		return model.QuoteDouble
 	}
-	
-	for i, ch := range key {
-		if i == 0 && !unicode.IsLetter(ch) && ch != '_' {
-			return false
+	return model.QuoteNone
+}
+
+// interpolate replaces ${VAR} and $VAR references using lookup. Unresolved references
+// are left intact and returned; \$ produces a literal $.
+func interpolate(value string, lookup func(string) (string, bool)) (string, []string) {
+	var result strings.Builder
+	var unresolved []string
+
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+
+		if ch == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			result.WriteByte('$')
+			i++
+			continue
+		}
+
+		if ch != '$' || i+1 >= len(value) {
+			result.WriteByte(ch)
+			continue
+		}
+
+		// ${VAR}
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i+2:], '}')
+			if end == -1 {
+				result.WriteByte(ch)
+				continue
+			}
+			name := value[i+2 : i+2+end]
+			ref := value[i : i+3+end]
+			if !isValidKey(name) {
+				result.WriteString(ref)
+			} else if resolved, ok := lookup(name); ok {
+				result.WriteString(resolved)
+			} else {
+				result.WriteString(ref)
+				unresolved = append(unresolved, name)
+			}
+			i += len(ref) - 1
+			continue
+		}
+
+		// $VAR
+		end := i + 1
+		for end < len(value) && isKeyChar(value[end], end == i+1) {
+			end++
 		}
-		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '_' {
-			return false
+		if end == i+1 {
+			result.WriteByte(ch)
+			continue
+		}
+		name := value[i+1 : end]
+		if resolved, ok := lookup(name); ok {
+			result.WriteString(resolved)
+		} else {
+			result.WriteString(value[i:end])
+			unresolved = append(unresolved, name)
 		}
+		i = end - 1
 	}
-	
-	return true
-}
\ No newline at end of file
+
+	return result.String(), unresolved
+}
+
+func isKeyChar(ch byte, first bool) bool {
+	if ch == '_' || (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') {
+		return true
+	}
+	return !first && ch >= '0' && ch <= '9'
+}
+
+func isValidKey(key string) bool {
+	return model.IsValidKey(key)
+}
@@ -10,11 +10,53 @@ import (
 	"time"
 )
 
+// preRestoreMarker prefixes the timestamp of safety backups made before a restore
+const preRestoreMarker = "pre-restore."
+
+// BackupRetention is the number of backups kept per file after each write (0 keeps all)
+var BackupRetention = 20
+
+// BackupPolicy controls when WriteFile backs up the file it is about to overwrite
+type BackupPolicy int
+
+const (
+	BackupEveryWrite     BackupPolicy = iota // Back up before every write (default)
+	BackupOncePerSession                     // Back up only before the first write of each file
+	BackupNever                              // Never back up automatically
+)
+
+var (
+	backupPolicy = BackupEveryWrite
+	backedUp     = make(map[string]bool) // Files backed up this session
+)
+
+// SetBackupPolicy sets when WriteFile creates backups. Backups made on demand
+// with CreateBackup are not affected.
+func SetBackupPolicy(policy BackupPolicy) {
+	backupPolicy = policy
+	backedUp = make(map[string]bool)
+}
+
+// ParseBackupPolicy parses a policy name: "always", "session" or "never"
+func ParseBackupPolicy(name string) (BackupPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "always":
+		return BackupEveryWrite, nil
+	case "session":
+		return BackupOncePerSession, nil
+	case "never":
+		return BackupNever, nil
+	}
+	return BackupEveryWrite, fmt.Errorf("unknown backup policy %q (expected always, session or never)", name)
+}
+
 // BackupInfo holds information about a backup file
 type BackupInfo struct {
-	Path      string
-	Timestamp time.Time
-	Size      int64
+	Path       string
+	Timestamp  time.Time
+	Size       int64
+	PreRestore bool   // Safety backup made before a restore; never pruned
+	Label      string // Optional label given when the backup was created
 }
 
 // ListBackups returns a list of backup files for the given env file
@@ -35,16 +77,18 @@ func ListBackups(path string) ([]BackupInfo, error) {
 			continue
 		}
 
-		// Parse timestamp from filename
-		timestamp, err := parseBackupTimestamp(match)
+		// Parse timestamp and label from filename
+		timestamp, label, err := parseBackupName(match)
 		if err != nil {
 			continue
 		}
 
 		backups = append(backups, BackupInfo{
-			Path:      match,
-			Timestamp: timestamp,
-			Size:      info.Size(),
+			Path:       match,
+			Timestamp:  timestamp,
+			Size:       info.Size(),
+			PreRestore: strings.Contains(filepath.Base(match), ".backup."+preRestoreMarker),
+			Label:      label,
 		})
 	}
 
@@ -56,16 +100,38 @@ func ListBackups(path string) ([]BackupInfo, error) {
 	return backups, nil
 }
 
-// parseBackupTimestamp extracts the timestamp from a backup filename
-func parseBackupTimestamp(path string) (time.Time, error) {
+// backupTimeFormat is the timestamp format used in backup filenames
+const backupTimeFormat = "20060102-150405"
+
+// parseBackupName extracts the timestamp and optional label from a backup
+// filename of the form <file>.backup.<timestamp>[.<label>]
+func parseBackupName(path string) (time.Time, string, error) {
 	base := filepath.Base(path)
 	parts := strings.Split(base, ".backup.")
 	if len(parts) != 2 {
-		return time.Time{}, fmt.Errorf("invalid backup filename format")
+		return time.Time{}, "", fmt.Errorf("invalid backup filename format")
 	}
 
-	timestamp := parts[1]
-	return time.Parse("20060102-150405", timestamp)
+	timestamp, label, _ := strings.Cut(strings.TrimPrefix(parts[1], preRestoreMarker), ".")
+	parsed, err := time.Parse(backupTimeFormat, timestamp)
+	return parsed, label, err
+}
+
+// SanitizeBackupLabel makes a label safe to use in a filename: runs of anything
+// other than letters, digits, - and _ become a single -
+func SanitizeBackupLabel(label string) string {
+	var sb strings.Builder
+	dash := false
+	for _, ch := range strings.TrimSpace(label) {
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-' {
+			sb.WriteRune(ch)
+			dash = false
+		} else if !dash {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.Trim(sb.String(), "-")
 }
 
 // RestoreBackup restores a backup file to the original env file
@@ -78,8 +144,8 @@ func RestoreBackup(backupPath, originalPath string) error {
 
 	// Create a backup of the current file first (just in case)
 	if _, err := os.Stat(originalPath); err == nil {
-		timestamp := time.Now().Format("20060102-150405")
-		safetyBackupPath := fmt.Sprintf("%s.backup.pre-restore.%s", originalPath, timestamp)
+		timestamp := time.Now().Format(backupTimeFormat)
+		safetyBackupPath := fmt.Sprintf("%s.backup.%s%s", originalPath, preRestoreMarker, timestamp)
 		if err := copyFile(originalPath, safetyBackupPath); err != nil {
 			return fmt.Errorf("failed to create safety backup: %w", err)
 		}
@@ -124,12 +190,67 @@ func copyFile(src, dst string) error {
 
 // CreateBackup creates a backup of the given file
 func CreateBackup(path string) error {
+	return CreateLabeledBackup(path, "")
+}
+
+// CreateLabeledBackup creates a backup of the given file with a label, e.g.
+// "before prod migration", shown in the backup list. The label is sanitized
+// for use in the filename (see SanitizeBackupLabel).
+func CreateLabeledBackup(path, label string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil // no file to backup
 	}
 
-	timestamp := time.Now().Format("20060102-150405")
-	backupPath := fmt.Sprintf("%s.backup.%s", path, timestamp)
+	backupPath := fmt.Sprintf("%s.backup.%s", path, time.Now().Format(backupTimeFormat))
+	if label = SanitizeBackupLabel(label); label != "" {
+		backupPath += "." + label
+	}
 
 	return copyFile(path, backupPath)
 }
+
+// PruneBackups deletes all but the newest keep backups of the given file.
+// Pre-restore safety backups and labeled backups are never pruned.
+func PruneBackups(path string, keep int) error {
+	backups, err := ListBackups(path)
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	for _, backup := range backups {
+		if backup.PreRestore || backup.Label != "" {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := DeleteBackup(backup.Path); err != nil {
+			return fmt.Errorf("failed to delete backup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PruneBackupsOlderThan deletes backups of the given file older than d.
+// Pre-restore safety backups and labeled backups are never pruned.
+func PruneBackupsOlderThan(path string, d time.Duration) error {
+	backups, err := ListBackups(path)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-d)
+	for _, backup := range backups {
+		if backup.PreRestore || backup.Label != "" || !backup.Timestamp.Before(cutoff) {
+			continue
+		}
+		if err := DeleteBackup(backup.Path); err != nil {
+			return fmt.Errorf("failed to delete backup: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeBackup(t *testing.T, path, suffix string) string {
+	t.Helper()
+	backupPath := path + ".backup." + suffix
+	if err := os.WriteFile(backupPath, []byte("KEY=value\n"), 0644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+	return backupPath
+}
+
+func TestPruneBackupsKeepsNewestAndPreRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	oldest := writeBackup(t, path, "20240101-100000")
+	middle := writeBackup(t, path, "20240102-100000")
+	newest := writeBackup(t, path, "20240103-100000")
+	safety := writeBackup(t, path, "pre-restore.20230101-100000")
+
+	if err := PruneBackups(path, 2); err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+
+	for _, kept := range []string{middle, newest, safety} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("expected %s to be kept", filepath.Base(kept))
+		}
+	}
+	if _, err := os.Stat(oldest); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be pruned", filepath.Base(oldest))
+	}
+}
+
+func TestPruneBackupsOlderThan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	old := writeBackup(t, path, time.Now().Add(-48*time.Hour).Format("20060102-150405"))
+	recent := writeBackup(t, path, time.Now().Add(-time.Hour).Format("20060102-150405"))
+
+	if err := PruneBackupsOlderThan(path, 24*time.Hour); err != nil {
+		t.Fatalf("PruneBackupsOlderThan() error = %v", err)
+	}
+
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("expected recent backup to be kept")
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expected old backup to be pruned")
+	}
+}
+
+func TestBackupPolicy(t *testing.T) {
+	defer SetBackupPolicy(BackupEveryWrite)
+
+	countBackups := func(path string) int {
+		backups, err := ListBackups(path)
+		if err != nil {
+			t.Fatalf("ListBackups() error = %v", err)
+		}
+		return len(backups)
+	}
+	write := func(path string) {
+		envFile, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if err := WriteFile(envFile); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("KEY=value\n"), 0644)
+
+	SetBackupPolicy(BackupNever)
+	write(path)
+	if n := countBackups(path); n != 0 {
+		t.Fatalf("expected no backups with BackupNever, got %d", n)
+	}
+
+	// Only the first write of the session is backed up
+	SetBackupPolicy(BackupOncePerSession)
+	write(path)
+	backups, _ := ListBackups(path)
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup after the first write, got %d", len(backups))
+	}
+	os.Remove(backups[0].Path)
+	write(path)
+	if n := countBackups(path); n != 0 {
+		t.Errorf("expected no backup on the second write of the session, got %d", n)
+	}
+
+	SetBackupPolicy(BackupEveryWrite)
+	write(path)
+	if n := countBackups(path); n != 1 {
+		t.Errorf("expected a backup on every write, got %d", n)
+	}
+
+	if _, err := ParseBackupPolicy("weekly"); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+}
+
+func TestLabeledBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("KEY=value\n"), 0644)
+
+	if got := SanitizeBackupLabel(" before prod/config  migration "); got != "before-prod-config-migration" {
+		t.Errorf("SanitizeBackupLabel() = %q", got)
+	}
+
+	if err := CreateLabeledBackup(path, "before prod/config"); err != nil {
+		t.Fatalf("CreateLabeledBackup() error = %v", err)
+	}
+	old := writeBackup(t, path, "20240101-100000")
+	backups, err := ListBackups(path)
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d (%v)", len(backups), err)
+	}
+	if backups[0].Label != "before-prod-config" || backups[1].Label != "" {
+		t.Errorf("unexpected labels %q and %q", backups[0].Label, backups[1].Label)
+	}
+	if filepath.Dir(backups[0].Path) != filepath.Dir(path) {
+		t.Errorf("the label must not change the backup directory: %s", backups[0].Path)
+	}
+
+	// Labeled backups survive pruning
+	if err := PruneBackups(path, 0); err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+	if _, err := os.Stat(backups[0].Path); err != nil {
+		t.Errorf("expected the labeled backup to be kept")
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expected the unlabeled backup to be pruned")
+	}
+}
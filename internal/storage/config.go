@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+// LoadCategoryRules reads custom category rules from a file of
+// "PREFIX = category [#color]" lines (see model.ParseCategoryRules)
+func LoadCategoryRules(path string) (model.CategoryRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read category rules: %w", err)
+	}
+	rules, err := model.ParseCategoryRules(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// LoadSchema reads a schema file of "KEY = type [required]" lines (see model.ParseSchema)
+func LoadSchema(path string) (model.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return model.Schema{}, fmt.Errorf("failed to read schema: %w", err)
+	}
+	schema, err := model.ParseSchema(string(data))
+	if err != nil {
+		return model.Schema{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// csvHeader is the header row of CSV exports
+var csvHeader = []string{"key", "value", "exported", "is_secret"}
+
+// csvFormulaChars start a formula when they lead a spreadsheet cell
+const csvFormulaChars = "=+-@\t\r"
+
+// escapeCSVFormula keeps a spreadsheet from running a value as a formula, e.g.
+// =HYPERLINK(...), by prefixing it with an apostrophe. Values that already start
+// with one get another, so unescapeCSVFormula can strip it again.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaChars+"'", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVFormula reverses escapeCSVFormula
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaChars+"'", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// exportToCSV converts ExportData to CSV with one row per entry. Quoting follows
+// RFC 4180, so values with commas, quotes or newlines survive a spreadsheet, and
+// values that would start a formula are escaped (see escapeCSVFormula).
+func exportToCSV(data ExportData) (string, error) {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.UseCRLF = true // RFC 4180 line endings
+
+	if err := w.Write(csvHeader); err != nil {
+		return "", err
+	}
+	for _, entry := range data.Entries {
+		row := []string{entry.Key, escapeCSVFormula(entry.Value), strconv.FormatBool(entry.Exported), strconv.FormatBool(entry.IsSecret)}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return sb.String(), w.Error()
+}
+
+// importFromCSV parses a CSV written by exportToCSV back into ExportData. The
+// header row is required; the exported and is_secret columns are optional.
+func importFromCSV(content string) (ExportData, error) {
+	var data ExportData
+	r := csv.NewReader(strings.NewReader(content))
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return data, err
+	}
+	if len(records) == 0 {
+		return data, fmt.Errorf("missing header row")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	keyCol, hasKey := columns["key"]
+	valueCol, hasValue := columns["value"]
+	if !hasKey || !hasValue {
+		return data, fmt.Errorf("header must contain key and value columns")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	flag := func(record []string, name string, line int) (bool, error) {
+		value := strings.TrimSpace(field(record, name))
+		if value == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("line %d: invalid %s value %q", line, name, value)
+		}
+		return b, nil
+	}
+
+	for i, record := range records[1:] {
+		line := i + 2
+		if keyCol >= len(record) || valueCol >= len(record) {
+			return data, fmt.Errorf("line %d: missing key or value", line)
+		}
+		entry := ExportEntry{Key: strings.TrimSpace(record[keyCol]), Value: unescapeCSVFormula(record[valueCol])}
+		if entry.Key == "" {
+			return data, fmt.Errorf("line %d: empty key", line)
+		}
+		if entry.Exported, err = flag(record, "exported", line); err != nil {
+			return data, err
+		}
+		if entry.IsSecret, err = flag(record, "is_secret", line); err != nil {
+			return data, err
+		}
+		data.Entries = append(data.Entries, entry)
+	}
+	data.Count = len(data.Entries)
+
+	return data, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/envtui/envtui/internal/model"
@@ -14,10 +15,38 @@ import (
 type ExportFormat string
 
 const (
-	FormatJSON ExportFormat = "json"
-	FormatYAML ExportFormat = "yaml"
+	FormatJSON     ExportFormat = "json"
+	FormatJSONFlat ExportFormat = "json-flat" // {"KEY": "value"}, the shape most JSON consumers expect
+	FormatYAML     ExportFormat = "yaml"
+	FormatDirenv   ExportFormat = "direnv"
+	FormatTemplate ExportFormat = "template"
+	FormatDotenv   ExportFormat = "dotenv"
+	FormatTOML     ExportFormat = "toml"
+	FormatCSV      ExportFormat = "csv"
 )
 
+// ExportOptions holds options shared by all export targets
+type ExportOptions struct {
+	RedactSecrets bool // Leave secret values out of the exported output
+	MaskSecrets   bool // Replace secret values with model.MaskedPlaceholder, in every format but the template
+	SortKeys      bool // Sort keys alphabetically instead of keeping file order (dotenv only)
+	ExportedOnly  bool // Only export keys marked with "export", in every format but the template
+
+	// DotenvFiles are loaded with dotenv_if_exists ahead of the exported values (direnv only)
+	DotenvFiles []string
+}
+
+// exportValue returns the value of entry as it should be exported
+func exportValue(entry *model.Entry, opts ExportOptions) string {
+	switch {
+	case opts.RedactSecrets && entry.IsSecret:
+		return ""
+	case opts.MaskSecrets && entry.IsSecret:
+		return model.MaskedPlaceholder
+	}
+	return entry.Value
+}
+
 // ExportEntry represents a single entry for export
 type ExportEntry struct {
 	Key      string `json:"key" yaml:"key"`
@@ -33,18 +62,32 @@ type ExportData struct {
 	Count   int           `json:"count" yaml:"count"`
 }
 
-// ExportToFile exports an EnvFile to JSON or YAML format
+// ExportToFile exports an EnvFile to JSON, flat JSON, YAML, TOML, CSV, direnv, template or dotenv format
 func ExportToFile(envFile *model.EnvFile, format ExportFormat, outputPath string) error {
+	return ExportToFileWithOptions(envFile, format, outputPath, ExportOptions{})
+}
+
+// ExportToFileWithOptions exports an EnvFile to the given format using the given options
+func ExportToFileWithOptions(envFile *model.EnvFile, format ExportFormat, outputPath string, opts ExportOptions) error {
+	switch format {
+	case FormatDirenv:
+		return WriteDirenv(envFile, outputPath, opts)
+	case FormatTemplate:
+		return ExportToTemplate(envFile, outputPath)
+	case FormatDotenv:
+		return writeOutput(outputPath, []byte(ExportToDotenv(envFile, opts)), 0600)
+	}
+
 	data := ExportData{
 		File:  envFile.Path,
 		Count: 0,
 	}
 
 	for _, entry := range envFile.Entries {
-		if entry.Type == model.KeyValueEntry {
+		if entry.Type == model.KeyValueEntry && (entry.Exported || !opts.ExportedOnly) {
 			data.Entries = append(data.Entries, ExportEntry{
 				Key:      entry.Key,
-				Value:    entry.Value,
+				Value:    exportValue(entry, opts),
 				Exported: entry.Exported,
 				IsSecret: entry.IsSecret,
 			})
@@ -58,8 +101,16 @@ func ExportToFile(envFile *model.EnvFile, format ExportFormat, outputPath string
 	switch format {
 	case FormatJSON:
 		content, err = json.MarshalIndent(data, "", "  ")
+	case FormatJSONFlat:
+		content, err = exportToFlatJSON(data)
 	case FormatYAML:
 		content = []byte(exportToYAML(data))
+	case FormatTOML:
+		content = []byte(exportToTOML(data))
+	case FormatCSV:
+		var csvContent string
+		csvContent, err = exportToCSV(data)
+		content = []byte(csvContent)
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
@@ -68,11 +119,97 @@ func ExportToFile(envFile *model.EnvFile, format ExportFormat, outputPath string
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
 
-	if err := os.WriteFile(outputPath, content, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	return writeOutput(outputPath, content, 0644)
+}
+
+// ExportEntries exports only the given entries, e.g. the ones left visible by a
+// search or the bulk-selected ones, in the given format
+func ExportEntries(entries []*model.Entry, format ExportFormat, outputPath string) error {
+	return ExportToFile(&model.EnvFile{Entries: entries}, format, outputPath)
+}
+
+// FormatForPath picks the export format from the output file's extension,
+// defaulting to dotenv
+func FormatForPath(path string) ExportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".csv":
+		return FormatCSV
+	}
+	if filepath.Base(path) == ".envrc" {
+		return FormatDirenv
 	}
+	return FormatDotenv
+}
 
-	return nil
+// ExportToTemplate writes an example env file (e.g. .env.example) with every key,
+// comment and blank line in order, but with secret values left empty
+func ExportToTemplate(envFile *model.EnvFile, outputPath string) error {
+	var sb strings.Builder
+	for _, entry := range envFile.Entries {
+		line := *entry
+		if line.Type == model.KeyValueEntry && line.IsSecret {
+			line.Value = ""
+		}
+		sb.WriteString(line.String() + "\n")
+	}
+
+	return writeOutput(outputPath, []byte(sb.String()), 0644)
+}
+
+// ExportToDotenv renders a normalized .env file: comments and blank lines are
+// dropped, duplicate keys collapse to their last value (as a shell would see it)
+// at the position of their first occurrence, and values are quoted consistently.
+func ExportToDotenv(envFile *model.EnvFile, opts ExportOptions) string {
+	var keys []string
+	latest := make(map[string]*model.Entry)
+	for _, entry := range envFile.Entries {
+		if entry.Type != model.KeyValueEntry {
+			continue
+		}
+		if _, seen := latest[entry.Key]; !seen {
+			keys = append(keys, entry.Key)
+		}
+		latest[entry.Key] = entry
+	}
+
+	if opts.SortKeys {
+		sort.Strings(keys)
+	}
+
+	var sb strings.Builder
+	for _, key := range keys {
+		entry := latest[key]
+		if opts.ExportedOnly && !entry.Exported {
+			continue
+		}
+		if entry.Exported {
+			sb.WriteString("export ")
+		}
+		sb.WriteString(key + "=" + quoteDotenvValue(exportValue(entry, opts)) + "\n")
+	}
+
+	return sb.String()
+}
+
+// quoteDotenvValue quotes a value the way envtui writes it to a file (see
+// model.FormatValue), also quoting characters that dotenv loaders and shells
+// treat specially. Values with references or escapes are single-quoted, so they
+// stay literal.
+func quoteDotenvValue(value string) string {
+	style := model.QuoteNone
+	switch {
+	case strings.ContainsAny(value, "$`\\"):
+		style = model.QuoteSingle
+	case strings.ContainsAny(value, ";&|<>()*?["):
+		style = model.QuoteDouble
+	}
+	return model.FormatValue(value, style)
 }
 
 // exportToYAML converts ExportData to YAML format manually
@@ -96,7 +233,7 @@ func exportToYAML(data ExportData) string {
 	return sb.String()
 }
 
-// ImportFromFile imports entries from a JSON file
+// ImportFromFile imports entries from a JSON (structured or flat), TOML or CSV file
 func ImportFromFile(inputPath string) (*model.EnvFile, error) {
 	content, err := os.ReadFile(inputPath)
 	if err != nil {
@@ -108,17 +245,27 @@ func ImportFromFile(inputPath string) (*model.EnvFile, error) {
 
 	switch ext {
 	case ".json":
-		err = json.Unmarshal(content, &data)
+		data, err = importFromJSON(content)
 	case ".yaml", ".yml":
 		return nil, fmt.Errorf("YAML import not yet implemented - please use JSON format")
+	case ".toml":
+		data, err = importFromTOML(string(content))
+	case ".csv":
+		data, err = importFromCSV(string(content))
 	default:
 		// Try JSON format
-		err = json.Unmarshal(content, &data)
+		data, err = importFromJSON(content)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file: %w", err)
 	}
+	for _, expEntry := range data.Entries {
+		if expEntry.Value == model.MaskedPlaceholder {
+			// Importing the placeholder would overwrite the real secret with it
+			return nil, fmt.Errorf("%s holds a masked secret; import an export made without --mask-secrets", expEntry.Key)
+		}
+	}
 
 	// Create EnvFile from imported data
 	envFile := &model.EnvFile{
@@ -167,3 +314,127 @@ func MergeImport(envFile *model.EnvFile, imported *model.EnvFile, overwrite bool
 
 	return nil
 }
+
+// PreviewMergeImport reports what MergeImport would do without changing envFile.
+// There is one diff per imported key, in import order: OnlyInOther marks a key
+// that would be added, Different a value that would be overwritten, and neither
+// a key that would be left as is (its value matches, or overwrite is off).
+func PreviewMergeImport(envFile *model.EnvFile, imported *model.EnvFile, overwrite bool) []model.FileDiff {
+	var diffs []model.FileDiff
+	index := make(map[string]int) // Key -> position in diffs
+	for _, importedEntry := range imported.Entries {
+		if importedEntry.Type != model.KeyValueEntry {
+			continue
+		}
+
+		i, seen := index[importedEntry.Key]
+		if !seen {
+			diff := model.FileDiff{Key: importedEntry.Key, OnlyInOther: true}
+			if existing := envFile.GetEntry(importedEntry.Key); existing != nil {
+				diff = model.FileDiff{Key: importedEntry.Key, CurrentValue: existing.Value}
+			}
+			i = len(diffs)
+			index[importedEntry.Key] = i
+			diffs = append(diffs, diff)
+		} else if !overwrite {
+			// Like MergeImport, a repeated key only replaces the first one when overwriting
+			continue
+		}
+
+		diff := &diffs[i]
+		diff.OtherValue = importedEntry.Value
+		if !diff.OnlyInOther {
+			diff.Different = overwrite && diff.OtherValue != diff.CurrentValue
+		}
+	}
+	return diffs
+}
+
+// SummarizeMergePreview counts the keys a PreviewMergeImport result would add,
+// overwrite and leave unchanged, e.g. "12 new, 3 overwritten, 5 unchanged"
+func SummarizeMergePreview(diffs []model.FileDiff) string {
+	var added, overwritten, unchanged int
+	for _, diff := range diffs {
+		switch {
+		case diff.OnlyInOther:
+			added++
+		case diff.Different:
+			overwritten++
+		default:
+			unchanged++
+		}
+	}
+	return fmt.Sprintf("%d new, %d overwritten, %d unchanged", added, overwritten, unchanged)
+}
+
+// MergeChoice decides how MergeResolve treats a single key
+type MergeChoice int
+
+const (
+	MergeSkip       MergeChoice = iota // Leave the key untouched (the default for unresolved keys)
+	MergeTakeSource                    // Use the source value; removes the key if the source lacks it
+	MergeKeepTarget                    // Keep the target value as is
+)
+
+// MergeResolve applies per-key decisions from source onto target. Unlike MergeImport
+// it only touches the keys listed in resolution, and fails without modifying target
+// if a resolved key exists in neither file.
+func MergeResolve(target *model.EnvFile, source *model.EnvFile, resolution map[string]MergeChoice) error {
+	keys := mergeOrder(target, source, resolution)
+	for _, key := range keys {
+		if resolution[key] != MergeSkip && target.GetEntry(key) == nil && source.GetEntry(key) == nil {
+			return fmt.Errorf("cannot resolve %s: key not found in either file", key)
+		}
+	}
+
+	for _, key := range keys {
+		if resolution[key] != MergeTakeSource {
+			continue
+		}
+
+		sourceEntry := source.GetEntry(key)
+		existing := target.GetEntry(key)
+		switch {
+		case sourceEntry == nil:
+			target.DeleteEntry(key)
+		case existing == nil:
+			target.AddEntry(&model.Entry{
+				Type:     model.KeyValueEntry,
+				Key:      sourceEntry.Key,
+				Value:    sourceEntry.Value,
+				Exported: sourceEntry.Exported,
+				IsSecret: sourceEntry.IsSecret,
+			})
+		default:
+			existing.Value = sourceEntry.Value
+			existing.Exported = sourceEntry.Exported
+		}
+	}
+
+	return nil
+}
+
+// mergeOrder returns the keys of resolution in a fixed order, so added keys are
+// saved in the order the source has them: source keys first, then keys only in
+// the target, then keys in neither file, sorted
+func mergeOrder(target *model.EnvFile, source *model.EnvFile, resolution map[string]MergeChoice) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, file := range []*model.EnvFile{source, target} {
+		for _, entry := range file.Entries {
+			if _, ok := resolution[entry.Key]; ok && entry.Type == model.KeyValueEntry && !seen[entry.Key] {
+				seen[entry.Key] = true
+				keys = append(keys, entry.Key)
+			}
+		}
+	}
+
+	var missing []string
+	for key := range resolution {
+		if !seen[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return append(keys, missing...)
+}
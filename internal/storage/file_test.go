@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+func TestWriteFileRoundTripsComments(t *testing.T) {
+	original, err := os.ReadFile(filepath.Join("testdata", "comments.env"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, original, 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	envFile, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+
+	want := strings.TrimSuffix(string(original), "\n")
+	got := strings.TrimSuffix(string(written), "\n")
+	if got != want {
+		t.Errorf("round trip changed the file\n--- want ---\n%s\n--- got ---\n%s", want, got)
+	}
+}
+
+func TestLeadingCommentsMoveWithEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# Database host\nDB_HOST=localhost\n\n# Listen port\n# (default 8080)\nPORT=8080\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	envFile, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if err := envFile.RenameEntry("PORT", "APP_PORT"); err != nil {
+		t.Fatalf("RenameEntry() error = %v", err)
+	}
+	envFile.SortEntries()
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	written, _ := os.ReadFile(path)
+	want := "# Listen port\n# (default 8080)\nAPP_PORT=8080\n# Database host\nDB_HOST=localhost\n"
+	if string(written) != want {
+		t.Errorf("sorted file = %q, want %q", written, want)
+	}
+
+	envFile.DeleteEntry("DB_HOST")
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	written, _ = os.ReadFile(path)
+	want = "# Listen port\n# (default 8080)\nAPP_PORT=8080\n"
+	if string(written) != want {
+		t.Errorf("deleting a key should delete its comment, got %q", written)
+	}
+}
+
+func TestWriteFileRoundTripsMultilineValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	values := map[string]string{
+		"PEM":    "-----BEGIN KEY-----\nabc\\def\n-----END KEY-----",
+		"JSON":   `{"name": "app", "tags": ["a#b"]}`,
+		"PADDED": "  spaced  ",
+		"SIMPLE": "plain",
+		"TABBED": "a\tb",
+		"CRLF":   "line1\r\nline2",
+		"QUOTED": `"already quoted"`,
+		"SPACED": "hello big world",
+		"INNER":  `say "hi" it's fine`,
+	}
+
+	envFile := &model.EnvFile{Path: path}
+	for key, value := range values {
+		envFile.AddEntry(&model.Entry{Type: model.KeyValueEntry, Key: key, Value: value})
+	}
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	reread, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for key, want := range values {
+		entry := reread.GetEntry(key)
+		if entry == nil {
+			t.Fatalf("missing entry %s after round trip", key)
+		}
+		if entry.Value != want {
+			t.Errorf("%s = %q, want %q", key, entry.Value, want)
+		}
+	}
+}
+
+func TestWriteFileDetectsExternalChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	envFile, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	// Our own saves keep the hash current, so consecutive saves succeed
+	envFile.UpdateEntry("A", "2")
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("first save failed: %v", err)
+	}
+	envFile.UpdateEntry("A", "3")
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("second save failed: %v", err)
+	}
+
+	// Another program edits the file
+	if err := os.WriteFile(path, []byte("A=3\nB=external\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	envFile.UpdateEntry("A", "4")
+	if err := WriteFile(envFile); !errors.Is(err, ErrExternalChange) {
+		t.Fatalf("expected ErrExternalChange, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "A=3\nB=external\n" {
+		t.Errorf("external edits must not be overwritten, got:\n%s", content)
+	}
+
+	if err := ForceWriteFile(envFile); err != nil {
+		t.Fatalf("forced save failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "A=4\n" {
+		t.Errorf("forced save should overwrite, got:\n%s", content)
+	}
+}
+
+func TestStdinInputAndStdoutExport(t *testing.T) {
+	oldStdin, oldStdout := stdin, stdout
+	defer func() { stdin, stdout = oldStdin, oldStdout }()
+
+	var out strings.Builder
+	stdin = strings.NewReader("A=1\nexport B=two\n")
+	stdout = &out
+
+	envFile, err := ReadFile(StdinPath)
+	if err != nil {
+		t.Fatalf("read from stdin failed: %v", err)
+	}
+	if len(envFile.FilterEntries("")) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(envFile.FilterEntries("")))
+	}
+
+	envFile.UpdateEntry("A", "changed")
+	if err := WriteFile(envFile); !errors.Is(err, ErrStdinReadOnly) {
+		t.Fatalf("expected ErrStdinReadOnly, got %v", err)
+	}
+	if err := ForceWriteFile(envFile); !errors.Is(err, ErrStdinReadOnly) {
+		t.Fatalf("expected ErrStdinReadOnly from forced save, got %v", err)
+	}
+
+	if err := ExportToFile(envFile, FormatDotenv, StdinPath); err != nil {
+		t.Fatalf("export to stdout failed: %v", err)
+	}
+	if out.String() != "A=changed\nexport B=two\n" {
+		t.Errorf("unexpected stdout export:\n%s", out.String())
+	}
+}
+
+func TestEntryStringQuotesValues(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "K=plain"},
+		{"hello world", `K="hello world"`},
+		{"a#b", `K="a#b"`},
+		{`say "hi"`, `K="say \"hi\""`},
+		{"it's", `K="it's"`},
+		{"line1\nline2\tend", `K="line1\nline2\tend"`},
+	}
+	for _, tt := range tests {
+		entry := &model.Entry{Type: model.KeyValueEntry, Key: "K", Value: tt.value}
+		if got := entry.String(); got != tt.want {
+			t.Errorf("String() for %q = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteStyleRoundTrip(t *testing.T) {
+	original := "PLAIN=value\nPRICE='$5 flat'\nGREETING=\"hello\"\nEMPTY=''\nexport NAME=\"x\" # quoted\n"
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	envFile, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := envFile.GetEntry("PRICE").QuoteStyle; got != model.QuoteSingle {
+		t.Errorf("PRICE quote style = %s, want single", got)
+	}
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != original {
+		t.Errorf("round trip changed the quotes:\ngot  %q\nwant %q", content, original)
+	}
+
+	// A value that cannot be single-quoted falls back to double quotes
+	envFile.GetEntry("PRICE").Value = "it's $5"
+	if got := envFile.GetEntry("PRICE").String(); got != `PRICE="it's $5"` {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestCRLFLineEndingsRoundTrip(t *testing.T) {
+	original := "# Database\r\nDB_HOST=localhost\r\nCERT=\"line one\r\nline two\"\r\n\r\nexport PORT=8080 # http\r\n"
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	envFile, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if envFile.LineEnding != "\r\n" {
+		t.Errorf("LineEnding = %q, want CRLF", envFile.LineEnding)
+	}
+	for _, entry := range envFile.Entries {
+		if strings.Contains(entry.Value+entry.Comment+strings.Join(entry.LeadingComments, ""), "\r") {
+			t.Errorf("entry %s kept a carriage return: %q", entry.Key, entry.String())
+		}
+	}
+	if got := envFile.GetEntry("CERT").Value; got != "line one\nline two" {
+		t.Errorf("CERT = %q", got)
+	}
+
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	// Multiline values are written with \n escapes, as for LF files
+	want := strings.Replace(original, "line one\r\nline two", `line one\nline two`, 1)
+	if content, _ := os.ReadFile(path); string(content) != want {
+		t.Errorf("round trip changed the file:\ngot  %q\nwant %q", content, want)
+	}
+
+	envFile.AddEntry(&model.Entry{Type: model.KeyValueEntry, Key: "DEBUG", Value: "true"})
+	if err := WriteFile(envFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != want+"DEBUG=true\r\n" {
+		t.Errorf("new entries should use CRLF too, got %q", content)
+	}
+}
+
+func TestWriteFileKeepsTheEndOfTheFile(t *testing.T) {
+	tests := []struct {
+		name, original, withEntry string
+	}{
+		{"final newline", "A=1\n", "A=1\nB=2\n"},
+		{"no final newline", "A=1", "A=1\nB=2"},
+		{"trailing blank line", "A=1\n\n", "A=1\nB=2\n\n"},
+		{"trailing blank lines after a comment", "# c\nA=1\n\n\n", "# c\nA=1\nB=2\n\n\n"},
+		{"crlf without final newline", "A=1\r\n\r\nC=3", "A=1\r\n\r\nC=3\r\nB=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			os.WriteFile(path, []byte(tt.original), 0644)
+
+			envFile, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if err := WriteFile(envFile); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			if content, _ := os.ReadFile(path); string(content) != tt.original {
+				t.Errorf("round trip = %q, want %q", content, tt.original)
+			}
+
+			envFile.AddEntry(&model.Entry{Type: model.KeyValueEntry, Key: "B", Value: "2"})
+			if err := WriteFile(envFile); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			if content, _ := os.ReadFile(path); string(content) != tt.withEntry {
+				t.Errorf("after adding B = %q, want %q", content, tt.withEntry)
+			}
+		})
+	}
+}
+
+func TestReadFileRejectsNonEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content []byte
+		want    error
+	}{
+		{"binary", []byte("ELF\x00\x01\x02KEY=value\n"), ErrBinaryFile},
+		{"json", []byte("{\n  \"name\": \"app\"\n}\n"), ErrNotEnvFile},
+		{"huge", append([]byte("KEY="), make([]byte, MaxFileSize)...), ErrFileTooLarge},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		os.WriteFile(path, tt.content, 0644)
+		if _, err := ReadFile(path); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ReadFile() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Files with only comments, or partly invalid lines, still open
+	for name, content := range map[string]string{
+		"comments": "# filled in later\n\n",
+		"partial":  "not an entry\nKEY=value\n",
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := ReadFile(path); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -88,6 +89,100 @@ func GetGitStatus(path string) GitStatus {
 	return GitStatusClean
 }
 
+// IsGitIgnored returns true if the path matches a .gitignore rule. Tracked files are
+// checked against the rules too, so a committed file that is also ignored counts.
+func IsGitIgnored(path string) bool {
+	cmd := exec.Command("git", "check-ignore", "-q", "--no-index", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	return cmd.Run() == nil
+}
+
+// AddToGitignore appends the file, anchored to the repository root, to the
+// repository's top-level .gitignore (creating it if needed)
+func AddToGitignore(path string) error {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = filepath.Dir(path)
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("%s is not in a git repository", filepath.Base(path))
+	}
+	root := strings.TrimSpace(string(output))
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	// Resolve symlinks so the path lines up with git's (e.g. /tmp on macOS)
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	rule := "/" + filepath.ToSlash(rel)
+
+	gitignorePath := filepath.Join(root, ".gitignore")
+	existing, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == rule {
+			return nil
+		}
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += rule + "\n"
+	if err := os.WriteFile(gitignorePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+	return nil
+}
+
+// GitCommitFile stages and commits only the given file with the given message.
+// Both the add and the commit are path-scoped, so other staged or modified files
+// are never included.
+func GitCommitFile(path, message string) error {
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("commit message is empty")
+	}
+	if path == StdinPath {
+		return ErrStdinReadOnly
+	}
+	if !IsGitRepository(path) {
+		return fmt.Errorf("%s is not in a git repository", filepath.Base(path))
+	}
+
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+
+	cmd := exec.Command("git", "add", "--", base)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git add failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	// Nothing staged for this path means there is nothing to commit
+	cmd = exec.Command("git", "diff", "--cached", "--quiet", "--", base)
+	cmd.Dir = dir
+	if err := cmd.Run(); err == nil {
+		return fmt.Errorf("nothing to commit: %s has no changes", base)
+	}
+
+	cmd = exec.Command("git", "commit", "-m", message, "--", base)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
 // GetGitStatusIcon returns an icon representing the git status
 func GetGitStatusIcon(status GitStatus) string {
 	switch status {
@@ -122,18 +217,23 @@ func GetGitBranch(path string) string {
 
 // FileGitInfo holds git information for a file
 type FileGitInfo struct {
-	Status GitStatus
-	Branch string
-	Icon   string
+	Status  GitStatus
+	Branch  string
+	Icon    string
+	Ignored bool // Matches a .gitignore rule (only checked inside a repository)
 }
 
 // GetFileGitInfo returns complete git information for a file
 func GetFileGitInfo(path string) FileGitInfo {
+	if path == StdinPath {
+		return FileGitInfo{}
+	}
 	status := GetGitStatus(path)
 	return FileGitInfo{
-		Status: status,
-		Branch: GetGitBranch(path),
-		Icon:   GetGitStatusIcon(status),
+		Status:  status,
+		Branch:  GetGitBranch(path),
+		Icon:    GetGitStatusIcon(status),
+		Ignored: status != GitStatusNone && IsGitIgnored(path),
 	}
 }
 
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initGitRepo creates a git repository in a temp dir with a committer identity
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "envtui")
+	t.Setenv("GIT_AUTHOR_EMAIL", "envtui@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "envtui")
+	t.Setenv("GIT_COMMITTER_EMAIL", "envtui@example.com")
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+func TestGitCommitFileIsPathScoped(t *testing.T) {
+	dir := initGitRepo(t)
+	envPath := filepath.Join(dir, ".env")
+	otherPath := filepath.Join(dir, "other.txt")
+	os.WriteFile(envPath, []byte("A=1\n"), 0644)
+	os.WriteFile(otherPath, []byte("unrelated\n"), 0644)
+	runGit(t, dir, "add", "other.txt")
+
+	if err := GitCommitFile(envPath, "Update env"); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	committed := runGit(t, dir, "show", "--name-only", "--format=%s", "HEAD")
+	if !strings.Contains(committed, "Update env") || !strings.Contains(committed, ".env") {
+		t.Errorf("expected .env to be committed with the message, got:\n%s", committed)
+	}
+	if strings.Contains(committed, "other.txt") {
+		t.Errorf("unrelated staged files must not be committed, got:\n%s", committed)
+	}
+
+	if err := GitCommitFile(envPath, "Again"); err == nil || !strings.Contains(err.Error(), "nothing to commit") {
+		t.Errorf("expected a nothing-to-commit error, got %v", err)
+	}
+}
+
+func TestGitCommitFileOutsideRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	envPath := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(envPath, []byte("A=1\n"), 0644)
+
+	if err := GitCommitFile(envPath, "msg"); err == nil || !strings.Contains(err.Error(), "not in a git repository") {
+		t.Errorf("expected a not-a-repository error, got %v", err)
+	}
+}
+
+func TestAddToGitignore(t *testing.T) {
+	dir := initGitRepo(t)
+	sub := filepath.Join(dir, "config")
+	os.MkdirAll(sub, 0755)
+	envPath := filepath.Join(sub, ".env")
+	os.WriteFile(envPath, []byte("API_KEY=abc\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules"), 0644)
+
+	if IsGitIgnored(envPath) {
+		t.Fatalf(".env should not be ignored yet")
+	}
+	if err := AddToGitignore(envPath); err != nil {
+		t.Fatalf("AddToGitignore failed: %v", err)
+	}
+	if !IsGitIgnored(envPath) {
+		t.Errorf(".env should be ignored after adding it")
+	}
+
+	// Adding twice does not duplicate the rule
+	if err := AddToGitignore(envPath); err != nil {
+		t.Fatalf("second AddToGitignore failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if string(content) != "node_modules\n/config/.env\n" {
+		t.Errorf("unexpected .gitignore:\n%s", content)
+	}
+
+	if info := GetFileGitInfo(envPath); !info.Ignored {
+		t.Errorf("GetFileGitInfo should report the file as ignored")
+	}
+}
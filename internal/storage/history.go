@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+// redactedValue replaces secret values in the history log
+const redactedValue = "[redacted]"
+
+// HistoryRecord is a single persisted change in the history log
+type HistoryRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Key       string    `json:"key"`
+	OldValue  string    `json:"old_value,omitempty"`
+	NewValue  string    `json:"new_value,omitempty"`
+}
+
+// HistoryPath returns the sidecar history log path for an env file
+func HistoryPath(path string) string {
+	return path + ".envtui-history"
+}
+
+// NewHistoryRecord builds a history record from a change, redacting secret values
+// and values that look like secrets
+func NewHistoryRecord(change model.Change) HistoryRecord {
+	record := HistoryRecord{
+		Timestamp: change.Timestamp,
+		Type:      change.Type.String(),
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	if change.Entry == nil {
+		return record
+	}
+
+	record.Key = change.Entry.Key
+	switch change.Type {
+	case model.ChangeTypeRename:
+		// Key names are not secret, so renames are never redacted
+		record.OldValue = change.OldValue
+		record.NewValue = change.Entry.Key
+		return record
+	case model.ChangeTypeExport:
+		// Only the export flag changed, so no value is recorded
+		record.OldValue = strconv.FormatBool(!change.Entry.Exported)
+		record.NewValue = strconv.FormatBool(change.Entry.Exported)
+		return record
+	case model.ChangeTypeAdd:
+		record.NewValue = change.Entry.Value
+	case model.ChangeTypeUpdate:
+		record.OldValue = change.OldValue
+		record.NewValue = change.Entry.Value
+	case model.ChangeTypeDelete:
+		record.OldValue = change.Entry.Value
+	}
+
+	// A value that looks like a token is redacted even under an ordinary key
+	redact := func(value string) string {
+		if value != "" && (change.Entry.IsSecret || model.DetectSecretValue(value)) {
+			return redactedValue
+		}
+		return value
+	}
+	record.OldValue = redact(record.OldValue)
+	record.NewValue = redact(record.NewValue)
+
+	return record
+}
+
+// AppendHistory appends a change to the history log of the given env file
+func AppendHistory(path string, change model.Change) error {
+	line, err := json.Marshal(NewHistoryRecord(change))
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	f, err := os.OpenFile(HistoryPath(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history log: %w", err)
+	}
+
+	return nil
+}
+
+// LoadHistory reads the history log of the given env file (oldest first)
+func LoadHistory(path string) ([]HistoryRecord, error) {
+	f, err := os.Open(HistoryPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // no history yet
+		}
+		return nil, fmt.Errorf("failed to open history log: %w", err)
+	}
+	defer f.Close()
+
+	var records []HistoryRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // skip corrupt lines
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history log: %w", err)
+	}
+
+	return records, nil
+}
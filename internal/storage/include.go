@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+// LoadIncludes reads the files named by the include directives of envFile (see
+// model.EnvFile.Includes), and the files they include in turn, into
+// envFile.Layers. A later directive overrides an earlier one, and a file
+// overrides what it includes. Paths are relative to the including file.
+//
+// A file that cannot be read, or that includes itself through a cycle, is left
+// out; the first such problem is returned once every other file is loaded.
+func LoadIncludes(envFile *model.EnvFile) error {
+	layers, err := loadLayers(envFile, []string{includeKey(envFile.Path)})
+	envFile.Layers = dedupeLayers(layers)
+	return err
+}
+
+// loadLayers returns the files envFile includes, highest priority first. chain
+// holds the files being loaded, outermost first, to detect cycles.
+func loadLayers(envFile *model.EnvFile, chain []string) ([]*model.EnvFile, error) {
+	dir := "."
+	if envFile.Path != StdinPath {
+		dir = filepath.Dir(envFile.Path)
+	}
+
+	var layers []*model.EnvFile
+	var firstErr error
+	includes := envFile.Includes()
+	for i := len(includes) - 1; i >= 0; i-- {
+		include := includes[i]
+		path := include.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		err := func() error {
+			for n, loading := range chain {
+				if loading == includeKey(path) {
+					cycle := append(append([]string(nil), chain[n:]...), includeKey(path))
+					for j := range cycle {
+						cycle[j] = filepath.Base(cycle[j])
+					}
+					return fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+				}
+			}
+			included, err := ReadFile(path)
+			if err != nil {
+				return err
+			}
+			included.Path = path
+			included.ReadOnly = true
+			nested, err := loadLayers(included, append(chain, includeKey(path)))
+			layers = append(layers, included)
+			layers = append(layers, nested...)
+			return err
+		}()
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s line %d: cannot include %s: %w", filepath.Base(envFile.Path), include.Line, include.Path, err)
+		}
+	}
+	return layers, firstErr
+}
+
+// dedupeLayers keeps the highest-priority copy of a file included more than once
+func dedupeLayers(layers []*model.EnvFile) []*model.EnvFile {
+	seen := make(map[string]bool)
+	var unique []*model.EnvFile
+	for _, layer := range layers {
+		if key := includeKey(layer.Path); !seen[key] {
+			seen[key] = true
+			unique = append(unique, layer)
+		}
+	}
+	return unique
+}
+
+// includeKey identifies a file however its path is written
+func includeKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
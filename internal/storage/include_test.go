@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadIncludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"common.env": "LOG_LEVEL=info\nREGION=eu\nTIMEOUT=30\n",
+		"base.env":   "# envtui:include common.env\nLOG_LEVEL=warn\nPORT=8080\n",
+		"ci.env":     "PORT=9000\n",
+		".env":       "# envtui:include base.env\n# envtui:include ci.env\nDEBUG=true\n# Local overrides\n# envtui:include shared/missing.env\nREGION=us\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	envFile, err := ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	err = LoadIncludes(envFile)
+	if err == nil || !strings.Contains(err.Error(), ".env line 5: cannot include shared/missing.env") {
+		t.Errorf("expected the missing include to be reported with its line, got %v", err)
+	}
+
+	// The later directive wins, and a file wins over what it includes
+	inherited, sources := envFile.InheritedEntries()
+	got := make(map[string]string)
+	for _, entry := range inherited {
+		got[entry.Key] = entry.Value + " from " + filepath.Base(sources[entry])
+	}
+	want := map[string]string{
+		"PORT":      "9000 from ci.env",
+		"LOG_LEVEL": "warn from base.env",
+		"TIMEOUT":   "30 from common.env",
+	}
+	if len(got) != len(want) {
+		t.Errorf("inherited %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+	if _, ok := got["REGION"]; ok {
+		t.Error("REGION is set by the file itself and should not be inherited")
+	}
+	if !strings.Contains(envFile.String(), "REGION=us\n") || strings.Contains(envFile.String(), "PORT") {
+		t.Errorf("included entries must not be saved with the file:\n%s", envFile.String())
+	}
+}
+
+func TestLoadIncludesDetectsCycles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.env"), []byte("# envtui:include b.env\nA=1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.env"), []byte("# envtui:include a.env\nB=2\n"), 0644)
+
+	envFile, err := ReadFile(filepath.Join(dir, "a.env"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	err = LoadIncludes(envFile)
+	if err == nil || !strings.Contains(err.Error(), "include cycle: a.env -> b.env -> a.env") {
+		t.Fatalf("expected an include cycle, got %v", err)
+	}
+	if len(envFile.Layers) != 1 || filepath.Base(envFile.Layers[0].Path) != "b.env" {
+		t.Errorf("expected b.env to still be included once, got %d layers", len(envFile.Layers))
+	}
+}
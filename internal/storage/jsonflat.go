@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+// exportToFlatJSON converts ExportData to a flat {"KEY": "value"} object in file
+// order. A repeated key keeps its last value, at the position of its first
+// occurrence, as a shell would see it.
+func exportToFlatJSON(data ExportData) ([]byte, error) {
+	var keys []string
+	values := make(map[string]string)
+	for _, entry := range data.Entries {
+		if _, seen := values[entry.Key]; !seen {
+			keys = append(keys, entry.Key)
+		}
+		values[entry.Key] = entry.Value
+	}
+	if len(keys) == 0 {
+		return []byte("{}\n"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for i, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(values[key])
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString("  " + string(name) + ": " + string(value))
+		if i < len(keys)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+	return []byte(sb.String()), nil
+}
+
+// importFromFlatJSON parses a flat object of string values back into ExportData,
+// keeping the order of its keys. Secrets are recognized from the key names.
+func importFromFlatJSON(content []byte) (ExportData, error) {
+	var data ExportData
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	if tok, err := dec.Token(); err != nil {
+		return data, err
+	} else if tok != json.Delim('{') {
+		return data, fmt.Errorf("expected a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return data, err
+		}
+		key := tok.(string) // Object keys are always strings
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return data, fmt.Errorf("value of %s is not a string", key)
+		}
+		data.Entries = append(data.Entries, ExportEntry{Key: key, Value: value, IsSecret: model.IsSecretKey(key)})
+	}
+	if _, err := dec.Token(); err != nil {
+		return data, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return data, fmt.Errorf("unexpected content after the JSON object")
+	}
+	data.Count = len(data.Entries)
+
+	return data, nil
+}
+
+// importFromJSON parses either JSON shape: the structured ExportData written by
+// FormatJSON, recognized by its "entries" list, or a flat object of string values
+func importFromJSON(content []byte) (ExportData, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return ExportData{}, err
+	}
+	if entries, ok := fields["entries"]; !ok || !bytes.HasPrefix(bytes.TrimSpace(entries), []byte("[")) {
+		return importFromFlatJSON(content)
+	}
+	var data ExportData
+	err := json.Unmarshal(content, &data)
+	return data, err
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+// MetadataPath returns the sidecar metadata path for an env file. The env file
+// format has no room for when a key was changed, so that is kept here.
+func MetadataPath(path string) string {
+	return path + ".envtui-meta"
+}
+
+// metadata is the JSON layout of the sidecar metadata file
+type metadata struct {
+	LastModified map[string]time.Time `json:"last_modified"`
+}
+
+// LoadLastModified reads when each key of the given env file was last changed.
+// A missing metadata file yields no timestamps and no error.
+func LoadLastModified(path string) (map[string]time.Time, error) {
+	content, err := os.ReadFile(MetadataPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var meta metadata
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return meta.LastModified, nil
+}
+
+// SaveLastModified writes the file's modification times to its sidecar
+// metadata file. Keys no longer in the file are dropped.
+func SaveLastModified(envFile *model.EnvFile) error {
+	if envFile.Path == StdinPath {
+		return nil
+	}
+
+	meta := metadata{LastModified: make(map[string]time.Time)}
+	for key, at := range envFile.LastModified {
+		if envFile.GetEntry(key) != nil {
+			meta.LastModified[key] = at
+		}
+	}
+	content, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.WriteFile(MetadataPath(envFile.Path), append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExpandPath turns a command line argument into the env files it names. A
+// directory yields the *.env and .env* files directly inside it, and a glob
+// pattern such as "config/*.env" yields its matches. Backups, history logs and
+// temporary save files are skipped. Any other path, including StdinPath, is
+// returned as is so reading it reports a missing file.
+func ExpandPath(path string) ([]string, error) {
+	if path == StdinPath {
+		return []string{path}, nil
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		var paths []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.Type().IsRegular() && isEnvFileName(name) && !isSidecarFile(name) {
+				paths = append(paths, filepath.Join(path, name))
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no env files in %s", path)
+		}
+		return paths, nil
+	}
+
+	if !strings.ContainsAny(path, "*?[") {
+		return []string{path}, nil
+	}
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", path, err)
+	}
+	var paths []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || info.IsDir() || isSidecarFile(filepath.Base(match)) {
+			continue
+		}
+		paths = append(paths, match)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match %s", path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// ExpandPaths expands every argument (see ExpandPath), dropping repeated files.
+// Arguments that fail to expand are skipped and the first failure is returned
+// along with the files that were found.
+func ExpandPaths(args []string) ([]string, error) {
+	var paths []string
+	var firstErr error
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		expanded, err := ExpandPath(arg)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, path := range expanded {
+			if !seen[filepath.Clean(path)] {
+				seen[filepath.Clean(path)] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, firstErr
+}
+
+// isEnvFileName reports whether a file in a scanned directory looks like an env file
+func isEnvFileName(name string) bool {
+	return strings.HasPrefix(name, ".env") || strings.HasSuffix(name, ".env")
+}
+
+// isSidecarFile reports whether name is a file envtui writes next to an env file
+func isSidecarFile(name string) bool {
+	return strings.Contains(name, ".backup.") ||
+		strings.HasSuffix(name, HistoryPath("")) ||
+		strings.HasSuffix(name, MetadataPath("")) ||
+		strings.HasSuffix(name, ".tmp")
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".env", ".env.local", "api.env", ".env.backup.20260101-120000", ".env.envtui-history", ".env.envtui-meta", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.env"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ExpandPaths([]string{dir, filepath.Join(dir, "*.env"), filepath.Join(dir, ".env")})
+	if err != nil {
+		t.Fatalf("ExpandPaths() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, ".env"), filepath.Join(dir, ".env.local"), filepath.Join(dir, "api.env")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("ExpandPaths() = %v, want %v", paths, want)
+	}
+
+	// A pattern matching nothing is reported, but other arguments still load
+	paths, err = ExpandPaths([]string{filepath.Join(dir, "*.yaml"), filepath.Join(dir, "missing.env"), StdinPath})
+	if err == nil {
+		t.Error("expected an error for a pattern matching nothing")
+	}
+	if want := []string{filepath.Join(dir, "missing.env"), StdinPath}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ExpandPaths() = %v, want %v", paths, want)
+	}
+}
@@ -9,12 +9,39 @@ import (
 	"github.com/envtui/envtui/internal/model"
 )
 
-// ExportToShell exports env file entries as shell commands
+// ExportToShell exports env file entries as shell commands. exportFormat "export"
+// forces export statements; "powershell" and "cmd" produce Windows shell syntax.
 func ExportToShell(envFile *model.EnvFile, exportFormat string) string {
+	return ExportToShellWithOptions(envFile, exportFormat, ExportOptions{})
+}
+
+// ExportToShellWithOptions exports env file entries as shell commands using the
+// given options. Redacted secrets are left out so values set elsewhere are kept;
+// masked ones are set to a quoted placeholder.
+func ExportToShellWithOptions(envFile *model.EnvFile, exportFormat string, opts ExportOptions) string {
 	var sb strings.Builder
 
-	for _, entry := range envFile.Entries {
-		if entry.Type != model.KeyValueEntry {
+	for _, original := range envFile.Entries {
+		if original.Type != model.KeyValueEntry || (opts.RedactSecrets && original.IsSecret) {
+			continue
+		}
+		if opts.ExportedOnly && !original.Exported {
+			continue
+		}
+		entry := *original
+		entry.Value = exportValue(original, opts)
+
+		switch exportFormat {
+		case "powershell":
+			sb.WriteString(fmt.Sprintf("%s = %s\n", powershellEnvVar(entry.Key), escapePowerShellValue(entry.Value)))
+			continue
+		case "cmd":
+			if strings.ContainsAny(entry.Value, "\r\n") {
+				// cmd has no way to put a line break in a variable
+				sb.WriteString(fmt.Sprintf("rem %s skipped: multi-line values cannot be set from cmd\n", escapeCmdValue(entry.Key)))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("set %s=%s\n", escapeCmdValue(entry.Key), escapeCmdValue(entry.Value)))
 			continue
 		}
 
@@ -33,16 +60,118 @@ func ExportToShell(envFile *model.EnvFile, exportFormat string) string {
 	return sb.String()
 }
 
-// escapeShellValue escapes a value for safe shell usage
+// ExportToDirenv exports env file entries as a direnv .envrc. Each file in
+// opts.DotenvFiles is loaded with dotenv_if_exists before the exported values.
+func ExportToDirenv(envFile *model.EnvFile, opts ExportOptions) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Generated by envtui from %s\n", filepath.Base(envFile.Path)))
+	for _, dotenv := range opts.DotenvFiles {
+		sb.WriteString(fmt.Sprintf("dotenv_if_exists %s\n", escapeShellValue(dotenv)))
+	}
+	if len(opts.DotenvFiles) > 0 {
+		sb.WriteString("\n")
+	}
+
+	exportable := envFile
+	if opts.RedactSecrets {
+		// Leave secrets out entirely so values loaded elsewhere are not clobbered
+		exportable = &model.EnvFile{Path: envFile.Path}
+		var redacted []string
+		for _, entry := range envFile.Entries {
+			if entry.Type == model.KeyValueEntry && entry.IsSecret {
+				redacted = append(redacted, entry.Key)
+				continue
+			}
+			exportable.Entries = append(exportable.Entries, entry)
+		}
+		if len(redacted) > 0 {
+			sb.WriteString(fmt.Sprintf("# Secrets redacted: %s\n", strings.Join(redacted, ", ")))
+		}
+	}
+
+	sb.WriteString(ExportToShellWithOptions(exportable, "export", opts))
+
+	return sb.String()
+}
+
+// WriteDirenv writes a direnv .envrc for the env file to outputPath
+func WriteDirenv(envFile *model.EnvFile, outputPath string, opts ExportOptions) error {
+	content := ExportToDirenv(envFile, opts)
+	return writeOutput(outputPath, []byte(content), 0600)
+}
+
+// escapeShellValue quotes a value for POSIX shells. Anything but plain words is
+// single-quoted, the only quoting in which $, backticks and backslashes are not
+// interpreted: direnv sources an .envrc on cd, so "$(...)" would run there. An
+// embedded quote closes the quotes, is written escaped and opens them again.
 func escapeShellValue(value string) string {
-	// If value contains spaces or special characters, wrap in quotes
-	if strings.ContainsAny(value, " \\t\"'$()<>|&;`") {
-		// Escape double quotes
-		value = strings.ReplaceAll(value, "\"", "\\\"")
-		// Wrap in double quotes
-		return fmt.Sprintf("\"%s\"", value)
+	if value == "" || strings.Trim(value, shellSafeChars) == "" {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellSafeChars can stand unquoted in a shell word
+const shellSafeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-.,/:@%+="
+
+// powershellEnvVar returns the PowerShell variable for an environment key, using
+// the braced form for keys that are not plain identifiers
+func powershellEnvVar(key string) string {
+	for _, ch := range key {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_') {
+			key = strings.NewReplacer("`", "``", "{", "`{", "}", "`}").Replace(key)
+			return "${env:" + key + "}"
+		}
+	}
+	return "$env:" + key
+}
+
+// escapePowerShellValue renders value as a double-quoted PowerShell string.
+// The backtick is PowerShell's escape character, so it escapes itself, $ (which
+// would start an expansion), double quotes and control characters.
+func escapePowerShellValue(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, ch := range value {
+		switch ch {
+		case '`', '$', '"', '\u201C', '\u201D', '\u201E': // PowerShell also ends strings at typographic quotes
+			sb.WriteRune('`')
+			sb.WriteRune(ch)
+		case '\n':
+			sb.WriteString("`n")
+		case '\r':
+			sb.WriteString("`r")
+		case '\t':
+			sb.WriteString("`t")
+		case 0:
+			sb.WriteString("`0")
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// escapeCmdValue escapes a value for an unquoted cmd "set" statement meant to be
+// run from a batch file. cmd has no string quoting that protects everything, so
+// metacharacters (including " so it never toggles quote mode) are escaped with ^,
+// and % is doubled so it is not expanded.
+func escapeCmdValue(value string) string {
+	var sb strings.Builder
+	for _, ch := range value {
+		switch ch {
+		case '^', '&', '|', '<', '>', '(', ')', '"':
+			sb.WriteRune('^')
+			sb.WriteRune(ch)
+		case '%':
+			sb.WriteString("%%")
+		default:
+			sb.WriteRune(ch)
+		}
 	}
-	return value
+	return sb.String()
 }
 
 // GenerateShellAlias generates shell alias/function for easy envtui usage
@@ -89,8 +218,12 @@ func PrintShellCompletion(shell string) string {
 		return generateZshCompletion()
 	case "fish":
 		return generateFishCompletion()
+	case "powershell":
+		return generatePowerShellCompletion()
+	case "cmd":
+		return "rem cmd has no programmable completion; use PowerShell for envtui completions\n"
 	default:
-		return "# Shell completion not available for: " + shell + "\n# Supported shells: bash, zsh, fish\n"
+		return "# Shell completion not available for: " + shell + "\n# Supported shells: bash, zsh, fish, powershell\n"
 	}
 }
 
@@ -108,7 +241,7 @@ func generateBashCompletion() string {
             return 0
             ;;
         --format)
-            COMPREPLY=( $(compgen -W "json yaml shell" -- "${cur}") )
+            COMPREPLY=( $(compgen -W "json yaml shell direnv template dotenv toml csv powershell cmd" -- "${cur}") )
             return 0
             ;;
         *)
@@ -129,7 +262,7 @@ func generateZshCompletion() string {
 _arguments \
     '--files[Comma-separated env files]:files:_files -g "*.env"' \
     '--export[Export to file]:output file:_files' \
-    '--format[Export format]:format:(json yaml shell)' \
+    '--format[Export format]:format:(json yaml shell direnv template dotenv toml csv powershell cmd)' \
     '--import[Import from file]:input file:_files -g "*.{json,yaml,yml}"' \
     '--merge[Merge imported entries]' \
     '--overwrite[Overwrite existing entries when importing]' \
@@ -140,7 +273,7 @@ _arguments \
 func generateFishCompletion() string {
 	return `complete -c envtui -l files -d "Comma-separated env files" -r -F
 complete -c envtui -l export -d "Export to file" -r -F
-complete -c envtui -l format -d "Export format" -x -a "json yaml shell"
+complete -c envtui -l format -d "Export format" -x -a "json yaml shell direnv template dotenv toml csv powershell cmd"
 complete -c envtui -l import -d "Import from file" -r -F
 complete -c envtui -l merge -d "Merge imported entries"
 complete -c envtui -l overwrite -d "Overwrite existing entries"
@@ -148,6 +281,31 @@ complete -c envtui -l help -d "Show help"
 `
 }
 
+func generatePowerShellCompletion() string {
+	return `Register-ArgumentCompleter -Native -CommandName envtui -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $options = @('--files', '--export', '--format', '--import', '--merge', '--overwrite', '--help')
+    $formats = @('json', 'yaml', 'shell', 'direnv', 'template', 'dotenv', 'toml', 'csv', 'powershell', 'cmd')
+
+    $words = $commandAst.CommandElements | ForEach-Object { $_.ToString() }
+    $prev = if ($wordToComplete) { $words[-2] } else { $words[-1] }
+
+    $candidates = switch ($prev) {
+        '--format' { $formats }
+        { $_ -in '--files', '--export', '--import' } {
+            Get-ChildItem -Path "$wordToComplete*" -Name -ErrorAction SilentlyContinue
+        }
+        default { $options }
+    }
+
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+}
+
 // SaveShellIntegration saves shell integration script to a file
 func SaveShellIntegration(outputPath string) error {
 	content := GenerateShellAlias()
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// exportToTOML converts ExportData to TOML. Values live in an [env] table in file
+// order; secret and exported flags are kept as parallel key lists at the top level.
+func exportToTOML(data ExportData) string {
+	var secrets, exported []string
+	for _, entry := range data.Entries {
+		if entry.IsSecret {
+			secrets = append(secrets, entry.Key)
+		}
+		if entry.Exported {
+			exported = append(exported, entry.Key)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("file = " + tomlQuote(data.File) + "\n")
+	sb.WriteString(fmt.Sprintf("count = %d\n", data.Count))
+	sb.WriteString("secret = " + tomlArray(secrets) + "\n")
+	sb.WriteString("exported = " + tomlArray(exported) + "\n")
+	sb.WriteString("\n[env]\n")
+
+	for _, entry := range data.Entries {
+		sb.WriteString(tomlKey(entry.Key) + " = " + tomlQuote(entry.Value) + "\n")
+	}
+
+	return sb.String()
+}
+
+// importFromTOML parses the TOML written by exportToTOML back into ExportData.
+// Only the subset of TOML it produces is supported: strings, integers,
+// string arrays and the [env] table.
+func importFromTOML(content string) (ExportData, error) {
+	var data ExportData
+	secrets := make(map[string]bool)
+	exported := make(map[string]bool)
+	table := ""
+
+	for i, rawLine := range strings.Split(content, "\n") {
+		lineNum := i + 1
+		line := strings.TrimSpace(strings.TrimSuffix(rawLine, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return data, fmt.Errorf("line %d: malformed table header", lineNum)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, rest, err := tomlParseKey(line)
+		if err != nil {
+			return data, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, "=") {
+			return data, fmt.Errorf("line %d: expected '=' after key %s", lineNum, key)
+		}
+		rest = strings.TrimSpace(rest[1:])
+
+		switch table {
+		case "env":
+			value, err := tomlParseString(rest)
+			if err != nil {
+				return data, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			data.Entries = append(data.Entries, ExportEntry{Key: key, Value: value})
+		case "":
+			switch key {
+			case "file":
+				if data.File, err = tomlParseString(rest); err != nil {
+					return data, fmt.Errorf("line %d: %w", lineNum, err)
+				}
+			case "count":
+				if data.Count, err = strconv.Atoi(tomlStripComment(rest)); err != nil {
+					return data, fmt.Errorf("line %d: invalid count: %w", lineNum, err)
+				}
+			case "secret", "exported":
+				keys, err := tomlParseArray(rest)
+				if err != nil {
+					return data, fmt.Errorf("line %d: %w", lineNum, err)
+				}
+				for _, k := range keys {
+					if key == "secret" {
+						secrets[k] = true
+					} else {
+						exported[k] = true
+					}
+				}
+			}
+		}
+	}
+
+	for i := range data.Entries {
+		data.Entries[i].IsSecret = secrets[data.Entries[i].Key]
+		data.Entries[i].Exported = exported[data.Entries[i].Key]
+	}
+
+	return data, nil
+}
+
+// tomlKey returns the key bare if TOML allows it, otherwise quoted
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, ch := range key {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-') {
+			return tomlQuote(key)
+		}
+	}
+	return key
+}
+
+// tomlQuote renders a TOML basic string
+func tomlQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, ch := range s {
+		switch ch {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if ch < 0x20 || ch == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\u%04X`, ch))
+			} else {
+				sb.WriteRune(ch)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// tomlArray renders a single-line array of strings
+func tomlArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = tomlQuote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// tomlParseKey reads a bare or quoted key from the start of a line
+func tomlParseKey(line string) (string, string, error) {
+	if strings.HasPrefix(line, `"`) || strings.HasPrefix(line, "'") {
+		key, n, err := tomlReadString(line)
+		if err != nil {
+			return "", "", err
+		}
+		return key, line[n:], nil
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end <= 0 {
+		return "", "", fmt.Errorf("missing key")
+	}
+	return line[:end], line[end:], nil
+}
+
+// tomlParseString parses a string value followed by an optional comment
+func tomlParseString(s string) (string, error) {
+	value, n, err := tomlReadString(s)
+	if err != nil {
+		return "", err
+	}
+	if rest := tomlStripComment(s[n:]); rest != "" {
+		return "", fmt.Errorf("unexpected content after string: %s", rest)
+	}
+	return value, nil
+}
+
+// tomlParseArray parses a single-line array of strings
+func tomlParseArray(s string) ([]string, error) {
+	if !strings.HasPrefix(s, "[") {
+		return nil, fmt.Errorf("expected array")
+	}
+	s = strings.TrimSpace(s[1:])
+
+	var values []string
+	for {
+		if strings.HasPrefix(s, "]") {
+			if rest := tomlStripComment(s[1:]); rest != "" {
+				return nil, fmt.Errorf("unexpected content after array: %s", rest)
+			}
+			return values, nil
+		}
+		value, n, err := tomlReadString(s)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		s = strings.TrimSpace(s[n:])
+		if strings.HasPrefix(s, ",") {
+			s = strings.TrimSpace(s[1:])
+		} else if !strings.HasPrefix(s, "]") {
+			return nil, fmt.Errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// tomlReadString reads a basic ("...") or literal ('...') string from the start
+// of s and returns its value and the number of bytes consumed
+func tomlReadString(s string) (string, int, error) {
+	if strings.HasPrefix(s, "'") {
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", 0, fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], end + 2, nil
+	}
+	if !strings.HasPrefix(s, `"`) {
+		return "", 0, fmt.Errorf("expected string")
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		if ch == '"' {
+			return sb.String(), i + 1, nil
+		}
+		if ch != '\\' {
+			sb.WriteByte(ch)
+			continue
+		}
+		if i+1 >= len(s) {
+			break
+		}
+		i++
+		switch s[i] {
+		case '\\':
+			sb.WriteByte('\\')
+		case '"':
+			sb.WriteByte('"')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u', 'U':
+			size := 4
+			if s[i] == 'U' {
+				size = 8
+			}
+			if i+size >= len(s) {
+				return "", 0, fmt.Errorf("truncated unicode escape")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", 0, fmt.Errorf("invalid unicode escape")
+			}
+			sb.WriteRune(rune(code))
+			i += size
+		default:
+			return "", 0, fmt.Errorf("invalid escape sequence \\%c", s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// tomlStripComment trims whitespace and a trailing # comment
+func tomlStripComment(s string) string {
+	if idx := strings.Index(s, "#"); idx >= 0 {
+		s = s[:idx]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,95 @@
+package styles
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// monochrome is set while ThemeMonochrome is in use
+var monochrome bool
+
+// sgrPattern matches an ANSI "select graphic rendition" sequence, e.g. "\x1b[1;38;5;99m"
+var sgrPattern = regexp.MustCompile("\x1b\\[([0-9;]*)m")
+
+// NoColorRequested reports whether the environment asks for output without
+// colors: NO_COLOR is set (https://no-color.org) or the terminal is "dumb"
+func NoColorRequested() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// applyMonochrome sets styles that tell things apart with bold, underline and
+// reverse video instead of colors. Views still set some colors inline, so their
+// output goes through Decolor as well.
+func applyMonochrome() {
+	// The colors are kept for views that refer to them; Decolor drops them
+	applyPalette(darkPalette)
+
+	// NO_COLOR makes lipgloss drop every attribute, not just colors. Keep the
+	// attributes on terminals that support them; Decolor removes the colors.
+	if termenv.NewOutput(os.Stdout).ColorProfile() != termenv.Ascii {
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+
+	TitleStyle = lipgloss.NewStyle().Bold(true).Underline(true).Padding(0, 1)
+	SubtitleStyle = lipgloss.NewStyle().Padding(0, 1)
+	BorderStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	FocusedBorderStyle = lipgloss.NewStyle().Border(lipgloss.ThickBorder()).Padding(0, 1)
+
+	SelectedItemStyle = lipgloss.NewStyle().Reverse(true).Bold(true).Padding(0, 2)
+	KeyStyle = lipgloss.NewStyle().Bold(true)
+	ValueStyle = lipgloss.NewStyle()
+	SecretValueStyle = lipgloss.NewStyle().Italic(true)
+	CommentStyle = lipgloss.NewStyle().Faint(true).Italic(true)
+	MatchHighlightStyle = lipgloss.NewStyle().Underline(true).Bold(true)
+
+	HelpKeyStyle = lipgloss.NewStyle().Bold(true)
+	HelpDescStyle = lipgloss.NewStyle()
+	HelpSeparatorStyle = lipgloss.NewStyle().Faint(true)
+}
+
+// Monochrome reports whether colors are turned off
+func Monochrome() bool {
+	return monochrome
+}
+
+// Decolor removes the colors from rendered text and keeps its other attributes.
+// A background color becomes reverse video, so selected rows and banners still
+// stand out.
+func Decolor(s string) string {
+	if !strings.Contains(s, "\x1b[") {
+		return s
+	}
+	return sgrPattern.ReplaceAllStringFunc(s, func(seq string) string {
+		params := strings.Split(seq[2:len(seq)-1], ";")
+		var kept []string
+		for i := 0; i < len(params); i++ {
+			n, _ := strconv.Atoi(params[i])
+			switch {
+			case n == 38 || n == 48:
+				// Extended colors take more parameters: 38;5;n or 38;2;r;g;b
+				if i+1 < len(params) && params[i+1] == "5" {
+					i += 2
+				} else if i+1 < len(params) && params[i+1] == "2" {
+					i += 4
+				}
+				if n == 48 {
+					kept = append(kept, "7")
+				}
+			case n >= 30 && n <= 39, n >= 90 && n <= 97, n == 49:
+			case n >= 40 && n <= 47, n >= 100 && n <= 107:
+				kept = append(kept, "7")
+			default:
+				kept = append(kept, params[i])
+			}
+		}
+		if len(kept) == 0 {
+			return ""
+		}
+		return "\x1b[" + strings.Join(kept, ";") + "m"
+	})
+}
@@ -0,0 +1,20 @@
+package styles
+
+import "testing"
+
+func TestDecolorKeepsAttributes(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"\x1b[1;38;2;124;58;237mTitle\x1b[0m", "\x1b[1mTitle\x1b[0m"},
+		{"\x1b[97;45mSelected\x1b[0m", "\x1b[7mSelected\x1b[0m"},
+		{"\x1b[48;5;99;4mBanner\x1b[0m", "\x1b[7;4mBanner\x1b[0m"},
+		{"\x1b[31mred\x1b[39m text", "red text"},
+	}
+	for _, tt := range tests {
+		if got := Decolor(tt.in); got != tt.want {
+			t.Errorf("Decolor(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
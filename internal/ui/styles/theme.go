@@ -1,89 +1,229 @@
 package styles
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Theme is a set of colors and styles suited to a kind of terminal
+type Theme int
+
+const (
+	ThemeDark       Theme = iota // Light text for dark backgrounds, the default
+	ThemeLight                   // Darker text with enough contrast on light backgrounds
+	ThemeMonochrome              // No colors, only bold, underline and reverse video (see Decolor)
+)
+
+func (t Theme) String() string {
+	switch t {
+	case ThemeLight:
+		return "light"
+	case ThemeMonochrome:
+		return "mono"
+	default:
+		return "dark"
+	}
+}
+
+// ParseTheme parses a --theme value. "auto" picks light or dark from the
+// terminal's background color.
+func ParseTheme(name string) (Theme, error) {
+	switch name {
+	case "", "auto":
+		if lipgloss.HasDarkBackground() {
+			return ThemeDark, nil
+		}
+		return ThemeLight, nil
+	case "dark":
+		return ThemeDark, nil
+	case "light":
+		return ThemeLight, nil
+	case "mono", "monochrome":
+		return ThemeMonochrome, nil
+	}
+	return ThemeDark, fmt.Errorf("unknown theme %q (use auto, dark, light or mono)", name)
+}
+
+// palette holds the colors a theme builds its styles from
+type palette struct {
+	primary, secondary, danger, warning, info string
+	database, aws, api, secret, other         string
+	text, value, muted, help, border          string
+	separator, match                          string
+}
+
+var darkPalette = palette{
+	primary: "#7C3AED", secondary: "#10B981", danger: "#EF4444", warning: "#F59E0B", info: "#3B82F6",
+	database: "#3B82F6", aws: "#FF9500", api: "#10B981", secret: "#EF4444", other: "#6B7280",
+	text: "#FFFFFF", value: "#D1D5DB", muted: "#6B7280", help: "#9CA3AF", border: "#374151",
+	separator: "#4B5563", match: "#FACC15",
+}
+
+var lightPalette = palette{
+	primary: "#6D28D9", secondary: "#047857", danger: "#B91C1C", warning: "#B45309", info: "#1D4ED8",
+	database: "#1D4ED8", aws: "#C2410C", api: "#047857", secret: "#B91C1C", other: "#4B5563",
+	text: "#111827", value: "#374151", muted: "#4B5563", help: "#4B5563", border: "#9CA3AF",
+	separator: "#6B7280", match: "#B45309",
+}
+
 var (
 	// Colors
-	Primary   = lipgloss.Color("#7C3AED")
-	Secondary = lipgloss.Color("#10B981")
-	Danger    = lipgloss.Color("#EF4444")
-	Warning   = lipgloss.Color("#F59E0B")
-	Info      = lipgloss.Color("#3B82F6")
+	Primary   lipgloss.Color
+	Secondary lipgloss.Color
+	Danger    lipgloss.Color
+	Warning   lipgloss.Color
+	Info      lipgloss.Color
 
 	// Category colors
-	DatabaseColor = lipgloss.Color("#3B82F6")
-	AWSColor      = lipgloss.Color("#FF9500")
-	APIColor      = lipgloss.Color("#10B981")
-	SecretColor   = lipgloss.Color("#EF4444")
-	OtherColor    = lipgloss.Color("#6B7280")
+	DatabaseColor lipgloss.Color
+	AWSColor      lipgloss.Color
+	APIColor      lipgloss.Color
+	SecretColor   lipgloss.Color
+	OtherColor    lipgloss.Color
 )
 
 // Base styles
 var (
+	BaseStyle          lipgloss.Style
+	TitleStyle         lipgloss.Style
+	SubtitleStyle      lipgloss.Style
+	BorderStyle        lipgloss.Style
+	FocusedBorderStyle lipgloss.Style
+)
+
+// List styles
+var (
+	ListItemStyle       lipgloss.Style
+	SelectedItemStyle   lipgloss.Style
+	KeyStyle            lipgloss.Style
+	ValueStyle          lipgloss.Style
+	SecretValueStyle    lipgloss.Style
+	CommentStyle        lipgloss.Style
+	MatchHighlightStyle lipgloss.Style
+)
+
+// Help styles
+var (
+	HelpKeyStyle       lipgloss.Style
+	HelpDescStyle      lipgloss.Style
+	HelpSeparatorStyle lipgloss.Style
+)
+
+func init() {
+	SetTheme(ThemeDark)
+}
+
+// SetTheme switches every color and style to the given theme
+func SetTheme(theme Theme) {
+	monochrome = theme == ThemeMonochrome
+	switch theme {
+	case ThemeLight:
+		applyPalette(lightPalette)
+	case ThemeMonochrome:
+		applyMonochrome()
+	default:
+		applyPalette(darkPalette)
+	}
+}
+
+// applyPalette builds the styles from the colors of a palette
+func applyPalette(p palette) {
+	Primary = lipgloss.Color(p.primary)
+	Secondary = lipgloss.Color(p.secondary)
+	Danger = lipgloss.Color(p.danger)
+	Warning = lipgloss.Color(p.warning)
+	Info = lipgloss.Color(p.info)
+
+	DatabaseColor = lipgloss.Color(p.database)
+	AWSColor = lipgloss.Color(p.aws)
+	APIColor = lipgloss.Color(p.api)
+	SecretColor = lipgloss.Color(p.secret)
+	OtherColor = lipgloss.Color(p.other)
+
 	BaseStyle = lipgloss.NewStyle().
-			Padding(1, 2)
+		Padding(1, 2)
 
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(Primary).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(Primary).
+		Bold(true).
+		Padding(0, 1)
 
 	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6B7280")).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(p.muted)).
+		Padding(0, 1)
 
 	BorderStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#374151")).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.border)).
+		Padding(0, 1)
 
 	FocusedBorderStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(Primary).
-				Padding(0, 1)
-)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(0, 1)
 
-// List styles
-var (
 	ListItemStyle = lipgloss.NewStyle().
-			Padding(0, 2)
+		Padding(0, 2)
 
 	SelectedItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(Primary).
-				Padding(0, 2)
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(Primary).
+		Padding(0, 2)
 
 	KeyStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Bold(true)
+		Foreground(lipgloss.Color(p.text)).
+		Bold(true)
 
 	ValueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#D1D5DB"))
+		Foreground(lipgloss.Color(p.value))
 
 	SecretValueStyle = lipgloss.NewStyle().
-				Foreground(SecretColor)
+		Foreground(SecretColor)
 
 	CommentStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6B7280")).
-			Italic(true)
-)
+		Foreground(lipgloss.Color(p.muted)).
+		Italic(true)
+
+	MatchHighlightStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.match)).
+		Bold(true)
 
-// Help styles
-var (
 	HelpKeyStyle = lipgloss.NewStyle().
-			Foreground(Primary).
-			Bold(true)
+		Foreground(Primary).
+		Bold(true)
 
 	HelpDescStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#9CA3AF"))
+		Foreground(lipgloss.Color(p.help))
 
 	HelpSeparatorStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#4B5563"))
-)
+		Foreground(lipgloss.Color(p.separator))
+}
+
+// customCategoryColors override or extend the built-in category colors
+var customCategoryColors map[string]lipgloss.Color
 
+// categoryPalette colors custom categories that have no configured color
+var categoryPalette = []lipgloss.Color{
+	"#A855F7", "#EC4899", "#14B8A6", "#EAB308", "#8B5CF6", "#06B6D4", "#F97316", "#84CC16",
+}
+
+// SetCategoryColors sets colors for custom categories, keyed by category name.
+// Passing nil restores the built-in colors.
+func SetCategoryColors(colors map[string]string) {
+	customCategoryColors = make(map[string]lipgloss.Color, len(colors))
+	for category, color := range colors {
+		customCategoryColors[category] = lipgloss.Color(color)
+	}
+}
+
+// CategoryColor returns the dot color for a category. Unknown categories get a
+// stable color from a palette so each one is distinct.
 func CategoryColor(category string) lipgloss.Color {
+	if color, ok := customCategoryColors[category]; ok {
+		return color
+	}
+
 	switch category {
 	case "database":
 		return DatabaseColor
@@ -93,7 +233,13 @@ func CategoryColor(category string) lipgloss.Color {
 		return APIColor
 	case "secret":
 		return SecretColor
-	default:
+	case "other", "":
 		return OtherColor
+	default:
+		var hash uint32
+		for _, ch := range category {
+			hash = hash*31 + uint32(ch)
+		}
+		return categoryPalette[hash%uint32(len(categoryPalette))]
 	}
 }
@@ -0,0 +1,38 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSetThemeSwitchesAllStyles(t *testing.T) {
+	defer SetTheme(ThemeDark)
+
+	SetTheme(ThemeLight)
+	if Primary != lipgloss.Color(lightPalette.primary) || ValueStyle.GetForeground() != lipgloss.Color(lightPalette.value) {
+		t.Errorf("light theme kept dark colors: primary %v, value %v", Primary, ValueStyle.GetForeground())
+	}
+	if Monochrome() {
+		t.Error("light theme should not be monochrome")
+	}
+
+	SetTheme(ThemeMonochrome)
+	if !Monochrome() || !SelectedItemStyle.GetReverse() {
+		t.Error("monochrome theme should select rows with reverse video")
+	}
+
+	SetTheme(ThemeDark)
+	if Monochrome() || ValueStyle.GetForeground() != lipgloss.Color(darkPalette.value) {
+		t.Error("expected the dark theme to be restored")
+	}
+
+	for name, want := range map[string]Theme{"dark": ThemeDark, "light": ThemeLight, "mono": ThemeMonochrome} {
+		if got, err := ParseTheme(name); err != nil || got != want {
+			t.Errorf("ParseTheme(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseTheme("solarized"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
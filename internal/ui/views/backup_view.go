@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/envtui/envtui/internal/storage"
@@ -18,11 +19,34 @@ const (
 	BackupViewModeList BackupViewMode = iota
 	BackupViewModeConfirmRestore
 	BackupViewModeConfirmDelete
+	BackupViewModeDiff
+	BackupViewModeLabel // Asking for the label of a new backup
 )
 
+// backupDateFilter limits the backup list to a recent period
+type backupDateFilter int
+
+const (
+	backupDateAll backupDateFilter = iota
+	backupDateToday
+	backupDateWeek
+)
+
+func (f backupDateFilter) String() string {
+	switch f {
+	case backupDateToday:
+		return "today"
+	case backupDateWeek:
+		return "last 7 days"
+	default:
+		return "all dates"
+	}
+}
+
 // BackupView displays and manages backup files
 type BackupView struct {
-	backups      []storage.BackupInfo
+	allBackups   []storage.BackupInfo
+	backups      []storage.BackupInfo // allBackups matching the search and date filter
 	selected     int
 	filePath     string
 	mode         BackupViewMode
@@ -30,15 +54,32 @@ type BackupView struct {
 	height       int
 	message      string
 	messageTimer time.Time
+	isError      bool
+	diffView     DiffView
+	labelInput   textinput.Model
+	searchInput  textinput.Model
+	searching    bool
+	dateFilter   backupDateFilter
 }
 
 // NewBackupView creates a new backup view
 func NewBackupView(filePath string, backups []storage.BackupInfo) BackupView {
+	li := textinput.New()
+	li.Placeholder = "e.g. before prod migration"
+	li.CharLimit = 60
+
+	si := textinput.New()
+	si.Placeholder = "Search by date, time or label..."
+	si.CharLimit = 50
+
 	return BackupView{
-		backups:  backups,
-		filePath: filePath,
-		selected: 0,
-		mode:     BackupViewModeList,
+		allBackups:  backups,
+		backups:     backups,
+		filePath:    filePath,
+		selected:    0,
+		mode:        BackupViewModeList,
+		labelInput:  li,
+		searchInput: si,
 	}
 }
 
@@ -57,6 +98,25 @@ func (bv BackupView) Init() tea.Cmd {
 func (bv BackupView) Update(msg tea.Msg) (BackupView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if bv.searching {
+			switch msg.String() {
+			case "esc":
+				bv.searching = false
+				bv.searchInput.Blur()
+				bv.searchInput.SetValue("")
+				bv.applyFilter()
+			case "enter":
+				bv.searching = false
+				bv.searchInput.Blur()
+			default:
+				var cmd tea.Cmd
+				bv.searchInput, cmd = bv.searchInput.Update(msg)
+				bv.applyFilter()
+				return bv, cmd
+			}
+			return bv, nil
+		}
+
 		switch bv.mode {
 		case BackupViewModeConfirmRestore:
 			switch msg.String() {
@@ -74,6 +134,28 @@ func (bv BackupView) Update(msg tea.Msg) (BackupView, tea.Cmd) {
 				bv.mode = BackupViewModeList
 				return bv, nil
 			}
+		case BackupViewModeLabel:
+			switch msg.String() {
+			case "enter":
+				bv.createLabeledBackup()
+				return bv, nil
+			case "esc":
+				bv.mode = BackupViewModeList
+				bv.labelInput.Blur()
+				return bv, nil
+			}
+			var cmd tea.Cmd
+			bv.labelInput, cmd = bv.labelInput.Update(msg)
+			return bv, cmd
+		case BackupViewModeDiff:
+			switch msg.String() {
+			case "esc", "q", "v":
+				bv.mode = BackupViewModeList
+				return bv, nil
+			case "r":
+				bv.mode = BackupViewModeConfirmRestore
+				return bv, nil
+			}
 		default:
 			switch msg.String() {
 			case "q", "esc":
@@ -94,12 +176,132 @@ func (bv BackupView) Update(msg tea.Msg) (BackupView, tea.Cmd) {
 				if len(bv.backups) > 0 {
 					bv.mode = BackupViewModeConfirmDelete
 				}
+			case "v":
+				if len(bv.backups) > 0 {
+					bv.showDiff()
+				}
+			case "n":
+				bv.mode = BackupViewModeLabel
+				bv.labelInput.SetValue("")
+				bv.labelInput.Focus()
+				return bv, textinput.Blink
+			case "/":
+				bv.searching = true
+				bv.searchInput.Focus()
+				return bv, textinput.Blink
+			case "t":
+				bv.dateFilter = (bv.dateFilter + 1) % (backupDateWeek + 1)
+				bv.applyFilter()
 			}
 		}
 	}
 	return bv, nil
 }
 
+// showDiff previews what restoring the selected backup would change in the current file
+func (bv *BackupView) showDiff() {
+	backup := bv.backups[bv.selected]
+	backupFile, err := storage.ReadFile(backup.Path)
+	if err != nil {
+		bv.message = fmt.Sprintf("Cannot preview backup: %v", err)
+		bv.isError = true
+		return
+	}
+	current, err := storage.ReadFile(bv.filePath)
+	if err != nil {
+		bv.message = fmt.Sprintf("Cannot read current file: %v", err)
+		bv.isError = true
+		return
+	}
+
+	// The backup is the state after a restore, the current file is the baseline
+	bv.diffView = NewDiffView(backupFile, current)
+	bv.diffView.SetLabels(
+		"Restore Preview "+backup.Timestamp.Format("Jan 02 15:04:05"),
+		"Backup matches the current file - restoring changes nothing",
+	)
+	bv.diffView.SetSize(bv.width, bv.height-4)
+	bv.message = ""
+	bv.mode = BackupViewModeDiff
+}
+
+// applyFilter shows the backups matching the search text and date filter
+func (bv *BackupView) applyFilter() {
+	query := strings.ToLower(strings.TrimSpace(bv.searchInput.Value()))
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	bv.backups = nil
+	for _, backup := range bv.allBackups {
+		switch bv.dateFilter {
+		case backupDateToday:
+			if backup.Timestamp.Before(today) {
+				continue
+			}
+		case backupDateWeek:
+			if backup.Timestamp.Before(today.AddDate(0, 0, -6)) {
+				continue
+			}
+		}
+		if query != "" && !strings.Contains(backupSearchText(backup), query) {
+			continue
+		}
+		bv.backups = append(bv.backups, backup)
+	}
+
+	if bv.selected >= len(bv.backups) {
+		bv.selected = max(0, len(bv.backups)-1)
+	}
+}
+
+// backupSearchText is the lowercase text a backup is searched by: its time in
+// the displayed and ISO formats, and its label
+func backupSearchText(backup storage.BackupInfo) string {
+	text := backup.Timestamp.Format("Jan 02 15:04:05") + " " + backup.Timestamp.Format("2006-01-02") + " " + backup.Label
+	if backup.PreRestore {
+		text += " pre-restore"
+	}
+	return strings.ToLower(text)
+}
+
+// isFiltered returns true while the search or date filter hides backups
+func (bv BackupView) isFiltered() bool {
+	return bv.searchInput.Value() != "" || bv.dateFilter != backupDateAll
+}
+
+// createLabeledBackup backs up the file with the entered label and reloads the list
+func (bv *BackupView) createLabeledBackup() {
+	bv.mode = BackupViewModeList
+	bv.labelInput.Blur()
+	bv.messageTimer = time.Now()
+
+	label := storage.SanitizeBackupLabel(bv.labelInput.Value())
+	if err := storage.CreateLabeledBackup(bv.filePath, label); err != nil {
+		bv.message = fmt.Sprintf("Error creating backup: %v", err)
+		bv.isError = true
+		return
+	}
+	backups, err := storage.ListBackups(bv.filePath)
+	if err != nil {
+		bv.message = fmt.Sprintf("Error listing backups: %v", err)
+		bv.isError = true
+		return
+	}
+	bv.allBackups = backups
+	bv.selected = 0
+	bv.applyFilter()
+	bv.isError = false
+	bv.message = "Backup created"
+	if label != "" {
+		bv.message = fmt.Sprintf("Backup %q created", label)
+	}
+}
+
+// IsListMode returns true when the backup list is shown (no dialog, preview or search open)
+func (bv BackupView) IsListMode() bool {
+	return bv.mode == BackupViewModeList && !bv.searching
+}
+
 func (bv BackupView) confirmRestore() tea.Cmd {
 	if bv.selected >= 0 && bv.selected < len(bv.backups) {
 		backup := bv.backups[bv.selected]
@@ -124,6 +326,12 @@ func (bv BackupView) confirmDelete() tea.Cmd {
 		} else {
 			bv.message = "Backup deleted successfully!"
 			// Remove from list
+			for i := range bv.allBackups {
+				if bv.allBackups[i].Path == backup.Path {
+					bv.allBackups = append(bv.allBackups[:i], bv.allBackups[i+1:]...)
+					break
+				}
+			}
 			bv.backups = append(bv.backups[:bv.selected], bv.backups[bv.selected+1:]...)
 			if bv.selected >= len(bv.backups) && bv.selected > 0 {
 				bv.selected--
@@ -149,12 +357,25 @@ func (bv BackupView) View() string {
 
 	// File info
 	subtitle := styles.SubtitleStyle.Render(fmt.Sprintf("📁 %s", bv.filePath))
+	if bv.isFiltered() {
+		subtitle = styles.SubtitleStyle.Render(fmt.Sprintf("📁 %s • showing %d of %d (%s)",
+			bv.filePath, len(bv.backups), len(bv.allBackups), bv.dateFilter))
+	}
 	sections = append(sections, subtitle)
 
+	// Search input
+	if bv.searching || bv.searchInput.Value() != "" {
+		sections = append(sections, styles.BorderStyle.Render(bv.searchInput.View()))
+	}
+
 	// Message area
 	if bv.message != "" {
+		color := lipgloss.Color("#22C55E")
+		if bv.isError {
+			color = lipgloss.Color("#EF4444")
+		}
 		msgStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#22C55E")).
+			Foreground(color).
 			Padding(1, 1)
 		sections = append(sections, msgStyle.Render(bv.message))
 	}
@@ -165,6 +386,12 @@ func (bv BackupView) View() string {
 		sections = append(sections, bv.renderConfirmDialog("restore"))
 	case BackupViewModeConfirmDelete:
 		sections = append(sections, bv.renderConfirmDialog("delete"))
+	case BackupViewModeDiff:
+		sections = append(sections, bv.diffView.View())
+		return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	case BackupViewModeLabel:
+		labelBox := styles.BorderStyle.Render(styles.HelpKeyStyle.Render("Backup label: ") + bv.labelInput.View())
+		sections = append(sections, labelBox, bv.renderBackupList())
 	default:
 		sections = append(sections, bv.renderBackupList())
 	}
@@ -178,13 +405,20 @@ func (bv BackupView) View() string {
 
 func (bv BackupView) renderBackupList() string {
 	if len(bv.backups) == 0 {
+		message := "No backups found for this file."
+		if len(bv.allBackups) > 0 {
+			message = "No backups match the search or date filter."
+		}
 		return lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#6B7280")).
 			Padding(2, 2).
-			Render("No backups found for this file.")
+			Render(message)
 	}
 
 	listHeight := bv.height - 12
+	if bv.searching || bv.searchInput.Value() != "" {
+		listHeight -= 3
+	}
 	var items []string
 
 	start := 0
@@ -216,6 +450,12 @@ func (bv BackupView) renderBackupItem(backup storage.BackupInfo, selected bool)
 	sizeStr := formatBytes(backup.Size)
 
 	content := fmt.Sprintf("%s (%s)", timeStr, sizeStr)
+	if backup.Label != "" {
+		content += " 🏷 " + backup.Label
+	}
+	if backup.PreRestore {
+		content += " [pre-restore]"
+	}
 	return style.Width(bv.width - 6).Render(content)
 }
 
@@ -239,11 +479,22 @@ func (bv BackupView) renderConfirmDialog(action string) string {
 }
 
 func (bv BackupView) renderHelp() string {
+	if bv.mode == BackupViewModeLabel {
+		return styles.HelpDescStyle.Render("Press Enter to create the backup (label optional), Esc to cancel")
+	}
+	if bv.searching {
+		return styles.HelpDescStyle.Render("Press Enter to confirm search, Esc to clear it")
+	}
+
 	helpItems := []string{
 		styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
 		styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
+		styles.HelpKeyStyle.Render("v") + " " + styles.HelpDescStyle.Render("preview"),
 		styles.HelpKeyStyle.Render("r") + " " + styles.HelpDescStyle.Render("restore"),
 		styles.HelpKeyStyle.Render("d") + " " + styles.HelpDescStyle.Render("delete"),
+		styles.HelpKeyStyle.Render("n") + " " + styles.HelpDescStyle.Render("new labeled backup"),
+		styles.HelpKeyStyle.Render("/") + " " + styles.HelpDescStyle.Render("search"),
+		styles.HelpKeyStyle.Render("t") + " " + styles.HelpDescStyle.Render(bv.dateFilter.String()),
 		styles.HelpKeyStyle.Render("Esc/q") + " " + styles.HelpDescStyle.Render("close"),
 	}
 
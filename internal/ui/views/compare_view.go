@@ -0,0 +1,403 @@
+package views
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/envtui/envtui/internal/model"
+	"github.com/envtui/envtui/internal/storage"
+	"github.com/envtui/envtui/internal/ui/styles"
+)
+
+var (
+	compareOnlyCurrentColor = lipgloss.Color("#22C55E") // Green
+	compareOnlyOtherColor   = lipgloss.Color("#3B82F6") // Blue
+	compareDifferentColor   = lipgloss.Color("#F59E0B") // Yellow/Orange
+)
+
+// mergeSide is the side whose value wins for a key when merging
+type mergeSide int
+
+const (
+	mergeUnresolved mergeSide = iota
+	mergeCurrentWins
+	mergeOtherWins
+)
+
+// MergeFilesMsg asks the app to apply the merge decisions made in the compare view.
+// Each map is a resolution for storage.MergeResolve with the other file as source.
+type MergeFilesMsg struct {
+	Current     *model.EnvFile
+	Other       *model.EnvFile
+	IntoCurrent map[string]storage.MergeChoice
+	IntoOther   map[string]storage.MergeChoice
+}
+
+// CompareView shows a side-by-side diff between two open env files, or between
+// a file and the process environment
+type CompareView struct {
+	current     *model.EnvFile
+	other       *model.EnvFile
+	environ     map[string]string // Compared against instead of other; merging is disabled
+	hideEnvOnly bool              // Whether keys only set in the environment are hidden
+	compare     *model.EnvFileCompare
+	diffs       []model.FileDiff // Only keys that differ, sorted by key
+	choices     map[string]mergeSide
+	selected    int
+	showSecrets bool
+	message     string
+	patch       patchPrompt
+	width       int
+	height      int
+}
+
+// NewCompareView creates a view comparing the current file against another open file
+func NewCompareView(current, other *model.EnvFile) CompareView {
+	cv := CompareView{
+		current: current,
+		other:   other,
+		choices: make(map[string]mergeSide),
+	}
+	cv.refresh()
+	return cv
+}
+
+// NewEnvironCompareView creates a read-only view comparing the current file
+// against environment variables, highlighting drift from what the shell exports
+func NewEnvironCompareView(current *model.EnvFile, environ map[string]string) CompareView {
+	cv := CompareView{
+		current: current,
+		environ: environ,
+		choices: make(map[string]mergeSide),
+	}
+	cv.refresh()
+	return cv
+}
+
+// Refresh recomputes the comparison after the files changed and clears merge decisions
+func (cv *CompareView) Refresh(message string) {
+	cv.choices = make(map[string]mergeSide)
+	cv.message = message
+	cv.refresh()
+}
+
+// refresh recomputes the comparison from the two files
+func (cv *CompareView) refresh() {
+	if cv.environ != nil {
+		cv.compare = model.CompareWithEnviron(cv.current, cv.environ)
+	} else {
+		cv.compare = cv.current.CompareWith(cv.other)
+	}
+	cv.diffs = cv.diffs[:0]
+	for _, diff := range cv.compare.Differences {
+		if diff.OnlyInOther && cv.hideEnvOnly {
+			continue
+		}
+		if diff.OnlyInCurrent || diff.OnlyInOther || diff.Different {
+			cv.diffs = append(cv.diffs, diff)
+		}
+	}
+	sort.Slice(cv.diffs, func(i, j int) bool {
+		return cv.diffs[i].Key < cv.diffs[j].Key
+	})
+	if cv.selected >= len(cv.diffs) {
+		cv.selected = max(0, len(cv.diffs)-1)
+	}
+}
+
+// SetSize sets the dimensions of the compare view
+func (cv *CompareView) SetSize(width, height int) {
+	cv.width = width
+	cv.height = height
+}
+
+// Update handles user input
+func (cv CompareView) Update(msg tea.Msg) (CompareView, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if cv.patch.active {
+			return cv, cv.patch.update(msg, cv.patchDiffs(), cv.patchHeader())
+		}
+		switch msg.String() {
+		case "e":
+			if len(cv.diffs) == 0 {
+				cv.message = "Nothing to export - there are no differences"
+				return cv, nil
+			}
+			cv.message = ""
+			return cv, cv.patch.open(cv.patchPath())
+		case "up", "k":
+			if cv.selected > 0 {
+				cv.selected--
+			}
+		case "down", "j":
+			if cv.selected < len(cv.diffs)-1 {
+				cv.selected++
+			}
+		case "x":
+			cv.showSecrets = !cv.showSecrets
+		}
+		if cv.environ != nil {
+			// The environment can't be written, so there is nothing to merge
+			if msg.String() == "o" {
+				cv.hideEnvOnly = !cv.hideEnvOnly
+				cv.refresh()
+			}
+			return cv, nil
+		}
+		switch msg.String() {
+		case "left", "h":
+			cv.choose(mergeCurrentWins)
+		case "right", "l":
+			cv.choose(mergeOtherWins)
+		case " ":
+			cv.choose(mergeUnresolved)
+		case "A":
+			cv.chooseAll(mergeCurrentWins)
+		case "B":
+			cv.chooseAll(mergeOtherWins)
+		case "w":
+			return cv.applyMerge()
+		}
+	}
+	return cv, nil
+}
+
+// patchDiffs returns the listed differences, from the other file to the current one
+func (cv CompareView) patchDiffs() []DiffEntry {
+	return CompareDiffs(&model.EnvFileCompare{Differences: cv.diffs})
+}
+
+// patchHeader describes the comparison at the top of an exported patch
+func (cv CompareView) patchHeader() string {
+	return fmt.Sprintf("%s compared with %s (+ only in %[1]s, - only in %[2]s, ~ %[2]s -> %[1]s)",
+		filepath.Base(cv.current.Path), cv.otherName())
+}
+
+// patchPath suggests where to export the comparison, next to the current file
+func (cv CompareView) patchPath() string {
+	name := strings.TrimPrefix(filepath.Base(cv.current.Path), ".") + "-vs-" + strings.TrimPrefix(cv.otherName(), ".")
+	return filepath.Join(filepath.Dir(cv.current.Path), name+".diff")
+}
+
+// CapturesInput reports whether keys go to the patch path prompt
+func (cv CompareView) CapturesInput() bool {
+	return cv.patch.active
+}
+
+// SetMessage shows a message below the comparison
+func (cv *CompareView) SetMessage(message string) {
+	cv.message = message
+}
+
+// choose sets the winning side for the selected key
+func (cv *CompareView) choose(side mergeSide) {
+	if cv.selected < 0 || cv.selected >= len(cv.diffs) {
+		return
+	}
+	key := cv.diffs[cv.selected].Key
+	if side == mergeUnresolved {
+		delete(cv.choices, key)
+	} else {
+		cv.choices[key] = side
+	}
+	cv.message = ""
+}
+
+// chooseAll sets the winning side for every differing key
+func (cv *CompareView) chooseAll(side mergeSide) {
+	for _, diff := range cv.diffs {
+		cv.choices[diff.Key] = side
+	}
+	cv.message = ""
+}
+
+// applyMerge turns the per-key choices into resolutions for both files
+func (cv *CompareView) applyMerge() (CompareView, tea.Cmd) {
+	if len(cv.choices) == 0 {
+		cv.message = "Nothing to merge - pick a side with ←/→ first"
+		return *cv, nil
+	}
+
+	msg := MergeFilesMsg{
+		Current:     cv.current,
+		Other:       cv.other,
+		IntoCurrent: make(map[string]storage.MergeChoice),
+		IntoOther:   make(map[string]storage.MergeChoice),
+	}
+	for key, side := range cv.choices {
+		if side == mergeCurrentWins {
+			msg.IntoOther[key] = storage.MergeTakeSource
+		} else {
+			msg.IntoCurrent[key] = storage.MergeTakeSource
+		}
+	}
+	return *cv, func() tea.Msg { return msg }
+}
+
+// View renders the compare view
+func (cv CompareView) View() string {
+	if cv.width == 0 {
+		return "Loading..."
+	}
+
+	var sections []string
+
+	title := styles.TitleStyle.Render(fmt.Sprintf("Compare - %d differences", len(cv.diffs)))
+	sections = append(sections, title)
+
+	subtitle := styles.SubtitleStyle.Render(fmt.Sprintf("📁 %s ⇄ %s", cv.current.Path, cv.otherName()))
+	sections = append(sections, subtitle)
+	sections = append(sections, cv.renderCounts())
+
+	listHeight := cv.height - 10
+	if listHeight < 5 {
+		listHeight = 5
+	}
+
+	var list string
+	if len(cv.diffs) == 0 {
+		list = styles.HelpDescStyle.Render("Both files define the same keys with the same values")
+		if cv.environ != nil {
+			list = styles.HelpDescStyle.Render("The environment matches every key in the file")
+		}
+	} else {
+		colWidth := max(10, (cv.width-12)/3)
+		header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#9CA3AF")).Render(
+			"    " + padColumn("KEY", colWidth) + " " +
+				padColumn(filepath.Base(cv.current.Path), colWidth) + " " +
+				padColumn(cv.otherName(), colWidth))
+
+		start := max(0, cv.selected-(listHeight-1)/2)
+		end := min(len(cv.diffs), start+listHeight-1)
+
+		items := []string{header}
+		for i := start; i < end; i++ {
+			items = append(items, cv.renderDiff(cv.diffs[i], colWidth, i == cv.selected))
+		}
+		list = strings.Join(items, "\n")
+	}
+
+	listBox := styles.BorderStyle.Width(cv.width - 4).Height(listHeight).Render(list)
+	sections = append(sections, listBox)
+
+	if cv.message != "" {
+		sections = append(sections, lipgloss.NewStyle().Foreground(styles.Info).Render(" "+cv.message))
+	}
+	if cv.patch.active {
+		sections = append(sections, cv.patch.view(len(cv.diffs)))
+	} else {
+		sections = append(sections, cv.renderHelp())
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (cv CompareView) renderCounts() string {
+	counts := []string{
+		lipgloss.NewStyle().Foreground(compareDifferentColor).Render(fmt.Sprintf("~ %d different", cv.compare.DifferentValues)),
+		lipgloss.NewStyle().Foreground(compareOnlyCurrentColor).Render(fmt.Sprintf("◀ %d only in %s", cv.compare.OnlyInCurrent, filepath.Base(cv.current.Path))),
+		lipgloss.NewStyle().Foreground(compareOnlyOtherColor).Render(fmt.Sprintf("▶ %d only in %s", cv.compare.OnlyInOther, cv.otherName())),
+		styles.HelpDescStyle.Render(fmt.Sprintf("= %d matching", cv.compare.MatchingKeys)),
+	}
+	return " " + strings.Join(counts, styles.HelpSeparatorStyle.Render(" • "))
+}
+
+func (cv CompareView) renderDiff(diff model.FileDiff, colWidth int, selected bool) string {
+	var prefix string
+	var color lipgloss.Color
+	currentValue := cv.displayValue(diff.Key, diff.CurrentValue)
+	otherValue := cv.displayValue(diff.Key, diff.OtherValue)
+
+	switch {
+	case diff.OnlyInCurrent:
+		prefix, color = "◀", compareOnlyCurrentColor
+		otherValue = "(missing)"
+	case diff.OnlyInOther:
+		prefix, color = "▶", compareOnlyOtherColor
+		currentValue = "(missing)"
+	default:
+		prefix, color = "~", compareDifferentColor
+	}
+
+	marker := " "
+	switch cv.choices[diff.Key] {
+	case mergeCurrentWins:
+		marker = "«"
+	case mergeOtherWins:
+		marker = "»"
+	}
+
+	line := marker + " " + prefix + " " + padColumn(diff.Key, colWidth) + " " +
+		padColumn(currentValue, colWidth) + " " + padColumn(otherValue, colWidth)
+
+	style := lipgloss.NewStyle().Foreground(color)
+	if selected {
+		style = style.Background(lipgloss.Color("#374151")).Bold(true)
+	}
+	return style.Render(line)
+}
+
+// otherName names the right-hand side of the comparison
+func (cv CompareView) otherName() string {
+	if cv.environ != nil {
+		return model.EnvironName
+	}
+	return filepath.Base(cv.other.Path)
+}
+
+// displayValue masks secret values unless secrets are shown
+func (cv CompareView) displayValue(key, value string) string {
+	if value != "" && !cv.showSecrets && model.IsSecretKey(key) {
+		return model.MaskValue(value)
+	}
+	return value
+}
+
+func (cv CompareView) renderHelp() string {
+	if cv.environ != nil {
+		envOnly := "hide env-only"
+		if cv.hideEnvOnly {
+			envOnly = "show env-only"
+		}
+		helpItems := []string{
+			styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
+			styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
+			styles.HelpKeyStyle.Render("o") + " " + styles.HelpDescStyle.Render(envOnly),
+			styles.HelpKeyStyle.Render("x") + " " + styles.HelpDescStyle.Render("secrets"),
+			styles.HelpKeyStyle.Render("e") + " " + styles.HelpDescStyle.Render("export patch"),
+			styles.HelpKeyStyle.Render("Esc/q") + " " + styles.HelpDescStyle.Render("close"),
+		}
+		return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
+	}
+
+	helpItems := []string{
+		styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
+		styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
+		styles.HelpKeyStyle.Render("←/→") + " " + styles.HelpDescStyle.Render("pick side"),
+		styles.HelpKeyStyle.Render("A/B") + " " + styles.HelpDescStyle.Render("take all left/right"),
+		styles.HelpKeyStyle.Render("space") + " " + styles.HelpDescStyle.Render("unset"),
+		styles.HelpKeyStyle.Render("w") + " " + styles.HelpDescStyle.Render(fmt.Sprintf("merge (%d)", len(cv.choices))),
+		styles.HelpKeyStyle.Render("x") + " " + styles.HelpDescStyle.Render("secrets"),
+		styles.HelpKeyStyle.Render("e") + " " + styles.HelpDescStyle.Render("export patch"),
+		styles.HelpKeyStyle.Render("Esc/q") + " " + styles.HelpDescStyle.Render("close"),
+	}
+
+	return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
+}
+
+// padColumn truncates or pads s to exactly width display cells
+func padColumn(s string, width int) string {
+	s = strings.ReplaceAll(s, "\n", "↵")
+	if lipgloss.Width(s) > width {
+		runes := []rune(s)
+		for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+			runes = runes[:len(runes)-1]
+		}
+		return string(runes) + "…"
+	}
+	return s + strings.Repeat(" ", width-lipgloss.Width(s))
+}
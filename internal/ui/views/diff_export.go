@@ -0,0 +1,133 @@
+package views
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/envtui/envtui/internal/model"
+	"github.com/envtui/envtui/internal/ui/styles"
+)
+
+// ExportDiffMsg asks the app to write differences to Path as a patch (see ExportDiff)
+type ExportDiffMsg struct {
+	Diffs       []DiffEntry
+	Header      string // Written first as a comment, e.g. what was compared
+	Path        string
+	MaskSecrets bool
+}
+
+// ExportDiff writes differences as a readable patch, one line per key in key
+// order: "+ KEY=value" for an added key, "- KEY=value" for a removed one and
+// "~ KEY=old -> new" for a changed value. With maskSecrets, values of secret
+// keys are replaced with a placeholder, so the patch can be attached to a PR.
+func ExportDiff(diffs []DiffEntry, w io.Writer, maskSecrets bool) error {
+	sorted := append([]DiffEntry(nil), diffs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	value := func(key, value string) string {
+		if maskSecrets && value != "" && model.IsSecretKey(key) {
+			return model.MaskedPlaceholder
+		}
+		// Keep every difference on one line
+		if strings.ContainsAny(value, "\n\r") {
+			return strconv.Quote(value)
+		}
+		return value
+	}
+
+	for _, diff := range sorted {
+		var line string
+		switch diff.Type {
+		case DiffAdded:
+			line = fmt.Sprintf("+ %s=%s", diff.Key, value(diff.Key, diff.NewValue))
+		case DiffDeleted:
+			line = fmt.Sprintf("- %s=%s", diff.Key, value(diff.Key, diff.OldValue))
+		case DiffModified:
+			line = fmt.Sprintf("~ %s=%s -> %s", diff.Key, value(diff.Key, diff.OldValue), value(diff.Key, diff.NewValue))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompareDiffs turns a comparison into differences from the other file to the
+// current one: keys only in the current file are added, keys only in the other
+// removed
+func CompareDiffs(compare *model.EnvFileCompare) []DiffEntry {
+	var diffs []DiffEntry
+	for _, diff := range compare.Differences {
+		switch {
+		case diff.OnlyInCurrent:
+			diffs = append(diffs, DiffEntry{Key: diff.Key, NewValue: diff.CurrentValue, Type: DiffAdded})
+		case diff.OnlyInOther:
+			diffs = append(diffs, DiffEntry{Key: diff.Key, OldValue: diff.OtherValue, Type: DiffDeleted})
+		case diff.Different:
+			diffs = append(diffs, DiffEntry{Key: diff.Key, OldValue: diff.OtherValue, NewValue: diff.CurrentValue, Type: DiffModified})
+		}
+	}
+	return diffs
+}
+
+// patchPrompt asks where to write the differences shown by a view as a patch
+type patchPrompt struct {
+	input       textinput.Model
+	active      bool
+	realSecrets bool // Whether secret values are written as they are
+}
+
+// open starts asking for the path, suggesting defaultPath
+func (p *patchPrompt) open(defaultPath string) tea.Cmd {
+	p.input = textinput.New()
+	p.input.Placeholder = "patch file"
+	p.input.CharLimit = 0
+	p.input.SetValue(defaultPath)
+	p.input.CursorEnd()
+	p.active = true
+	p.realSecrets = false
+	return p.input.Focus()
+}
+
+// update handles a key while the prompt is open. On Enter it asks the app to
+// write diffs under header.
+func (p *patchPrompt) update(msg tea.KeyMsg, diffs []DiffEntry, header string) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		p.active = false
+		return nil
+	case "ctrl+x":
+		p.realSecrets = !p.realSecrets
+		return nil
+	case "enter":
+		path := strings.TrimSpace(p.input.Value())
+		if path == "" {
+			return nil
+		}
+		p.active = false
+		export := ExportDiffMsg{Diffs: diffs, Header: header, Path: path, MaskSecrets: !p.realSecrets}
+		return func() tea.Msg { return export }
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// view renders the path input and what happens to secrets
+func (p patchPrompt) view(count int) string {
+	secrets := "secrets masked"
+	if p.realSecrets {
+		secrets = "real secret values"
+	}
+	label := fmt.Sprintf("Export %d differences to: ", count)
+	box := styles.BorderStyle.Render(styles.HelpKeyStyle.Render(label) + p.input.View())
+	help := styles.HelpDescStyle.Render(fmt.Sprintf("Enter: write patch (%s)  •  ctrl+x: toggle secrets  •  Esc: cancel", secrets))
+	return box + "\n" + help
+}
@@ -2,8 +2,10 @@ package views
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/envtui/envtui/internal/model"
 	"github.com/envtui/envtui/internal/ui/styles"
@@ -15,6 +17,10 @@ type DiffView struct {
 	originalState *model.EnvFile
 	width         int
 	height        int
+	title         string // Title prefix, followed by the difference count
+	emptyMessage  string // Shown when there are no differences
+	exportable    bool   // Whether e exports the differences as a patch
+	patch         patchPrompt
 }
 
 // DiffEntry represents a single difference between current and original
@@ -38,15 +44,49 @@ func NewDiffView(current, original *model.EnvFile) DiffView {
 	return DiffView{
 		currentState:  current,
 		originalState: original,
+		title:         "Unsaved Changes",
+		emptyMessage:  "No unsaved changes - file is up to date",
 	}
 }
 
+// SetLabels overrides the title and the message shown when there are no differences
+func (dv *DiffView) SetLabels(title, emptyMessage string) {
+	dv.title = title
+	dv.emptyMessage = emptyMessage
+}
+
+// SetExportable lets e export the differences as a patch (see ExportDiff)
+func (dv *DiffView) SetExportable(exportable bool) {
+	dv.exportable = exportable
+}
+
 // SetSize sets the dimensions of the diff view
 func (dv *DiffView) SetSize(width, height int) {
 	dv.width = width
 	dv.height = height
 }
 
+// Update handles user input: e exports the differences as a patch
+func (dv DiffView) Update(msg tea.Msg) (DiffView, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return dv, nil
+	}
+	if dv.patch.active {
+		header := fmt.Sprintf("%s: %s", dv.title, filepath.Base(dv.currentState.Path))
+		return dv, dv.patch.update(keyMsg, dv.ComputeDifferences(), header)
+	}
+	if keyMsg.String() == "e" && dv.exportable && len(dv.ComputeDifferences()) > 0 {
+		return dv, dv.patch.open(dv.currentState.Path + ".diff")
+	}
+	return dv, nil
+}
+
+// CapturesInput reports whether keys go to the patch path prompt
+func (dv DiffView) CapturesInput() bool {
+	return dv.patch.active
+}
+
 // ComputeDifferences calculates the differences between current and original
 func (dv DiffView) ComputeDifferences() []DiffEntry {
 	var diffs []DiffEntry
@@ -115,13 +155,13 @@ func (dv DiffView) View() string {
 			Width(dv.width).
 			Height(dv.height).
 			Align(lipgloss.Center, lipgloss.Center).
-			Render("No unsaved changes - file is up to date")
+			Render(dv.emptyMessage)
 	}
 
 	var sections []string
 
 	// Title
-	title := styles.TitleStyle.Render(fmt.Sprintf("Unsaved Changes - %d differences", len(diffs)))
+	title := styles.TitleStyle.Render(fmt.Sprintf("%s - %d differences", dv.title, len(diffs)))
 	sections = append(sections, title)
 
 	// Subtitle with file info
@@ -141,8 +181,11 @@ func (dv DiffView) View() string {
 	sections = append(sections, listBox)
 
 	// Help
-	help := dv.renderHelp()
-	sections = append(sections, help)
+	if dv.patch.active {
+		sections = append(sections, dv.patch.view(len(diffs)))
+	} else {
+		sections = append(sections, dv.renderHelp())
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
@@ -182,10 +225,14 @@ func (dv DiffView) renderDiffEntry(diff DiffEntry) string {
 }
 
 func (dv DiffView) renderHelp() string {
-	helpItems := []string{
-		styles.HelpKeyStyle.Render("Esc") + " " + styles.HelpDescStyle.Render("close diff view"),
-		styles.HelpKeyStyle.Render("q") + " " + styles.HelpDescStyle.Render("quit"),
+	var helpItems []string
+	if dv.exportable {
+		helpItems = append(helpItems, styles.HelpKeyStyle.Render("e")+" "+styles.HelpDescStyle.Render("export patch"))
 	}
+	helpItems = append(helpItems,
+		styles.HelpKeyStyle.Render("Esc")+" "+styles.HelpDescStyle.Render("close diff view"),
+		styles.HelpKeyStyle.Render("q")+" "+styles.HelpDescStyle.Render("quit"),
+	)
 
 	return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
 }
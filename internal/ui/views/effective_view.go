@@ -0,0 +1,164 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/envtui/envtui/internal/model"
+	"github.com/envtui/envtui/internal/ui/styles"
+)
+
+// sourceBadgeColors color the source badge by the rank of the file it names
+var sourceBadgeColors = []lipgloss.Color{
+	lipgloss.Color("#22C55E"), // Green
+	lipgloss.Color("#3B82F6"), // Blue
+	lipgloss.Color("#F59E0B"), // Yellow/Orange
+	lipgloss.Color("#A855F7"), // Purple
+}
+
+// EffectiveView shows the value each key resolves to once all open files are
+// loaded in precedence order, and which file it comes from. It is read-only.
+type EffectiveView struct {
+	files       []*model.EnvFile // Highest priority first
+	resolved    map[string]model.ResolvedEntry
+	keys        []string // Sorted keys of resolved
+	selected    int
+	showSecrets bool
+	width       int
+	height      int
+}
+
+// NewEffectiveView resolves the given files using precedence (see model.ResolveEffective)
+func NewEffectiveView(files []*model.EnvFile, precedence []string) EffectiveView {
+	ev := EffectiveView{
+		files:    model.SortByPrecedence(files, precedence),
+		resolved: model.ResolveEffective(files, precedence),
+	}
+	for key := range ev.resolved {
+		ev.keys = append(ev.keys, key)
+	}
+	sort.Strings(ev.keys)
+	return ev
+}
+
+// SetSize sets the dimensions of the effective config view
+func (ev *EffectiveView) SetSize(width, height int) {
+	ev.width = width
+	ev.height = height
+}
+
+// Update handles user input
+func (ev EffectiveView) Update(msg tea.Msg) (EffectiveView, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "k":
+			if ev.selected > 0 {
+				ev.selected--
+			}
+		case "down", "j":
+			if ev.selected < len(ev.keys)-1 {
+				ev.selected++
+			}
+		case "x":
+			ev.showSecrets = !ev.showSecrets
+		}
+	}
+	return ev, nil
+}
+
+// View renders the effective config view
+func (ev EffectiveView) View() string {
+	if ev.width == 0 {
+		return "Loading..."
+	}
+
+	var sections []string
+
+	title := styles.TitleStyle.Render(fmt.Sprintf("Effective Config - %d keys", len(ev.keys)))
+	sections = append(sections, title)
+
+	var order []string
+	for _, file := range ev.files {
+		order = append(order, fileDisplayName(file.Path))
+	}
+	subtitle := styles.SubtitleStyle.Render("📁 " + strings.Join(order, " ▸ ") + " (highest priority first)")
+	sections = append(sections, subtitle)
+
+	listHeight := ev.height - 8
+	if listHeight < 5 {
+		listHeight = 5
+	}
+
+	var list string
+	if len(ev.keys) == 0 {
+		list = styles.HelpDescStyle.Render("No keys are set in the open files")
+	} else {
+		keyWidth := 0
+		for _, key := range ev.keys {
+			keyWidth = max(keyWidth, lipgloss.Width(key))
+		}
+		keyWidth = min(keyWidth, max(10, ev.width/3))
+
+		start := max(0, ev.selected-listHeight/2)
+		end := min(len(ev.keys), start+listHeight)
+
+		var items []string
+		for i := start; i < end; i++ {
+			items = append(items, ev.renderEntry(ev.resolved[ev.keys[i]], keyWidth, i == ev.selected))
+		}
+		list = strings.Join(items, "\n")
+	}
+
+	listBox := styles.BorderStyle.Width(ev.width - 4).Height(listHeight).Render(list)
+	sections = append(sections, listBox)
+	sections = append(sections, ev.renderHelp())
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (ev EffectiveView) renderEntry(entry model.ResolvedEntry, keyWidth int, selected bool) string {
+	style := styles.ListItemStyle
+	if selected {
+		style = styles.SelectedItemStyle
+	}
+
+	value := styles.ValueStyle.Render(padColumn(entry.Value, max(10, ev.width/3)))
+	if entry.IsSecret && !ev.showSecrets {
+		value = styles.SecretValueStyle.Render(padColumn(model.MaskValue(entry.Value), max(10, ev.width/3)))
+	}
+
+	content := styles.KeyStyle.Render(padColumn(entry.Key, keyWidth)) + " = " + value + " " + ev.sourceBadge(entry.Source)
+	if len(entry.Overridden) > 0 {
+		var names []string
+		for _, path := range entry.Overridden {
+			names = append(names, fileDisplayName(path))
+		}
+		content += styles.HelpDescStyle.Render(" overrides " + strings.Join(names, ", "))
+	}
+	return style.Width(ev.width - 6).Render(content)
+}
+
+// sourceBadge renders the name of the file a value comes from, colored by its rank
+func (ev EffectiveView) sourceBadge(path string) string {
+	color := sourceBadgeColors[len(sourceBadgeColors)-1]
+	for i, file := range ev.files {
+		if file.Path == path && i < len(sourceBadgeColors) {
+			color = sourceBadgeColors[i]
+		}
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#111827")).Background(color).Padding(0, 1).Render(fileDisplayName(path))
+}
+
+func (ev EffectiveView) renderHelp() string {
+	helpItems := []string{
+		styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
+		styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
+		styles.HelpKeyStyle.Render("x") + " " + styles.HelpDescStyle.Render("secrets"),
+		styles.HelpKeyStyle.Render("Esc/q") + " " + styles.HelpDescStyle.Render("close"),
+	}
+
+	return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
+}
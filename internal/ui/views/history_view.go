@@ -0,0 +1,141 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/envtui/envtui/internal/storage"
+	"github.com/envtui/envtui/internal/ui/styles"
+)
+
+// HistoryView displays the persisted change history of an env file
+type HistoryView struct {
+	records  []storage.HistoryRecord
+	filePath string
+	selected int
+	width    int
+	height   int
+}
+
+// NewHistoryView creates a new history view (records are shown newest first)
+func NewHistoryView(filePath string, records []storage.HistoryRecord) HistoryView {
+	reversed := make([]storage.HistoryRecord, len(records))
+	for i, record := range records {
+		reversed[len(records)-1-i] = record
+	}
+	return HistoryView{
+		records:  reversed,
+		filePath: filePath,
+	}
+}
+
+// SetSize sets the dimensions of the history view
+func (hv *HistoryView) SetSize(width, height int) {
+	hv.width = width
+	hv.height = height
+}
+
+// Update handles user input
+func (hv HistoryView) Update(msg tea.Msg) (HistoryView, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "k":
+			if hv.selected > 0 {
+				hv.selected--
+			}
+		case "down", "j":
+			if hv.selected < len(hv.records)-1 {
+				hv.selected++
+			}
+		}
+	}
+	return hv, nil
+}
+
+// View renders the history view
+func (hv HistoryView) View() string {
+	if hv.width == 0 {
+		return "Loading..."
+	}
+
+	if len(hv.records) == 0 {
+		return lipgloss.NewStyle().
+			Width(hv.width).
+			Height(hv.height).
+			Align(lipgloss.Center, lipgloss.Center).
+			Render("No recorded history for this file")
+	}
+
+	var sections []string
+
+	title := styles.TitleStyle.Render(fmt.Sprintf("Change History - %d changes", len(hv.records)))
+	sections = append(sections, title)
+
+	subtitle := styles.SubtitleStyle.Render(fmt.Sprintf("📁 %s", storage.HistoryPath(hv.filePath)))
+	sections = append(sections, subtitle)
+
+	listHeight := hv.height - 8
+	if listHeight < 5 {
+		listHeight = 5
+	}
+
+	start := max(0, hv.selected-listHeight/2)
+	end := min(len(hv.records), start+listHeight)
+
+	var items []string
+	for i := start; i < end; i++ {
+		items = append(items, hv.renderRecord(hv.records[i], i == hv.selected))
+	}
+
+	list := strings.Join(items, "\n")
+	listBox := styles.BorderStyle.Width(hv.width - 4).Height(listHeight).Render(list)
+	sections = append(sections, listBox)
+
+	sections = append(sections, hv.renderHelp())
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (hv HistoryView) renderRecord(record storage.HistoryRecord, selected bool) string {
+	style := styles.ListItemStyle
+	if selected {
+		style = styles.SelectedItemStyle
+	}
+
+	timeStr := record.Timestamp.Format("Jan 02 15:04:05")
+	keyStr := styles.KeyStyle.Render(record.Key)
+
+	var content string
+	switch record.Type {
+	case "add":
+		content = fmt.Sprintf("%s  + %s = %s", timeStr, keyStr, record.NewValue)
+	case "update":
+		content = fmt.Sprintf("%s  ~ %s: %s → %s", timeStr, keyStr, record.OldValue, record.NewValue)
+	case "delete":
+		content = fmt.Sprintf("%s  - %s = %s", timeStr, keyStr, record.OldValue)
+	case "rename":
+		content = fmt.Sprintf("%s  ↻ %s → %s", timeStr, record.OldValue, keyStr)
+	case "export":
+		action := "export"
+		if record.NewValue != "true" {
+			action = "unexport"
+		}
+		content = fmt.Sprintf("%s  ⇪ %s %s", timeStr, action, keyStr)
+	default:
+		content = fmt.Sprintf("%s  %s %s", timeStr, record.Type, keyStr)
+	}
+
+	return style.Width(hv.width - 6).Render(content)
+}
+
+func (hv HistoryView) renderHelp() string {
+	helpItems := []string{
+		styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
+		styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
+		styles.HelpKeyStyle.Render("Esc/q") + " " + styles.HelpDescStyle.Render("close"),
+	}
+
+	return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
+}
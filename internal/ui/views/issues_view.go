@@ -0,0 +1,141 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/envtui/envtui/internal/model"
+	"github.com/envtui/envtui/internal/ui/styles"
+)
+
+// JumpToIssueMsg asks the app to select the entry an issue refers to
+type JumpToIssueMsg struct {
+	Issue model.ValidationIssue
+}
+
+// IssuesView lists the validation issues of an env file
+type IssuesView struct {
+	issues   []model.ValidationIssue
+	filePath string
+	selected int
+	width    int
+	height   int
+}
+
+// NewIssuesView creates a view of the given validation issues
+func NewIssuesView(filePath string, issues []model.ValidationIssue) IssuesView {
+	return IssuesView{
+		issues:   issues,
+		filePath: filePath,
+	}
+}
+
+// SetSize sets the dimensions of the issues view
+func (iv *IssuesView) SetSize(width, height int) {
+	iv.width = width
+	iv.height = height
+}
+
+// Update handles user input
+func (iv IssuesView) Update(msg tea.Msg) (IssuesView, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "k":
+			if iv.selected > 0 {
+				iv.selected--
+			}
+		case "down", "j":
+			if iv.selected < len(iv.issues)-1 {
+				iv.selected++
+			}
+		case "enter":
+			if iv.selected < len(iv.issues) {
+				jump := JumpToIssueMsg{Issue: iv.issues[iv.selected]}
+				return iv, func() tea.Msg { return jump }
+			}
+		}
+	}
+	return iv, nil
+}
+
+// View renders the issues view
+func (iv IssuesView) View() string {
+	if iv.width == 0 {
+		return "Loading..."
+	}
+
+	if len(iv.issues) == 0 {
+		return lipgloss.NewStyle().
+			Width(iv.width).
+			Height(iv.height).
+			Align(lipgloss.Center, lipgloss.Center).
+			Render("No validation issues in this file")
+	}
+
+	var sections []string
+
+	title := styles.TitleStyle.Render(fmt.Sprintf("Validation Issues - %d found", len(iv.issues)))
+	sections = append(sections, title)
+
+	subtitle := styles.SubtitleStyle.Render(fmt.Sprintf("📁 %s", fileDisplayName(iv.filePath)))
+	sections = append(sections, subtitle)
+
+	listHeight := iv.height - 8
+	if listHeight < 5 {
+		listHeight = 5
+	}
+
+	start := max(0, iv.selected-listHeight/2)
+	end := min(len(iv.issues), start+listHeight)
+
+	var items []string
+	for i := start; i < end; i++ {
+		items = append(items, iv.renderIssue(iv.issues[i], i == iv.selected))
+	}
+
+	list := strings.Join(items, "\n")
+	listBox := styles.BorderStyle.Width(iv.width - 4).Height(listHeight).Render(list)
+	sections = append(sections, listBox)
+
+	sections = append(sections, iv.renderHelp())
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (iv IssuesView) renderIssue(issue model.ValidationIssue, selected bool) string {
+	style := styles.ListItemStyle
+	if selected {
+		style = styles.SelectedItemStyle
+	}
+
+	var level string
+	switch issue.Level {
+	case model.ValidationError:
+		level = lipgloss.NewStyle().Foreground(styles.Danger).Bold(true).Render("error  ")
+	case model.ValidationWarning:
+		level = lipgloss.NewStyle().Foreground(styles.Warning).Bold(true).Render("warning")
+	default:
+		level = lipgloss.NewStyle().Foreground(styles.Info).Render("info   ")
+	}
+
+	line := "     -"
+	if issue.Line > 0 {
+		line = fmt.Sprintf("%6d", issue.Line)
+	}
+
+	content := fmt.Sprintf("%s  %s  %s", styles.HelpDescStyle.Render("line"+line), level, issue.Message)
+	return style.Width(iv.width - 6).Render(content)
+}
+
+func (iv IssuesView) renderHelp() string {
+	helpItems := []string{
+		styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
+		styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
+		styles.HelpKeyStyle.Render("enter") + " " + styles.HelpDescStyle.Render("go to entry"),
+		styles.HelpKeyStyle.Render("Esc/q") + " " + styles.HelpDescStyle.Render("close"),
+	}
+
+	return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
+}
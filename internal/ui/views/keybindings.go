@@ -0,0 +1,187 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// KeyBindings maps action names (see KeyActions) to the keys that trigger them
+type KeyBindings map[string][]string
+
+// keyActions are the remappable list view actions, in the order they are
+// listed and checked
+var keyActions = []struct {
+	name    string
+	binding *key.Binding
+}{
+	{"up", &keys.Up},
+	{"down", &keys.Down},
+	{"search", &keys.Search},
+	{"jump", &keys.Jump},
+	{"add", &keys.Add},
+	{"edit", &keys.Edit},
+	{"rename", &keys.Rename},
+	{"duplicate", &keys.Duplicate},
+	{"delete", &keys.Delete},
+	{"toggle-secrets", &keys.Toggle},
+	{"partial-secrets", &keys.Partial},
+	{"toggle-export", &keys.ToggleExport},
+	{"read-only", &keys.ReadOnly},
+	{"peek", &keys.Reveal},
+	{"clipboard", &keys.Clipboard},
+	{"clipboard-file", &keys.ClipboardFile},
+	{"copy", &keys.Copy},
+	{"undo", &keys.Undo},
+	{"redo", &keys.Redo},
+	{"view-diff", &keys.ViewDiff},
+	{"disk-diff", &keys.DiskDiff},
+	{"sort", &keys.Sort},
+	{"sort-file", &keys.SortFile},
+	{"dedupe", &keys.Dedupe},
+	{"compare", &keys.Diff},
+	{"compare-view", &keys.Compare},
+	{"effective", &keys.Effective},
+	{"files", &keys.Files},
+	{"open-file", &keys.OpenFile},
+	{"close-file", &keys.CloseFile},
+	{"next-file", &keys.NextFile},
+	{"prev-file", &keys.PrevFile},
+	{"select", &keys.ToggleSelect},
+	{"bulk-delete", &keys.BulkDelete},
+	{"bulk-replace", &keys.BulkEdit},
+	{"backups", &keys.Backup},
+	{"backup-now", &keys.BackupNow},
+	{"export", &keys.Export},
+	{"history", &keys.History},
+	{"issues", &keys.Issues},
+	{"next-issue", &keys.NextIssue},
+	{"prev-issue", &keys.PrevIssue},
+	{"summary", &keys.Stats},
+	{"details", &keys.Details},
+	{"comments", &keys.Structure},
+	{"git-commit", &keys.GitCommit},
+	{"gitignore", &keys.GitIgnore},
+	{"quit", &keys.Quit},
+}
+
+// defaultKeys are the built-in bindings, restored before applying custom ones
+var defaultKeys = keys
+
+// KeyActions returns the names of the actions that can be remapped
+func KeyActions() []string {
+	names := make([]string, len(keyActions))
+	for i, action := range keyActions {
+		names[i] = action.name
+	}
+	return names
+}
+
+// SetKeyBindings replaces the keys of the given actions, keeping the defaults of
+// the others. A key bound to two actions is reported as a conflict and leaves the
+// defaults in place. Passing nil restores the built-in bindings.
+func SetKeyBindings(bindings KeyBindings) error {
+	keys = defaultKeys
+
+	known := make(map[string]*key.Binding, len(keyActions))
+	for _, action := range keyActions {
+		known[action.name] = action.binding
+	}
+	for name, bound := range bindings {
+		binding, ok := known[name]
+		if !ok {
+			keys = defaultKeys
+			return fmt.Errorf("unknown action %q", name)
+		}
+		if len(bound) == 0 {
+			keys = defaultKeys
+			return fmt.Errorf("no keys given for %s", name)
+		}
+		*binding = key.NewBinding(
+			key.WithKeys(bound...),
+			key.WithHelp(helpKeys(bound), binding.Help().Desc),
+		)
+	}
+
+	owners := make(map[string]string)
+	for _, action := range keyActions {
+		for _, k := range action.binding.Keys() {
+			if owner, ok := owners[k]; ok && (bindings[owner] != nil || bindings[action.name] != nil) {
+				keys = defaultKeys
+				return fmt.Errorf("key %q is bound to both %s and %s", k, owner, action.name)
+			}
+			owners[k] = action.name
+		}
+	}
+	return nil
+}
+
+// ParseKeyBindings parses "action = key[, key...]" lines, e.g. "down = n, down".
+// Use "space" for the space bar. Blank lines and lines starting with # are ignored.
+func ParseKeyBindings(content string) (KeyBindings, error) {
+	bindings := make(KeyBindings)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: expected action = key[, key...]", i+1)
+		}
+		if _, dup := bindings[name]; dup {
+			return nil, fmt.Errorf("line %d: %s is bound more than once", i+1, name)
+		}
+		var bound []string
+		for _, k := range strings.Split(rest, ",") {
+			k = strings.TrimSpace(k)
+			if k == "" {
+				continue
+			}
+			if k == "space" {
+				k = " "
+			}
+			bound = append(bound, k)
+		}
+		if len(bound) == 0 {
+			return nil, fmt.Errorf("line %d: no keys given for %s", i+1, name)
+		}
+		bindings[name] = bound
+	}
+	return bindings, nil
+}
+
+// ReadOnlyKey returns the key that locks and unlocks the current file, for messages
+func ReadOnlyKey() string {
+	return keys.ReadOnly.Help().Key
+}
+
+// KeyAction returns the name of the action bound to the key, or "" if none is
+func KeyAction(msg tea.KeyMsg) string {
+	for _, action := range keyActions {
+		if key.Matches(msg, *action.binding) {
+			return action.name
+		}
+	}
+	return ""
+}
+
+// helpKeys renders keys for the help bar, e.g. "↑/k"
+func helpKeys(bound []string) string {
+	names := make([]string, len(bound))
+	for i, k := range bound {
+		switch k {
+		case " ":
+			k = "space"
+		case "up":
+			k = "↑"
+		case "down":
+			k = "↓"
+		}
+		names[i] = k
+	}
+	return strings.Join(names, "/")
+}
@@ -5,7 +5,9 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,25 +17,146 @@ import (
 	"github.com/envtui/envtui/internal/ui/styles"
 )
 
-// Bulk delete message
+// BulkDeleteMsg deletes the given keys from the current file. Both single and
+// bulk deletes send it once the user confirmed (or confirmation is disabled).
 type BulkDeleteMsg struct {
 	Keys []string
 }
 
+// BulkUpdateMsg replaces Find with Replace in the values of the given keys.
+// An empty Find sets every value to Replace.
+type BulkUpdateMsg struct {
+	Keys    []string
+	Find    string
+	Replace string
+}
+
+// GitIgnoreMsg asks the app to add the current file to .gitignore
+type GitIgnoreMsg struct{}
+
+// ExportEntriesMsg asks the app to export the given entries to Path
+type ExportEntriesMsg struct {
+	Entries []*model.Entry
+	Path    string
+}
+
+// OpenFileMsg asks the app to open another file, or the files a directory or
+// glob names
+type OpenFileMsg struct {
+	Path string
+}
+
+// CloseFileMsg asks the app to close the current file
+type CloseFileMsg struct{}
+
+// ToggleExportMsg asks the app to flip whether the key is exported and save the file
+type ToggleExportMsg struct {
+	Key string
+}
+
+// BulkExportToggleMsg asks the app to mark the given keys as exported, or as not
+// exported, in one undoable step and save the file
+type BulkExportToggleMsg struct {
+	Keys     []string
+	Exported bool
+}
+
+// DedupeMsg asks the app to remove repeated keys from the current file
+type DedupeMsg struct {
+	Strategy model.DedupStrategy
+}
+
+// SortFileMsg asks the app to sort the current file's keys alphabetically and save it
+type SortFileMsg struct{}
+
+// BackupNowMsg asks the app to back up the current file right away
+type BackupNowMsg struct{}
+
+// GitCommitMsg asks the app to commit the current file with the given message
+type GitCommitMsg struct {
+	Message string
+}
+
 // Copy entry message
 type CopyEntryMsg struct {
 	Entry       *model.Entry
 	TargetIndex int
 }
 
+// FilePurpose is what the file chosen in the file picker is used for
+type FilePurpose int
+
+const (
+	FileSwitch     FilePurpose = iota // Make it the current file
+	FileCopyTarget                    // Copy the selected entry into it
+	FileCompare                       // Compare the current file with it
+)
+
+// FilePickedMsg reports the file chosen in the file picker
+type FilePickedMsg struct {
+	Index   int
+	Purpose FilePurpose
+}
+
+// StatusTimeoutMsg clears the transient status message it was scheduled for
+type StatusTimeoutMsg struct {
+	ID int
+}
+
+// RevealTimeoutMsg hides the secret revealed by the reveal it was scheduled for
+type RevealTimeoutMsg struct {
+	ID int
+}
+
+// JumpTimeoutMsg ends jump mode if no key was typed since it was scheduled
+type JumpTimeoutMsg struct {
+	ID int
+}
+
+const (
+	// statusDuration is how long a transient status message stays visible
+	statusDuration = 3 * time.Second
+	// revealDuration is how long a single revealed secret stays visible
+	revealDuration = 5 * time.Second
+	// jumpTimeout is how long jump mode waits for the next typed character
+	jumpTimeout = 1500 * time.Millisecond
+	// previewPrefix and previewSuffix are the characters of a secret left visible
+	// by the partial reveal, e.g. "ghp_••••••••3f2a"
+	previewPrefix = 4
+	previewSuffix = 4
+)
+
+// bulkPromptStep is the current step of the bulk find-and-replace prompt
+type bulkPromptStep int
+
+const (
+	bulkPromptNone bulkPromptStep = iota
+	bulkPromptFind
+	bulkPromptReplace
+)
+
+// fileClipboardStep is the current question of copying the whole file to the clipboard
+type fileClipboardStep int
+
+const (
+	fileClipboardNone    fileClipboardStep = iota
+	fileClipboardFormat                    // export-prefixed or plain lines
+	fileClipboardSecrets                   // real, masked or left-out secret values
+)
+
 type SortMode int
 
 const (
 	SortModeAlphabetical SortMode = iota
 	SortModeByCategory
 	SortModeByValueLength
+	SortModeByRecentlyChanged
+	SortModeEmptyFirst
 )
 
+// emptyValueLabel stands in for an empty value, e.g. a placeholder still to fill in
+const emptyValueLabel = "(empty)"
+
 type ListView struct {
 	entries         []*model.Entry
 	filteredEntries []*model.Entry
@@ -49,25 +172,101 @@ type ListView struct {
 	selectedItems   map[string]bool // Track multi-selected items
 	bulkMode        bool            // Whether in bulk selection mode
 	sortMode        SortMode
+	sorted          bool // Whether the user picked a sort; until then the list keeps file order
+	changeStack     *model.ChangeStack
 	copyMode        bool // Whether in copy mode (selecting target file)
+	copyOverwrite   bool // Whether copying replaces the value of a key the target already has
 	copyTargetIndex int  // Target file index for copy operation
+	compareMode     bool // Whether selecting a file to compare against
+	filePicker      bool // Whether choosing from the list of all open files
+	pickerIndex     int
+	showStructure   bool              // Whether comments and blank lines are shown inline
+	clipboardPrompt bool              // Whether asking to copy the real or masked secret
+	fileClipboard   fileClipboardStep // Question asked while copying the whole file
+	fileClipExport  bool              // Copy the file as export statements rather than plain lines
+	statusMessage   string
+	statusIsError   bool
+	statusID        int
+	revealedKey     string // Secret shown in plain text until the selection moves
+	revealID        int
+	bulkPrompt      bulkPromptStep
+	bulkFind        string
+	bulkInput       textinput.Model
+	commitPrompt    bool // Whether asking for a git commit message
+	commitInput     textinput.Model
+	issueLevels     map[string]model.ValidationLevel // Most severe validation issue per key
+	issueMessages   map[string]string                // Message of that issue, shown when stepping to the key
+	issueErrors     int
+	issueWarnings   int
+	jumpMode        bool // Whether typed characters jump to a key prefix
+	jumpBuffer      string
+	jumpID          int
+	deletePrompt    []string                // Keys waiting for a delete confirmation
+	dedupePrompt    []string                // Repeated keys waiting for a dedupe strategy
+	confirmDelete   bool                    // Whether deletes ask for confirmation first
+	comments        map[*model.Entry]string // Documentation comments, searched when searchComments is on
+	inherited       map[*model.Entry]string // Entries from included files, with the path of each
+	searchComments  bool
+	matchCase       bool // Search matches letter case exactly (ctrl+s while searching)
+	exportPrompt    bool // Whether asking for the path to export the visible entries to
+	exportInput     textinput.Model
+	openPrompt      bool // Whether asking for the path of a file to open
+	openInput       textinput.Model
+	showStats       bool // Whether the file summary panel is shown
+	showDetails     bool // Whether the selected entry's detail panel is shown
+	partialReveal   bool // Whether secrets show their first and last characters
 }
 
 type keyMap struct {
 	Up             key.Binding
 	Down           key.Binding
 	Search         key.Binding
+	Add            key.Binding
+	Edit           key.Binding
+	Rename         key.Binding
+	Duplicate      key.Binding
+	Delete         key.Binding
 	Toggle         key.Binding
+	Partial        key.Binding
+	ToggleExport   key.Binding
+	ReadOnly       key.Binding
+	Dedupe         key.Binding
 	Diff           key.Binding
+	ViewDiff       key.Binding
+	DiskDiff       key.Binding
+	History        key.Binding
+	Effective      key.Binding
+	Files          key.Binding
+	OpenFile       key.Binding
+	CloseFile      key.Binding
+	NextFile       key.Binding
+	PrevFile       key.Binding
+	Stats          key.Binding
+	Details        key.Binding
 	Undo           key.Binding
 	Redo           key.Binding
 	ToggleSelect   key.Binding
 	BulkDelete     key.Binding
+	BulkEdit       key.Binding
+	GitCommit      key.Binding
+	GitIgnore      key.Binding
+	Issues         key.Binding
+	NextIssue      key.Binding
+	PrevIssue      key.Binding
+	Jump           key.Binding
 	ClearSelection key.Binding
 	Sort           key.Binding
+	SortFile       key.Binding
 	Copy           key.Binding
+	Compare        key.Binding
 	Template       key.Binding
 	Backup         key.Binding
+	BackupNow      key.Binding
+	Export         key.Binding
+	Structure      key.Binding
+	Clipboard      key.Binding
+	ClipboardFile  key.Binding
+	Reveal         key.Binding
 	Quit           key.Binding
 	Enter          key.Binding
 	Escape         key.Binding
@@ -86,14 +285,94 @@ var keys = keyMap{
 		key.WithKeys("/"),
 		key.WithHelp("/", "search"),
 	),
+	Add: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "rename"),
+	),
+	Duplicate: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "duplicate"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete"),
+	),
 	Toggle: key.NewBinding(
 		key.WithKeys("x"),
 		key.WithHelp("x", "toggle secrets"),
 	),
+	Partial: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "partial secrets"),
+	),
+	ToggleExport: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("ctrl+e", "toggle export"),
+	),
+	ReadOnly: key.NewBinding(
+		key.WithKeys("ctrl+l"),
+		key.WithHelp("ctrl+l", "lock file"),
+	),
+	Dedupe: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "remove duplicate keys"),
+	),
 	Diff: key.NewBinding(
 		key.WithKeys("c"),
 		key.WithHelp("c", "compare files"),
 	),
+	ViewDiff: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "view unsaved changes"),
+	),
+	DiskDiff: key.NewBinding(
+		key.WithKeys("V"),
+		key.WithHelp("V", "diff against disk"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "history"),
+	),
+	Effective: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "effective config"),
+	),
+	Files: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "pick from all files"),
+	),
+	OpenFile: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open file"),
+	),
+	CloseFile: key.NewBinding(
+		key.WithKeys("ctrl+w"),
+		key.WithHelp("ctrl+w", "close file"),
+	),
+	NextFile: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next file"),
+	),
+	PrevFile: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous file"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "file summary"),
+	),
+	Details: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "entry details"),
+	),
 	Undo: key.NewBinding(
 		key.WithKeys("u"),
 		key.WithHelp("u", "undo"),
@@ -110,6 +389,34 @@ var keys = keyMap{
 		key.WithKeys("D"),
 		key.WithHelp("D", "bulk delete"),
 	),
+	BulkEdit: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "bulk replace"),
+	),
+	GitCommit: key.NewBinding(
+		key.WithKeys("G"),
+		key.WithHelp("G", "git commit file"),
+	),
+	GitIgnore: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "add file to .gitignore"),
+	),
+	Issues: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "validation issues"),
+	),
+	NextIssue: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next issue"),
+	),
+	PrevIssue: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous issue"),
+	),
+	Jump: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "jump to key"),
+	),
 	ClearSelection: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "clear selection"),
@@ -118,10 +425,18 @@ var keys = keyMap{
 		key.WithKeys("s"),
 		key.WithHelp("s", "sort mode"),
 	),
+	SortFile: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "sort file on disk"),
+	),
 	Copy: key.NewBinding(
 		key.WithKeys("y"),
 		key.WithHelp("y", "copy to file"),
 	),
+	Compare: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "compare with file"),
+	),
 	Template: key.NewBinding(
 		key.WithKeys("t"),
 		key.WithHelp("t", "templates"),
@@ -130,6 +445,30 @@ var keys = keyMap{
 		key.WithKeys("b"),
 		key.WithHelp("b", "backups"),
 	),
+	BackupNow: key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "back up now"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "export visible or selected entries"),
+	),
+	Structure: key.NewBinding(
+		key.WithKeys("#"),
+		key.WithHelp("#", "show comments"),
+	),
+	Clipboard: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "copy value to clipboard"),
+	),
+	ClipboardFile: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "copy file to clipboard"),
+	),
+	Reveal: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "peek at secret"),
+	),
 	Enter: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "edit"),
@@ -149,11 +488,31 @@ func NewListView(entries []*model.Entry) ListView {
 	ti.Placeholder = "Search entries..."
 	ti.CharLimit = 50
 
+	bi := textinput.New()
+	bi.CharLimit = 0
+
+	ci := textinput.New()
+	ci.Placeholder = "commit message"
+	ci.CharLimit = 200
+
+	ei := textinput.New()
+	ei.Placeholder = "output file (.json, .yaml, .toml or .env)"
+	ei.CharLimit = 0
+
+	oi := textinput.New()
+	oi.Placeholder = "path, directory or glob"
+	oi.CharLimit = 0
+
 	lv := ListView{
 		entries:         entries,
 		filteredEntries: entries,
 		searchInput:     ti,
 		selectedItems:   make(map[string]bool),
+		bulkInput:       bi,
+		commitInput:     ci,
+		exportInput:     ei,
+		openInput:       oi,
+		confirmDelete:   true,
 	}
 
 	return lv
@@ -172,7 +531,88 @@ func (lv ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 		lv.height = msg.Height
 		lv.searchInput.Width = msg.Width - 4
 
+	case StatusTimeoutMsg:
+		if msg.ID == lv.statusID {
+			lv.statusMessage = ""
+		}
+		return lv, nil
+
+	case RevealTimeoutMsg:
+		if msg.ID == lv.revealID {
+			lv.revealedKey = ""
+		}
+		return lv, nil
+
+	case JumpTimeoutMsg:
+		if msg.ID == lv.jumpID {
+			lv.jumpMode = false
+			lv.jumpBuffer = ""
+		}
+		return lv, nil
+
 	case tea.KeyMsg:
+		if lv.filePicker {
+			return lv.updateFilePicker(msg)
+		}
+
+		// Handle secret clipboard prompt (real or masked value)
+		if lv.clipboardPrompt {
+			selected := lv.GetSelected()
+			switch msg.String() {
+			case "esc", "q":
+				lv.clipboardPrompt = false
+				return lv, nil
+			case "r":
+				lv.clipboardPrompt = false
+				if selected != nil {
+					return lv, lv.copyToClipboard(selected.Key, selected.Value, "value")
+				}
+			case "m":
+				lv.clipboardPrompt = false
+				if selected != nil {
+					return lv, lv.copyToClipboard(selected.Key, selected.DisplayValue(), "masked value")
+				}
+			}
+			return lv, nil
+		}
+
+		// Handle the questions of copying the whole file
+		if lv.fileClipboard != fileClipboardNone {
+			return lv.updateFileClipboard(msg)
+		}
+
+		// Handle the dedupe strategy question
+		if len(lv.dedupePrompt) > 0 {
+			strategy := model.DedupKeepFirst
+			switch msg.String() {
+			case "f":
+			case "l":
+				strategy = model.DedupKeepLast
+			case "v":
+				strategy = model.DedupKeepLastValue
+			case "esc", "q", "n":
+				lv.dedupePrompt = nil
+				return lv, nil
+			default:
+				return lv, nil
+			}
+			lv.dedupePrompt = nil
+			return lv, func() tea.Msg { return DedupeMsg{Strategy: strategy} }
+		}
+
+		// Handle delete confirmation
+		if len(lv.deletePrompt) > 0 {
+			keys := lv.deletePrompt
+			switch msg.String() {
+			case "y", "Y":
+				lv.deletePrompt = nil
+				return lv, func() tea.Msg { return BulkDeleteMsg{Keys: keys} }
+			case "n", "N", "esc", "q":
+				lv.deletePrompt = nil
+			}
+			return lv, nil
+		}
+
 		// Handle copy mode (file picker for copying entries)
 		if lv.copyMode {
 			switch msg.String() {
@@ -190,6 +630,82 @@ func (lv ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 			return lv, nil
 		}
 
+		// Handle compare mode (file picker; the app opens the compare view)
+		if lv.compareMode {
+			if msg.String() == "esc" || msg.String() == "q" {
+				lv.compareMode = false
+			}
+			return lv, nil
+		}
+
+		// Handle type-ahead jumping
+		if lv.jumpMode {
+			return lv.updateJump(msg)
+		}
+
+		// Handle bulk find-and-replace prompt
+		if lv.bulkPrompt != bulkPromptNone {
+			return lv.updateBulkPrompt(msg)
+		}
+
+		// Handle git commit message prompt
+		if lv.commitPrompt {
+			switch msg.String() {
+			case "esc":
+				lv.commitPrompt = false
+				lv.commitInput.Blur()
+				return lv, nil
+			case "enter":
+				commit := GitCommitMsg{Message: lv.commitInput.Value()}
+				lv.commitPrompt = false
+				lv.commitInput.Blur()
+				return lv, func() tea.Msg { return commit }
+			}
+			lv.commitInput, cmd = lv.commitInput.Update(msg)
+			return lv, cmd
+		}
+
+		// Handle export path prompt
+		if lv.exportPrompt {
+			switch msg.String() {
+			case "esc":
+				lv.exportPrompt = false
+				lv.exportInput.Blur()
+				return lv, nil
+			case "enter":
+				path := strings.TrimSpace(lv.exportInput.Value())
+				if path == "" {
+					return lv, nil
+				}
+				export := ExportEntriesMsg{Entries: lv.exportEntries(), Path: path}
+				lv.exportPrompt = false
+				lv.exportInput.Blur()
+				return lv, func() tea.Msg { return export }
+			}
+			lv.exportInput, cmd = lv.exportInput.Update(msg)
+			return lv, cmd
+		}
+
+		// Handle open file prompt
+		if lv.openPrompt {
+			switch msg.String() {
+			case "esc":
+				lv.openPrompt = false
+				lv.openInput.Blur()
+				return lv, nil
+			case "enter":
+				path := strings.TrimSpace(lv.openInput.Value())
+				if path == "" {
+					return lv, nil
+				}
+				lv.openPrompt = false
+				lv.openInput.Blur()
+				return lv, func() tea.Msg { return OpenFileMsg{Path: path} }
+			}
+			lv.openInput, cmd = lv.openInput.Update(msg)
+			return lv, cmd
+		}
+
 		if lv.searching {
 			switch {
 			case key.Matches(msg, keys.Escape):
@@ -200,10 +716,21 @@ func (lv ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 			case key.Matches(msg, keys.Enter):
 				lv.searching = false
 				return lv, nil
+			case msg.String() == "tab":
+				lv.searchComments = !lv.searchComments
+				lv.filterEntries(lv.searchInput.Value())
+				lv.selected = 0
+				return lv, nil
+			case msg.String() == "ctrl+s":
+				lv.matchCase = !lv.matchCase
+				lv.filterEntries(lv.searchInput.Value())
+				lv.selected = 0
+				return lv, nil
 			default:
 				lv.searchInput, cmd = lv.searchInput.Update(msg)
 				lv.filterEntries(lv.searchInput.Value())
 				lv.selected = 0
+				lv.revealedKey = ""
 				return lv, cmd
 			}
 		}
@@ -214,10 +741,21 @@ func (lv ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 		case key.Matches(msg, keys.Up):
 			if lv.selected > 0 {
 				lv.selected--
+				lv.revealedKey = ""
 			}
 		case key.Matches(msg, keys.Down):
 			if lv.selected < len(lv.filteredEntries)-1 {
 				lv.selected++
+				lv.revealedKey = ""
+			}
+		case key.Matches(msg, keys.Reveal):
+			if selected := lv.GetSelected(); selected != nil && selected.IsSecret {
+				lv.revealID++
+				lv.revealedKey = selected.Key
+				id := lv.revealID
+				return lv, tea.Tick(revealDuration, func(time.Time) tea.Msg {
+					return RevealTimeoutMsg{ID: id}
+				})
 			}
 		case key.Matches(msg, keys.Search):
 			lv.searching = true
@@ -225,8 +763,18 @@ func (lv ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 			return lv, textinput.Blink
 		case key.Matches(msg, keys.Toggle):
 			lv.showSecrets = !lv.showSecrets
+		case key.Matches(msg, keys.Partial):
+			lv.partialReveal = !lv.partialReveal
+			if lv.partialReveal {
+				return lv, lv.setStatus(fmt.Sprintf("Secrets show their first %d and last %d characters", previewPrefix, previewSuffix), false)
+			}
+			return lv, lv.setStatus("Secrets are fully masked", false)
 		case key.Matches(msg, keys.Diff):
 			lv.ToggleDiffs()
+		case key.Matches(msg, keys.NextIssue):
+			return lv, lv.stepToIssue(1)
+		case key.Matches(msg, keys.PrevIssue):
+			return lv, lv.stepToIssue(-1)
 		case key.Matches(msg, keys.ToggleSelect):
 			// Toggle selection of current item
 			if lv.selected >= 0 && lv.selected < len(lv.filteredEntries) {
@@ -243,44 +791,292 @@ func (lv ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 			for k := range lv.selectedItems {
 				keys = append(keys, k)
 			}
-			return lv, tea.Batch(cmd, func() tea.Msg {
-				return BulkDeleteMsg{Keys: keys}
-			})
+			sort.Strings(keys)
+			return lv, lv.RequestDelete(keys)
+		case key.Matches(msg, keys.BulkEdit):
+			if len(lv.selectedItems) > 0 {
+				lv.bulkPrompt = bulkPromptFind
+				lv.bulkFind = ""
+				lv.bulkInput.SetValue("")
+				lv.bulkInput.Placeholder = "text to find (leave empty to set a common value)"
+				lv.bulkInput.Focus()
+				return lv, textinput.Blink
+			}
+		case key.Matches(msg, keys.GitCommit):
+			lv.commitPrompt = true
+			lv.commitInput.SetValue("")
+			lv.commitInput.Focus()
+			return lv, textinput.Blink
+		case key.Matches(msg, keys.GitIgnore):
+			return lv, func() tea.Msg { return GitIgnoreMsg{} }
+		case key.Matches(msg, keys.SortFile):
+			return lv, func() tea.Msg { return SortFileMsg{} }
+		case key.Matches(msg, keys.ToggleExport):
+			if len(lv.selectedItems) > 0 {
+				return lv, lv.bulkToggleExport()
+			}
+			if selected := lv.GetSelected(); selected != nil {
+				return lv, func() tea.Msg { return ToggleExportMsg{Key: selected.Key} }
+			}
+		case key.Matches(msg, keys.BackupNow):
+			return lv, func() tea.Msg { return BackupNowMsg{} }
+		case key.Matches(msg, keys.Export):
+			if len(lv.exportEntries()) == 0 {
+				return lv, lv.setStatus("Nothing to export", true)
+			}
+			lv.exportPrompt = true
+			lv.exportInput.SetValue("")
+			lv.exportInput.Focus()
+			return lv, textinput.Blink
+		case key.Matches(msg, keys.OpenFile):
+			lv.openPrompt = true
+			lv.openInput.SetValue("")
+			lv.openInput.Focus()
+			return lv, textinput.Blink
+		case key.Matches(msg, keys.CloseFile):
+			return lv, func() tea.Msg { return CloseFileMsg{} }
+		case key.Matches(msg, keys.Jump):
+			lv.jumpMode = true
+			lv.jumpBuffer = ""
+			return lv, lv.scheduleJumpTimeout()
 		case key.Matches(msg, keys.ClearSelection):
 			lv.selectedItems = make(map[string]bool)
 			lv.bulkMode = false
 		case key.Matches(msg, keys.Sort):
+			// Sorted order has no file structure to show
+			lv.showStructure = false
 			lv.cycleSortMode()
+		case key.Matches(msg, keys.Stats):
+			lv.showStats = !lv.showStats
+		case key.Matches(msg, keys.Details):
+			lv.showDetails = !lv.showDetails
+		case key.Matches(msg, keys.Structure):
+			lv.showStructure = !lv.showStructure
+			if lv.showStructure {
+				// Back to file order so comments line up with their keys
+				selected := lv.GetSelected()
+				lv.filterEntries(lv.searchInput.Value())
+				lv.selectEntry(selected)
+			}
+		case key.Matches(msg, keys.Clipboard):
+			selected := lv.GetSelected()
+			switch {
+			case selected == nil:
+				return lv, lv.setStatus("No entry selected", true)
+			case selected.Value == "":
+				return lv, lv.setStatus(fmt.Sprintf("%s has an empty value, nothing copied", selected.Key), true)
+			case selected.IsSecret:
+				lv.clipboardPrompt = true
+				return lv, nil
+			}
+			return lv, lv.copyToClipboard(selected.Key, selected.Value, "value")
+		case key.Matches(msg, keys.ClipboardFile):
+			if len(lv.entries) == 0 {
+				return lv, lv.setStatus("No entries to copy", true)
+			}
+			lv.fileClipboard = fileClipboardFormat
+			return lv, nil
 		case key.Matches(msg, keys.Copy):
 			// Debug: log the copy key detection
 			if len(lv.envFiles) > 1 && lv.selected >= 0 && lv.selected < len(lv.filteredEntries) {
 				lv.copyMode = true
 				lv.copyTargetIndex = -1
+				lv.copyOverwrite = false
 				return lv, nil
 			}
+		case key.Matches(msg, keys.Compare):
+			// Even a single file can be compared with the environment
+			lv.compareMode = true
+			return lv, nil
 		}
 	}
 
 	return lv, cmd
 }
 
-func (lv *ListView) filterEntries(query string) {
-	if query == "" {
-		lv.filteredEntries = lv.entries
-		return
+// updateBulkPrompt handles keys while the bulk find-and-replace prompt is open
+func (lv ListView) updateBulkPrompt(msg tea.KeyMsg) (ListView, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		lv.bulkPrompt = bulkPromptNone
+		lv.bulkInput.Blur()
+		return lv, nil
+	case "enter":
+		if lv.bulkPrompt == bulkPromptFind {
+			lv.bulkFind = lv.bulkInput.Value()
+			lv.bulkPrompt = bulkPromptReplace
+			lv.bulkInput.SetValue("")
+			lv.bulkInput.Placeholder = "replacement"
+			return lv, nil
+		}
+
+		keys := lv.GetSelectedItems()
+		sort.Strings(keys)
+		update := BulkUpdateMsg{Keys: keys, Find: lv.bulkFind, Replace: lv.bulkInput.Value()}
+		lv.bulkPrompt = bulkPromptNone
+		lv.bulkInput.Blur()
+		return lv, func() tea.Msg { return update }
+	}
+
+	var cmd tea.Cmd
+	lv.bulkInput, cmd = lv.bulkInput.Update(msg)
+	return lv, cmd
+}
+
+// updateJump handles keys in jump mode: typed characters extend the key prefix
+// and move the selection to the first entry whose key starts with it
+func (lv ListView) updateJump(msg tea.KeyMsg) (ListView, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyRunes:
+		lv.jumpBuffer += string(msg.Runes)
+	case tea.KeyBackspace:
+		if lv.jumpBuffer == "" {
+			return lv, lv.scheduleJumpTimeout()
+		}
+		runes := []rune(lv.jumpBuffer)
+		lv.jumpBuffer = string(runes[:len(runes)-1])
+	default:
+		// Esc, Enter or any other key leaves jump mode at the current entry
+		lv.jumpMode = false
+		lv.jumpBuffer = ""
+		return lv, nil
+	}
+
+	prefix := strings.ToUpper(lv.jumpBuffer)
+	for i, entry := range lv.filteredEntries {
+		if strings.HasPrefix(strings.ToUpper(entry.Key), prefix) {
+			lv.selected = i
+			lv.revealedKey = ""
+			break
+		}
+	}
+	return lv, lv.scheduleJumpTimeout()
+}
+
+// scheduleJumpTimeout restarts the countdown that ends jump mode
+func (lv *ListView) scheduleJumpTimeout() tea.Cmd {
+	lv.jumpID++
+	id := lv.jumpID
+	return tea.Tick(jumpTimeout, func(time.Time) tea.Msg {
+		return JumpTimeoutMsg{ID: id}
+	})
+}
+
+// jumpMatches reports whether any listed key starts with the jump buffer
+func (lv ListView) jumpMatches() bool {
+	prefix := strings.ToUpper(lv.jumpBuffer)
+	for _, entry := range lv.filteredEntries {
+		if strings.HasPrefix(strings.ToUpper(entry.Key), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteClipboard puts text on the system clipboard. Tests swap it out, as the
+// sandbox they run in may have no clipboard.
+var WriteClipboard = clipboard.WriteAll
+
+// copyToClipboard writes text to the system clipboard and reports the outcome
+func (lv *ListView) copyToClipboard(key, text, what string) tea.Cmd {
+	if err := WriteClipboard(text); err != nil {
+		// No clipboard utility (e.g. over SSH) - report instead of failing
+		return lv.setStatus(fmt.Sprintf("Clipboard unavailable: %v", err), true)
+	}
+	return lv.setStatus(fmt.Sprintf("Copied %s %s to clipboard", key, what), false)
+}
+
+// updateFileClipboard answers the questions of copying the whole file: export
+// statements or plain lines, then, if the file has secrets, how to copy them
+func (lv ListView) updateFileClipboard(msg tea.KeyMsg) (ListView, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		lv.fileClipboard = fileClipboardNone
+		return lv, nil
+	}
+
+	if lv.fileClipboard == fileClipboardFormat {
+		switch msg.String() {
+		case "e":
+			lv.fileClipExport = true
+		case "p":
+			lv.fileClipExport = false
+		default:
+			return lv, nil
+		}
+		if lv.countSecrets() > 0 {
+			lv.fileClipboard = fileClipboardSecrets
+			return lv, nil
+		}
+		lv.fileClipboard = fileClipboardNone
+		return lv, lv.copyFileToClipboard(storage.ExportOptions{})
 	}
 
-	query = strings.ToLower(query)
-	var filtered []*model.Entry
+	var opts storage.ExportOptions
+	switch msg.String() {
+	case "r":
+	case "m":
+		opts.MaskSecrets = true
+	case "s":
+		opts.RedactSecrets = true
+	default:
+		return lv, nil
+	}
+	lv.fileClipboard = fileClipboardNone
+	return lv, lv.copyFileToClipboard(opts)
+}
 
+// countSecrets returns how many entries of the current file are secrets
+func (lv ListView) countSecrets() int {
+	count := 0
 	for _, entry := range lv.entries {
-		if strings.Contains(strings.ToLower(entry.Key), query) ||
-			strings.Contains(strings.ToLower(entry.Value), query) {
-			filtered = append(filtered, entry)
+		if entry.IsSecret {
+			count++
 		}
 	}
+	return count
+}
+
+// copyFileToClipboard copies the current file as shell lines (see
+// storage.ExportToShellWithOptions), ready to paste into a remote shell
+func (lv *ListView) copyFileToClipboard(opts storage.ExportOptions) tea.Cmd {
+	if lv.currentIndex < 0 || lv.currentIndex >= len(lv.envFiles) {
+		return nil
+	}
+	envFile := lv.envFiles[lv.currentIndex]
+	format, what := "", "lines"
+	if lv.fileClipExport {
+		format, what = "export", "export statements"
+	}
+	if err := WriteClipboard(storage.ExportToShellWithOptions(envFile, format, opts)); err != nil {
+		return lv.setStatus(fmt.Sprintf("Clipboard unavailable: %v", err), true)
+	}
+	return lv.setStatus(fmt.Sprintf("Copied %s to clipboard as %s", filepath.Base(envFile.Path), what), false)
+}
+
+// setStatus shows a transient status message and schedules its removal
+func (lv *ListView) setStatus(message string, isError bool) tea.Cmd {
+	lv.statusID++
+	lv.statusMessage = message
+	lv.statusIsError = isError
+	id := lv.statusID
+	return tea.Tick(statusDuration, func(time.Time) tea.Msg {
+		return StatusTimeoutMsg{ID: id}
+	})
+}
+
+// filterEntries shows the entries fuzzy-matching query, best match first
+func (lv *ListView) filterEntries(query string) {
+	var comments map[*model.Entry]string
+	if lv.searchComments {
+		comments = lv.comments
+	}
+	lv.filteredEntries = model.RankEntries(lv.entries, query, comments, lv.matchCase)
+}
 
-	lv.filteredEntries = filtered
+// SetComments sets the documentation comments of the entries for comment search
+func (lv *ListView) SetComments(comments map[*model.Entry]string) {
+	lv.comments = comments
 }
 
 func (lv ListView) View() string {
@@ -312,8 +1108,12 @@ func (lv *ListView) ViewWithFiles(envFiles []*model.EnvFile, currentIndex int, g
 			MarginRight(1)
 		tabs = append(tabs, labelStyle.Render("FILES:"))
 
+		var fileTabs []string
 		for i, ef := range envFiles {
-			tabName := filepath.Base(ef.Path)
+			tabName := fileDisplayName(ef.Path)
+			if ef.ReadOnly {
+				tabName = "🔒" + tabName
+			}
 			entryCount := len(ef.FilterEntries(""))
 
 			// Add git status icon if available
@@ -332,7 +1132,7 @@ func (lv *ListView) ViewWithFiles(envFiles []*model.EnvFile, currentIndex int, g
 					Border(lipgloss.RoundedBorder()).
 					BorderForeground(lipgloss.Color("#C084FC")).
 					MarginRight(1)
-				tabs = append(tabs, activeTabStyle.
+				fileTabs = append(fileTabs, activeTabStyle.
 					Render(fmt.Sprintf(" ▶ %d:%s%s (%d) ", i+1, tabName, gitIndicator, entryCount)))
 			} else {
 				// Inactive tab - darker but still visible
@@ -343,15 +1143,22 @@ func (lv *ListView) ViewWithFiles(envFiles []*model.EnvFile, currentIndex int, g
 					Border(lipgloss.RoundedBorder()).
 					BorderForeground(lipgloss.Color("#4B5563")).
 					MarginRight(1)
-				tabs = append(tabs, inactiveTabStyle.
+				fileTabs = append(fileTabs, inactiveTabStyle.
 					Render(fmt.Sprintf(" %d:%s%s (%d) ", i+1, tabName, gitIndicator, entryCount)))
 			}
 		}
+		tabs = append(tabs, pageTabs(fileTabs, currentIndex, lv.width-lipgloss.Width(tabs[0]))...)
 		tabsRow := lipgloss.JoinHorizontal(lipgloss.Left, tabs...)
 
 		// File indicator showing current file info
 		currentFile := envFiles[currentIndex]
-		fileInfo := fmt.Sprintf("📁 %s (%d entries)", filepath.Base(currentFile.Path), len(currentFile.FilterEntries("")))
+		fileInfo := fmt.Sprintf("📁 %s (%d entries)%s", fileDisplayName(currentFile.Path), len(currentFile.FilterEntries("")), lv.positionCounter())
+		if storage.IsNewFile(currentFile) {
+			fileInfo += " ✚ new file - created on first save"
+		}
+		if currentFile.ReadOnly {
+			fileInfo += " 🔒 read-only"
+		}
 
 		// Add git branch info if available
 		if currentIndex < len(gitInfos) && gitInfos[currentIndex].Branch != "" {
@@ -363,17 +1170,59 @@ func (lv *ListView) ViewWithFiles(envFiles []*model.EnvFile, currentIndex int, g
 		header = lipgloss.JoinVertical(lipgloss.Left, title, tabsRow, subtitle)
 	} else {
 		title := styles.TitleStyle.Render("EnvTUI")
-		subtitle := styles.SubtitleStyle.Render(fmt.Sprintf("%d entries", len(lv.entries)))
+		subtitle := styles.SubtitleStyle.Render(fmt.Sprintf("%d entries%s", len(lv.entries), lv.positionCounter()))
+		if currentIndex >= 0 && currentIndex < len(envFiles) && storage.IsNewFile(envFiles[currentIndex]) {
+			subtitle = styles.SubtitleStyle.Render(fmt.Sprintf("%d entries ✚ new file - created on first save", len(lv.entries)))
+		}
 
 		// Add git status for single file
 		if len(gitInfos) > 0 && gitInfos[0].Status != storage.GitStatusNone {
-			subtitle = styles.SubtitleStyle.Render(fmt.Sprintf("%d entries %s", len(lv.entries), storage.FormatGitStatusForTab(gitInfos[0].Status)))
+			subtitle = styles.SubtitleStyle.Render(fmt.Sprintf("%d entries%s %s", len(lv.entries), lv.positionCounter(), storage.FormatGitStatusForTab(gitInfos[0].Status)))
+		}
+		if currentIndex >= 0 && currentIndex < len(envFiles) && envFiles[currentIndex].ReadOnly {
+			subtitle = lipgloss.JoinHorizontal(lipgloss.Left, subtitle, styles.SubtitleStyle.Render("🔒 read-only"))
 		}
 
 		header = lipgloss.JoinHorizontal(lipgloss.Left, title, subtitle)
 	}
 	sections = append(sections, header)
 
+	// File summary panel
+	statsPanel := ""
+	if lv.showStats && currentIndex >= 0 && currentIndex < len(envFiles) {
+		statsPanel = renderStats(envFiles[currentIndex].Stats())
+		sections = append(sections, statsPanel)
+	}
+
+	// Example/template file banner
+	exampleBanner := ""
+	if currentIndex >= 0 && currentIndex < len(envFiles) && envFiles[currentIndex].IsExample() {
+		exampleBanner = lv.renderExampleBanner(envFiles[currentIndex])
+		sections = append(sections, exampleBanner)
+	}
+
+	// Secrets in a file git would commit
+	gitBanner := ""
+	if currentIndex >= 0 && currentIndex < len(envFiles) && currentIndex < len(gitInfos) {
+		gitBanner = lv.renderGitignoreBanner(envFiles[currentIndex], gitInfos[currentIndex])
+		if gitBanner != "" {
+			sections = append(sections, gitBanner)
+		}
+	}
+
+	// Input read from stdin cannot be saved back
+	stdinBanner := ""
+	if currentIndex >= 0 && currentIndex < len(envFiles) && envFiles[currentIndex].Path == storage.StdinPath {
+		stdinBanner = lipgloss.NewStyle().
+			Background(styles.Warning).
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true).
+			Padding(0, 2).
+			Width(lv.width - 4).
+			Render(" ⚠ READ FROM STDIN: changes are kept in memory only and cannot be saved ")
+		sections = append(sections, stdinBanner)
+	}
+
 	// Copy mode banner
 	if lv.copyMode {
 		copyBanner := lipgloss.NewStyle().
@@ -382,49 +1231,213 @@ func (lv *ListView) ViewWithFiles(envFiles []*model.EnvFile, currentIndex int, g
 			Bold(true).
 			Padding(0, 2).
 			Width(lv.width - 4).
-			Render(" 📋 COPY MODE: Select target file (1-9) or Esc to cancel ")
+			Render(fmt.Sprintf(" 📋 COPY MODE (%s): Select target file (1-9, %s for all), a to copy to every file, o to switch mode, or Esc to cancel ", lv.copyModeName(), keys.Files.Help().Key))
 		sections = append(sections, copyBanner)
 	}
 
-	// Search input
-	if lv.searching {
-		searchBox := styles.BorderStyle.Render(lv.searchInput.View())
-		sections = append(sections, searchBox)
+	// Compare mode banner
+	if lv.compareMode {
+		compareBanner := lipgloss.NewStyle().
+			Background(styles.Info).
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true).
+			Padding(0, 2).
+			Width(lv.width - 4).
+			Render(fmt.Sprintf(" ⇄ COMPARE: Select file to compare with (1-9, %s for all), e for the environment, or Esc to cancel ", keys.Files.Help().Key))
+		sections = append(sections, compareBanner)
 	}
 
-	// Entries list - calculate available height
-	// Account for: header (3 rows) + help (5 rows) + padding (2) = 10 minimum
-	listHeight := lv.height - 10
-	if lv.searching {
-		listHeight -= 3
-	}
-	// Adjust for tabs if shown (tabs take 2 extra rows)
-	if len(envFiles) > 1 {
-		listHeight -= 3
-	}
-	// Adjust for copy mode banner
-	if lv.copyMode {
-		listHeight -= 1
-	}
-	// Ensure minimum height
-	if listHeight < 5 {
-		listHeight = 5
+	// Whole-file clipboard banner
+	if lv.fileClipboard != fileClipboardNone {
+		question := " 📋 Copy the file as e=export KEY=value, p=plain KEY=value, Esc=cancel "
+		if lv.fileClipboard == fileClipboardSecrets {
+			question = fmt.Sprintf(" 🔒 The file has %d %s: r=real values, m=masked, s=leave out, Esc=cancel ",
+				lv.countSecrets(), plural(lv.countSecrets(), "secret", "secrets"))
+		}
+		fileClipBanner := lipgloss.NewStyle().
+			Background(styles.Warning).
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true).
+			Padding(0, 2).
+			Width(lv.width - 4).
+			Render(question)
+		sections = append(sections, fileClipBanner)
+	}
+
+	// Clipboard prompt banner
+	if lv.clipboardPrompt {
+		if selected := lv.GetSelected(); selected != nil {
+			promptBanner := lipgloss.NewStyle().
+				Background(styles.Warning).
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Bold(true).
+				Padding(0, 2).
+				Width(lv.width - 4).
+				Render(fmt.Sprintf(" 🔒 %s is a secret: copy r=real value, m=masked value, Esc=cancel ", selected.Key))
+			sections = append(sections, promptBanner)
+		}
+	}
+
+	// Dedupe strategy banner
+	if len(lv.dedupePrompt) > 0 {
+		dedupeBanner := lipgloss.NewStyle().
+			Background(styles.Warning).
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true).
+			Padding(0, 2).
+			Width(lv.width - 4).
+			Render(fmt.Sprintf(" Repeated keys %s: f=keep first, l=keep last, v=first position with last value, Esc=cancel ", describeKeys(lv.dedupePrompt)))
+		sections = append(sections, dedupeBanner)
+	}
+
+	// Delete confirmation banner
+	if len(lv.deletePrompt) > 0 {
+		deleteBanner := lipgloss.NewStyle().
+			Background(styles.Danger).
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true).
+			Padding(0, 2).
+			Width(lv.width - 4).
+			Render(fmt.Sprintf(" 🗑 Delete %s? [y/N] ", describeKeys(lv.deletePrompt)))
+		sections = append(sections, deleteBanner)
+	}
+
+	// Search input
+	if lv.searching {
+		caseIndicator := styles.HelpDescStyle.Render("  Aa ignore case")
+		if lv.matchCase {
+			caseIndicator = styles.HelpKeyStyle.Render("  Aa match case")
+		}
+		searchBox := styles.BorderStyle.Render(lv.searchInput.View() + caseIndicator)
+		sections = append(sections, searchBox)
+	}
+
+	// Git commit message input
+	if lv.commitPrompt {
+		commitBox := styles.BorderStyle.Render(styles.HelpKeyStyle.Render("Commit message: ") + lv.commitInput.View())
+		sections = append(sections, commitBox)
+	}
+
+	// Export path input
+	if lv.exportPrompt {
+		label := fmt.Sprintf("Export %d entries to: ", len(lv.exportEntries()))
+		exportBox := styles.BorderStyle.Render(styles.HelpKeyStyle.Render(label) + lv.exportInput.View())
+		sections = append(sections, exportBox)
+	}
+
+	// Open file path input
+	if lv.openPrompt {
+		openBox := styles.BorderStyle.Render(styles.HelpKeyStyle.Render("Open: ") + lv.openInput.View())
+		sections = append(sections, openBox)
+	}
+
+	// Bulk find-and-replace input
+	if lv.bulkPrompt != bulkPromptNone {
+		label := fmt.Sprintf("Find in %d values: ", len(lv.selectedItems))
+		if lv.bulkPrompt == bulkPromptReplace {
+			label = fmt.Sprintf("Replace %q with: ", lv.bulkFind)
+			if lv.bulkFind == "" {
+				label = fmt.Sprintf("Set %d values to: ", len(lv.selectedItems))
+			}
+		}
+		bulkBox := styles.BorderStyle.Render(styles.HelpKeyStyle.Render(label) + lv.bulkInput.View())
+		sections = append(sections, bulkBox)
+	}
+
+	issuesFooter := lv.renderIssuesFooter()
+
+	// Selected entry detail panel
+	detailsPanel := ""
+	if lv.showDetails && currentIndex >= 0 && currentIndex < len(envFiles) {
+		if selected := lv.GetSelected(); selected != nil {
+			detailsPanel = renderDetails(selected, envFiles[currentIndex], time.Now())
+		}
+	}
+
+	// Entries list - calculate available height
+	// Account for: header (3 rows) + help (5 rows) + padding (2) = 10 minimum
+	listHeight := lv.height - 10
+	if lv.searching {
+		listHeight -= 3
+	}
+	if lv.bulkPrompt != bulkPromptNone || lv.commitPrompt || lv.exportPrompt || lv.openPrompt {
+		listHeight -= 3
+	}
+	// Adjust for tabs if shown (tabs take 2 extra rows)
+	if len(envFiles) > 1 {
+		listHeight -= 3
+	}
+	// Adjust for copy/compare mode banners
+	if lv.copyMode || lv.compareMode {
+		listHeight -= 1
+	}
+	// Adjust for clipboard prompt banner and status line
+	if lv.clipboardPrompt || lv.fileClipboard != fileClipboardNone || len(lv.deletePrompt) > 0 || len(lv.dedupePrompt) > 0 {
+		listHeight -= 1
+	}
+	if lv.statusMessage != "" {
+		listHeight -= 1
+	}
+	if statsPanel != "" {
+		listHeight -= lipgloss.Height(statsPanel)
+	}
+	if detailsPanel != "" {
+		listHeight -= lipgloss.Height(detailsPanel)
+	}
+	// Adjust for example file banner
+	if exampleBanner != "" {
+		listHeight -= lipgloss.Height(exampleBanner)
+	}
+	if gitBanner != "" {
+		listHeight -= lipgloss.Height(gitBanner)
+	}
+	if stdinBanner != "" {
+		listHeight -= lipgloss.Height(stdinBanner)
+	}
+	if issuesFooter != "" {
+		listHeight -= lipgloss.Height(issuesFooter)
+	}
+	// Ensure minimum height
+	if listHeight < 5 {
+		listHeight = 5
 	}
 
 	var items []string
-	start := max(0, lv.selected-listHeight/2)
-	end := min(len(lv.filteredEntries), start+listHeight)
+	if lv.filePicker {
+		items = lv.renderFilePicker(listHeight)
+	} else if lv.showStructure {
+		items = lv.renderStructureRows(listHeight)
+	} else {
+		start, end := scrollWindow(lv.selected, len(lv.filteredEntries), listHeight)
 
-	for i := start; i < end; i++ {
-		entry := lv.filteredEntries[i]
-		item := lv.renderEntry(entry, i == lv.selected)
-		items = append(items, item)
+		for i := start; i < end; i++ {
+			entry := lv.filteredEntries[i]
+			item := lv.renderEntry(entry, i == lv.selected, lv.searchInput.Value())
+			items = append(items, item)
+		}
+		items = addScrollIndicators(items, start, len(lv.filteredEntries)-end)
 	}
 
 	list := strings.Join(items, "\n")
 	listBox := styles.BorderStyle.Width(lv.width - 4).Height(listHeight).Render(list)
 	sections = append(sections, listBox)
 
+	if detailsPanel != "" {
+		sections = append(sections, detailsPanel)
+	}
+	if issuesFooter != "" {
+		sections = append(sections, issuesFooter)
+	}
+
+	// Transient status message
+	if lv.statusMessage != "" {
+		color := styles.Secondary
+		if lv.statusIsError {
+			color = styles.Danger
+		}
+		sections = append(sections, lipgloss.NewStyle().Foreground(color).Padding(0, 1).Render(lv.statusMessage))
+	}
+
 	// Help
 	help := lv.renderHelpWithFiles(len(envFiles) > 1)
 	sections = append(sections, help)
@@ -432,7 +1445,118 @@ func (lv *ListView) ViewWithFiles(envFiles []*model.EnvFile, currentIndex int, g
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
-func (lv ListView) renderEntry(entry *model.Entry, selected bool) string {
+// renderExampleBanner renders the reminder shown when editing an example/template file,
+// escalating to a warning when values in it look like real secrets
+func (lv ListView) renderExampleBanner(envFile *model.EnvFile) string {
+	suspects := envFile.SuspectedExampleSecrets()
+	if len(suspects) == 0 {
+		return lipgloss.NewStyle().
+			Foreground(styles.Warning).
+			Padding(0, 1).
+			Render("ℹ Editing an example/template file - use placeholders, not real secrets")
+	}
+
+	var keys []string
+	for _, entry := range suspects {
+		keys = append(keys, entry.Key)
+	}
+	return lipgloss.NewStyle().
+		Background(styles.Danger).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Bold(true).
+		Padding(0, 2).
+		Width(lv.width - 4).
+		Render(fmt.Sprintf(" ⚠ EXAMPLE FILE CONTAINS REAL-LOOKING SECRETS: %s ", strings.Join(keys, ", ")))
+}
+
+// pageTabs returns the run of tabs around the current one that fits in width,
+// with "‹ N" and "N more ›" markers for the tabs left out, so any number of
+// open files keeps the tab bar on one row
+func pageTabs(tabs []string, current, width int) []string {
+	if width <= 0 || current < 0 || current >= len(tabs) {
+		return tabs
+	}
+	total := 0
+	for _, tab := range tabs {
+		total += lipgloss.Width(tab)
+	}
+	if total <= width {
+		return tabs
+	}
+
+	// Grow the page from the current tab, alternating right and left
+	markerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF")).Bold(true).Padding(1, 1)
+	markerWidth := lipgloss.Width(markerStyle.Render(fmt.Sprintf("%d more ›", len(tabs))))
+	start, end := current, current+1
+	used := lipgloss.Width(tabs[current]) + 2*markerWidth
+	for grown := true; grown; {
+		grown = false
+		if end < len(tabs) && used+lipgloss.Width(tabs[end]) <= width {
+			used += lipgloss.Width(tabs[end])
+			end++
+			grown = true
+		}
+		if start > 0 && used+lipgloss.Width(tabs[start-1]) <= width {
+			start--
+			used += lipgloss.Width(tabs[start])
+			grown = true
+		}
+	}
+
+	var page []string
+	if start > 0 {
+		page = append(page, markerStyle.Render(fmt.Sprintf("‹ %d", start)))
+	}
+	page = append(page, tabs[start:end]...)
+	if end < len(tabs) {
+		page = append(page, markerStyle.Render(fmt.Sprintf("%d more ›", len(tabs)-end)))
+	}
+	return page
+}
+
+// fileDisplayName returns the name shown for a file in tabs and headers
+func fileDisplayName(path string) string {
+	if path == storage.StdinPath {
+		return "(stdin)"
+	}
+	return filepath.Base(path)
+}
+
+// renderGitignoreBanner warns when a file holding secrets is in a git repository
+// without being ignored, so it is (or could easily be) committed
+func (lv ListView) renderGitignoreBanner(envFile *model.EnvFile, gitInfo storage.FileGitInfo) string {
+	if gitInfo.Status == storage.GitStatusNone || gitInfo.Ignored || envFile.IsExample() {
+		return ""
+	}
+
+	hasSecrets := false
+	for _, entry := range envFile.Entries {
+		if entry.Type == model.KeyValueEntry && entry.IsSecret {
+			hasSecrets = true
+			break
+		}
+	}
+	if !hasSecrets {
+		return ""
+	}
+
+	name := filepath.Base(envFile.Path)
+	message := fmt.Sprintf(" ⚠ %s holds secrets and is not gitignored - press I to add it to .gitignore ", name)
+	if gitInfo.Status != storage.GitStatusUntracked {
+		message = fmt.Sprintf(" ⚠ %s holds secrets and is tracked by git - press I to gitignore it, then run git rm --cached %s ", name, name)
+	}
+	return lipgloss.NewStyle().
+		Background(styles.Danger).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Bold(true).
+		Padding(0, 2).
+		Width(lv.width - 4).
+		Render(message)
+}
+
+// renderEntry renders one key/value row, highlighting the characters that matched
+// the search query
+func (lv ListView) renderEntry(entry *model.Entry, selected bool, query string) string {
 	style := styles.ListItemStyle
 	if selected {
 		style = styles.SelectedItemStyle
@@ -450,8 +1574,29 @@ func (lv ListView) renderEntry(entry *model.Entry, selected bool) string {
 	categoryColor := styles.CategoryColor(entry.Category())
 	indicator := lipgloss.NewStyle().Foreground(categoryColor).Render("●")
 
-	// Key with diff indicator
-	keyStr := styles.KeyStyle.Render(entry.Key)
+	// Key with diff indicator; the key's matched characters are highlighted, or the
+	// value's when only the value matched
+	_, keyMatches, keyMatched := model.FuzzyMatch(entry.Key, query, lv.matchCase)
+	keyStr := highlightMatches(entry.Key, keyMatches, styles.KeyStyle)
+
+	// Exported keys get an "export " prefix in the file and in shell exports
+	if entry.Exported {
+		keyStr += " " + lipgloss.NewStyle().Foreground(styles.Info).Bold(true).Render("E")
+	}
+	// Inherited keys name the included file they come from
+	if source := lv.inherited[entry]; source != "" {
+		keyStr += " " + styles.CommentStyle.Render("↳ "+filepath.Base(source))
+	}
+
+	// Validation marker
+	issueMarker := " "
+	if level, ok := lv.issueLevels[entry.Key]; ok {
+		color := styles.Warning
+		if level == model.ValidationError {
+			color = styles.Danger
+		}
+		issueMarker = lipgloss.NewStyle().Foreground(color).Bold(true).Render("!")
+	}
 
 	// Check for differences with other files
 	diffIndicator := ""
@@ -459,17 +1604,227 @@ func (lv ListView) renderEntry(entry *model.Entry, selected bool) string {
 		diffIndicator = lv.getDiffIndicator(entry)
 	}
 
-	// Value
-	value := entry.Value
-	if entry.IsSecret && !lv.showSecrets {
-		value = entry.DisplayValue()
+	// Value (never highlighted while masked)
+	var valueStr string
+	_, valueMatches, valueMatched := model.FuzzyMatch(entry.Value, query, lv.matchCase)
+	if entry.Value == "" {
+		// Shown even for secrets: an unset secret reveals nothing
+		valueStr = styles.CommentStyle.Render(emptyValueLabel)
+	} else if entry.IsSecret && !lv.showSecrets && entry.Key != lv.revealedKey {
+		masked := entry.DisplayValue()
+		if lv.partialReveal {
+			masked = entry.MaskedPreview(previewPrefix, previewSuffix)
+		}
+		valueStr = styles.ValueStyle.Render(masked)
+	} else if valueMatched && !keyMatched {
+		valueStr = highlightMatches(entry.Value, valueMatches, styles.ValueStyle)
+	} else {
+		valueStr = styles.ValueStyle.Render(entry.Value)
 	}
-	valueStr := styles.ValueStyle.Render(value)
 
-	content := fmt.Sprintf("%s%s %s%s = %s", checkmark, indicator, keyStr, diffIndicator, valueStr)
+	content := fmt.Sprintf("%s%s%s %s%s = %s", checkmark, indicator, issueMarker, keyStr, diffIndicator, valueStr)
+
+	// Show the comment when only it matched, so it is clear why the entry is listed
+	if query != "" && lv.searchComments && !keyMatched && !valueMatched {
+		comment := lv.comments[entry]
+		if positions, ok := model.SubstringMatch(comment, query, lv.matchCase); ok {
+			content += "  " + styles.HelpDescStyle.Render("# ") + highlightMatches(comment, positions, styles.HelpDescStyle)
+		}
+	}
 	return style.Width(lv.width - 6).Render(content)
 }
 
+// highlightMatches renders text in base style with the runes at positions in the
+// match highlight style
+func highlightMatches(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+	highlight := styles.MatchHighlightStyle.Inherit(base)
+
+	var sb strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			sb.WriteString(highlight.Render(string(run)))
+		} else {
+			sb.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return sb.String()
+}
+
+// renderIssuesFooter summarizes the validation errors and warnings of the file
+func (lv ListView) renderIssuesFooter() string {
+	if lv.issueErrors == 0 && lv.issueWarnings == 0 {
+		return ""
+	}
+
+	var parts []string
+	if lv.issueErrors > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(styles.Danger).Bold(true).
+			Render(fmt.Sprintf("✗ %d %s", lv.issueErrors, plural(lv.issueErrors, "error", "errors"))))
+	}
+	if lv.issueWarnings > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(styles.Warning).Bold(true).
+			Render(fmt.Sprintf("⚠ %d %s", lv.issueWarnings, plural(lv.issueWarnings, "warning", "warnings"))))
+	}
+	parts = append(parts, styles.HelpDescStyle.Render(fmt.Sprintf("press %s to review, %s/%s to step through",
+		keys.Issues.Help().Key, keys.NextIssue.Help().Key, keys.PrevIssue.Help().Key)))
+	return " " + strings.Join(parts, styles.HelpSeparatorStyle.Render(" • "))
+}
+
+// plural returns singular when n is 1 and pluralForm otherwise
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
+
+// renderStructureRows renders the file's entries in order, including comments and
+// blank lines, keeping the selected key entry in view
+func (lv ListView) renderStructureRows(listHeight int) []string {
+	if lv.currentIndex < 0 || lv.currentIndex >= len(lv.envFiles) {
+		return nil
+	}
+
+	visible := make(map[*model.Entry]bool, len(lv.filteredEntries))
+	for _, entry := range lv.filteredEntries {
+		visible[entry] = true
+	}
+	selected := lv.GetSelected()
+
+	var rows []string
+	selectedRow := 0
+	for _, entry := range lv.envFiles[lv.currentIndex].Entries {
+		switch entry.Type {
+		case model.KeyValueEntry:
+			if !visible[entry] {
+				continue
+			}
+			for _, comment := range entry.LeadingComments {
+				rows = append(rows, lv.renderCommentRow(comment, comment))
+			}
+			if entry == selected {
+				selectedRow = len(rows)
+			}
+			rows = append(rows, lv.renderEntry(entry, entry == selected, lv.searchInput.Value()))
+		case model.CommentEntry:
+			rows = append(rows, lv.renderCommentRow(entry.Comment, entry.Comment))
+		case model.BareKeyEntry:
+			rows = append(rows, lv.renderCommentRow(entry.String(), entry.Comment))
+		case model.BlankEntry:
+			rows = append(rows, "")
+		}
+	}
+
+	start, end := scrollWindow(selectedRow, len(rows), listHeight)
+	return addScrollIndicators(rows[start:end], start, len(rows)-end)
+}
+
+// renderCommentRow renders a line of the file shown as a comment in the structure
+// view, with a warning marker when its comment looks like it holds a secret
+func (lv ListView) renderCommentRow(line, comment string) string {
+	marker := ""
+	if model.CommentHasSecret(comment) {
+		marker = lipgloss.NewStyle().Foreground(styles.Warning).Bold(true).Render("!") + " "
+	}
+	return styles.ListItemStyle.Width(lv.width - 6).Render(marker + styles.CommentStyle.Render(line))
+}
+
+// scrollWindow returns the rows [start, end) of a list of total rows to show in
+// height lines, keeping selected in view. When the list does not fit, lines are
+// left free for the scroll indicators, and the window never runs past the end.
+func scrollWindow(selected, total, height int) (int, int) {
+	if total <= height {
+		return 0, total
+	}
+
+	rows := max(1, height-1)
+	start := max(0, min(selected-rows/2, total-rows))
+	if start > 0 && start+rows < total && rows > 1 {
+		// Entries hidden on both sides need both indicators
+		rows--
+		start = max(0, min(selected-rows/2, total-rows))
+	}
+	return start, start + rows
+}
+
+// addScrollIndicators surrounds the visible rows with counts of the rows hidden
+// above and below them
+func addScrollIndicators(rows []string, above, below int) []string {
+	indicatorStyle := styles.HelpDescStyle.Padding(0, 2)
+	if above > 0 {
+		rows = append([]string{indicatorStyle.Render(fmt.Sprintf("▲ %d more", above))}, rows...)
+	}
+	if below > 0 {
+		rows = append(rows, indicatorStyle.Render(fmt.Sprintf("▼ %d more", below)))
+	}
+	return rows
+}
+
+// renderStats renders the file summary shown with ?
+func renderStats(stats model.Stats) string {
+	items := []string{
+		fmt.Sprintf("%d %s", stats.Keys, plural(stats.Keys, "key", "keys")),
+		fmt.Sprintf("%d %s", stats.Secrets, plural(stats.Secrets, "secret", "secrets")),
+		fmt.Sprintf("%d exported", stats.Exported),
+		fmt.Sprintf("%d %s", stats.Duplicates, plural(stats.Duplicates, "duplicate", "duplicates")),
+		fmt.Sprintf("%d %s", stats.Comments, plural(stats.Comments, "comment", "comments")),
+		fmt.Sprintf("%d blank %s", stats.Blanks, plural(stats.Blanks, "line", "lines")),
+	}
+	return styles.SubtitleStyle.Render("📊 " + strings.Join(items, " • "))
+}
+
+// renderDetails renders the detail panel of the selected entry shown with m,
+// including when it was last changed
+func renderDetails(entry *model.Entry, envFile *model.EnvFile, now time.Time) string {
+	items := []string{entry.Key}
+	if entry.Line > 0 {
+		items = append(items, fmt.Sprintf("line %d", entry.Line))
+	}
+	items = append(items, entry.Category(), model.InferValueType(entry.Value).String())
+	if entry.QuoteStyle != model.QuoteNone {
+		items = append(items, entry.QuoteStyle.String()+"-quoted")
+	}
+	if entry.Exported {
+		items = append(items, "exported")
+	}
+	if at, ok := envFile.ModifiedAt(entry.Key); ok {
+		items = append(items, "modified "+model.FormatAge(at, now))
+	} else {
+		items = append(items, "not modified")
+	}
+	return styles.SubtitleStyle.Render("🔎 " + strings.Join(items, " • "))
+}
+
+// positionCounter shows the selected entry's position, e.g. " • 45/230"
+func (lv ListView) positionCounter() string {
+	if len(lv.filteredEntries) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" • %d/%d", lv.selected+1, len(lv.filteredEntries))
+}
+
 func (lv ListView) getDiffIndicator(entry *model.Entry) string {
 	if len(lv.envFiles) <= 1 {
 		return ""
@@ -517,53 +1872,117 @@ func (lv ListView) renderHelp() string {
 
 func (lv ListView) renderHelpWithFiles(showFileShortcuts bool) string {
 	if lv.searching {
-		return styles.HelpDescStyle.Render("Press Enter to confirm search, Esc to cancel")
+		comments := "off"
+		if lv.searchComments {
+			comments = "on"
+		}
+		caseSensitive := "off"
+		if lv.matchCase {
+			caseSensitive = "on"
+		}
+		return styles.HelpDescStyle.Render(fmt.Sprintf("Press Enter to confirm search, Tab to search comments (%s), Ctrl+S for exact case (%s), Esc to cancel", comments, caseSensitive))
+	}
+	if lv.bulkPrompt != bulkPromptNone {
+		return styles.HelpDescStyle.Render("Press Enter to continue, Esc to cancel")
+	}
+	if lv.commitPrompt {
+		return styles.HelpDescStyle.Render("Press Enter to commit this file, Esc to cancel")
+	}
+	if len(lv.deletePrompt) > 0 {
+		return styles.HelpDescStyle.Render("Press y to delete, n or Esc to keep")
+	}
+	if lv.openPrompt {
+		return styles.HelpDescStyle.Render("Press Enter to open the file, or every env file in a directory or glob, Esc to cancel")
+	}
+	if lv.exportPrompt {
+		return styles.HelpDescStyle.Render("Press Enter to export (format from the file extension), Esc to cancel")
+	}
+	if lv.jumpMode {
+		jump := styles.HelpKeyStyle.Render("Jump to: ") + styles.KeyStyle.Render(lv.jumpBuffer+"█")
+		if lv.jumpBuffer != "" && !lv.jumpMatches() {
+			jump += lipgloss.NewStyle().Foreground(styles.Danger).Render("  no key starts with " + lv.jumpBuffer)
+		}
+		return jump + styles.HelpDescStyle.Render("  (type a key prefix, Esc to stop)")
 	}
 
-	// Show copy mode help if active
-	if lv.copyMode {
+	if lv.filePicker {
 		helpItems := []string{
-			styles.HelpKeyStyle.Render("1-9") + " " + styles.HelpDescStyle.Render("select target file"),
+			styles.HelpKeyStyle.Render("↑/↓") + " " + styles.HelpDescStyle.Render("move"),
+			styles.HelpKeyStyle.Render("enter") + " " + styles.HelpDescStyle.Render("choose"),
 			styles.HelpKeyStyle.Render("Esc") + " " + styles.HelpDescStyle.Render("cancel"),
 		}
 		return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
 	}
 
+	// Show copy/compare mode help if active
+	if lv.copyMode || lv.compareMode {
+		helpItems := []string{
+			styles.HelpKeyStyle.Render("1-9") + " " + styles.HelpDescStyle.Render("select file"),
+			styles.HelpKeyStyle.Render(keys.Files.Help().Key) + " " + styles.HelpDescStyle.Render("all files"),
+		}
+		if lv.copyMode {
+			helpItems = append(helpItems, styles.HelpKeyStyle.Render("a")+" "+styles.HelpDescStyle.Render("every file"))
+			helpItems = append(helpItems, styles.HelpKeyStyle.Render("o")+" "+styles.HelpDescStyle.Render("add only/overwrite"))
+		}
+		if lv.compareMode {
+			helpItems = append(helpItems, styles.HelpKeyStyle.Render("e")+" "+styles.HelpDescStyle.Render("environment"))
+		}
+		helpItems = append(helpItems, styles.HelpKeyStyle.Render("Esc")+" "+styles.HelpDescStyle.Render("cancel"))
+		return strings.Join(helpItems, styles.HelpSeparatorStyle.Render(" • "))
+	}
+
 	// Build help in organized rows
 	var rows []string
 	separator := styles.HelpSeparatorStyle.Render(" • ")
 
 	// Row 1: Navigation
 	navItems := []string{
-		styles.HelpKeyStyle.Render("↑/k") + " " + styles.HelpDescStyle.Render("up"),
-		styles.HelpKeyStyle.Render("↓/j") + " " + styles.HelpDescStyle.Render("down"),
-		styles.HelpKeyStyle.Render("/") + " " + styles.HelpDescStyle.Render("search"),
+		styles.HelpKeyStyle.Render(keys.Up.Help().Key) + " " + styles.HelpDescStyle.Render("up"),
+		styles.HelpKeyStyle.Render(keys.Down.Help().Key) + " " + styles.HelpDescStyle.Render("down"),
+		styles.HelpKeyStyle.Render(keys.Search.Help().Key) + " " + styles.HelpDescStyle.Render("search"),
+		styles.HelpKeyStyle.Render(keys.Jump.Help().Key) + " " + styles.HelpDescStyle.Render("jump"),
 	}
 	rows = append(rows, strings.Join(navItems, separator))
 
 	// Row 2: CRUD Operations
 	crudItems := []string{
-		styles.HelpKeyStyle.Render("a") + " " + styles.HelpDescStyle.Render("add"),
-		styles.HelpKeyStyle.Render("e") + " " + styles.HelpDescStyle.Render("edit"),
-		styles.HelpKeyStyle.Render("d") + " " + styles.HelpDescStyle.Render("delete"),
-		styles.HelpKeyStyle.Render("x") + " " + styles.HelpDescStyle.Render("secrets"),
+		styles.HelpKeyStyle.Render(keys.Add.Help().Key) + " " + styles.HelpDescStyle.Render("add"),
+		styles.HelpKeyStyle.Render(keys.Edit.Help().Key) + " " + styles.HelpDescStyle.Render("edit"),
+		styles.HelpKeyStyle.Render(keys.Rename.Help().Key) + " " + styles.HelpDescStyle.Render("rename"),
+		styles.HelpKeyStyle.Render(keys.Duplicate.Help().Key) + " " + styles.HelpDescStyle.Render("duplicate"),
+		styles.HelpKeyStyle.Render(keys.Delete.Help().Key) + " " + styles.HelpDescStyle.Render("delete"),
+		styles.HelpKeyStyle.Render(keys.Toggle.Help().Key) + " " + styles.HelpDescStyle.Render("secrets"),
+		styles.HelpKeyStyle.Render(keys.Partial.Help().Key) + " " + styles.HelpDescStyle.Render("partial"),
+		styles.HelpKeyStyle.Render(keys.ToggleExport.Help().Key) + " " + styles.HelpDescStyle.Render("export"),
+		styles.HelpKeyStyle.Render(keys.ReadOnly.Help().Key) + " " + styles.HelpDescStyle.Render("lock"),
+		styles.HelpKeyStyle.Render(keys.Reveal.Help().Key) + " " + styles.HelpDescStyle.Render("peek"),
+		styles.HelpKeyStyle.Render(keys.Clipboard.Help().Key) + " " + styles.HelpDescStyle.Render("clipboard"),
+		styles.HelpKeyStyle.Render(keys.ClipboardFile.Help().Key) + " " + styles.HelpDescStyle.Render("copy file"),
 	}
 	// Add file-specific operations if multiple files
 	if showFileShortcuts {
-		crudItems = append(crudItems, styles.HelpKeyStyle.Render("y")+" "+styles.HelpDescStyle.Render("copy"))
+		crudItems = append(crudItems, styles.HelpKeyStyle.Render(keys.Copy.Help().Key)+" "+styles.HelpDescStyle.Render("copy"))
 	}
 	rows = append(rows, strings.Join(crudItems, separator))
 
 	// Row 3: History & Comparison
 	historyItems := []string{
-		styles.HelpKeyStyle.Render("u") + " " + styles.HelpDescStyle.Render("undo"),
-		styles.HelpKeyStyle.Render("r") + " " + styles.HelpDescStyle.Render("redo"),
-		styles.HelpKeyStyle.Render("v") + " " + styles.HelpDescStyle.Render("diff"),
-		styles.HelpKeyStyle.Render("s") + " " + styles.HelpDescStyle.Render("sort"),
+		styles.HelpKeyStyle.Render(keys.Undo.Help().Key) + " " + styles.HelpDescStyle.Render("undo"),
+		styles.HelpKeyStyle.Render(keys.Redo.Help().Key) + " " + styles.HelpDescStyle.Render("redo"),
+		styles.HelpKeyStyle.Render(keys.ViewDiff.Help().Key) + " " + styles.HelpDescStyle.Render("diff"),
+		styles.HelpKeyStyle.Render(keys.DiskDiff.Help().Key) + " " + styles.HelpDescStyle.Render("vs disk"),
+		styles.HelpKeyStyle.Render(keys.Sort.Help().Key) + " " + styles.HelpDescStyle.Render("sort"),
+		styles.HelpKeyStyle.Render(keys.SortFile.Help().Key) + " " + styles.HelpDescStyle.Render("sort file"),
+		styles.HelpKeyStyle.Render(keys.Dedupe.Help().Key) + " " + styles.HelpDescStyle.Render("dedupe"),
+		styles.HelpKeyStyle.Render(keys.Details.Help().Key) + " " + styles.HelpDescStyle.Render("details"),
 	}
 	if showFileShortcuts {
-		historyItems = append(historyItems, styles.HelpKeyStyle.Render("c")+" "+styles.HelpDescStyle.Render("compare"))
+		historyItems = append(historyItems, styles.HelpKeyStyle.Render(keys.Diff.Help().Key)+" "+styles.HelpDescStyle.Render("compare"))
+		historyItems = append(historyItems, styles.HelpKeyStyle.Render(keys.Compare.Help().Key)+" "+styles.HelpDescStyle.Render("compare view"))
+		historyItems = append(historyItems, styles.HelpKeyStyle.Render(keys.Effective.Help().Key)+" "+styles.HelpDescStyle.Render("effective"))
 		historyItems = append(historyItems, styles.HelpKeyStyle.Render("1-9")+" "+styles.HelpDescStyle.Render("files"))
+		historyItems = append(historyItems, styles.HelpKeyStyle.Render(keys.Files.Help().Key)+" "+styles.HelpDescStyle.Render("all files"))
+		historyItems = append(historyItems, styles.HelpKeyStyle.Render(keys.PrevFile.Help().Key+"/"+keys.NextFile.Help().Key)+" "+styles.HelpDescStyle.Render("prev/next file"))
 	}
 	rows = append(rows, strings.Join(historyItems, separator))
 
@@ -579,8 +1998,10 @@ func (lv ListView) renderHelpWithFiles(showFileShortcuts bool) string {
 	// Row 5: Bulk Selection (only when active)
 	if lv.bulkMode {
 		bulkItems := []string{
-			styles.HelpKeyStyle.Render("space") + " " + styles.HelpDescStyle.Render("select"),
-			styles.HelpKeyStyle.Render("D") + " " + styles.HelpDescStyle.Render("bulk del ("+fmt.Sprintf("%d", len(lv.selectedItems))+")"),
+			styles.HelpKeyStyle.Render(keys.ToggleSelect.Help().Key) + " " + styles.HelpDescStyle.Render("select"),
+			styles.HelpKeyStyle.Render(keys.BulkDelete.Help().Key) + " " + styles.HelpDescStyle.Render("bulk del ("+fmt.Sprintf("%d", len(lv.selectedItems))+")"),
+			styles.HelpKeyStyle.Render(keys.BulkEdit.Help().Key) + " " + styles.HelpDescStyle.Render("bulk replace"),
+			styles.HelpKeyStyle.Render(keys.ToggleExport.Help().Key) + " " + styles.HelpDescStyle.Render("bulk export"),
 			styles.HelpKeyStyle.Render("Esc") + " " + styles.HelpDescStyle.Render("clear"),
 		}
 		rows = append(rows, strings.Join(bulkItems, separator))
@@ -589,9 +2010,21 @@ func (lv ListView) renderHelpWithFiles(showFileShortcuts bool) string {
 	// Row 5: Utilities & Quit
 	utilItems := []string{
 		styles.HelpKeyStyle.Render("t") + " " + styles.HelpDescStyle.Render("templates"),
-		styles.HelpKeyStyle.Render("b") + " " + styles.HelpDescStyle.Render("backups"),
-		styles.HelpKeyStyle.Render("q") + " " + styles.HelpDescStyle.Render("quit"),
+		styles.HelpKeyStyle.Render(keys.Backup.Help().Key) + " " + styles.HelpDescStyle.Render("backups"),
+		styles.HelpKeyStyle.Render(keys.BackupNow.Help().Key) + " " + styles.HelpDescStyle.Render("back up now"),
+		styles.HelpKeyStyle.Render(keys.Export.Help().Key) + " " + styles.HelpDescStyle.Render("export"),
+		styles.HelpKeyStyle.Render(keys.History.Help().Key) + " " + styles.HelpDescStyle.Render("history"),
+		styles.HelpKeyStyle.Render(keys.Issues.Help().Key) + " " + styles.HelpDescStyle.Render("issues"),
+		styles.HelpKeyStyle.Render(keys.NextIssue.Help().Key+"/"+keys.PrevIssue.Help().Key) + " " + styles.HelpDescStyle.Render("next/prev issue"),
+		styles.HelpKeyStyle.Render(keys.Stats.Help().Key) + " " + styles.HelpDescStyle.Render("summary"),
+		styles.HelpKeyStyle.Render(keys.Structure.Help().Key) + " " + styles.HelpDescStyle.Render("comments"),
+		styles.HelpKeyStyle.Render(keys.GitCommit.Help().Key) + " " + styles.HelpDescStyle.Render("git commit"),
+		styles.HelpKeyStyle.Render(keys.OpenFile.Help().Key) + " " + styles.HelpDescStyle.Render("open"),
+	}
+	if showFileShortcuts {
+		utilItems = append(utilItems, styles.HelpKeyStyle.Render(keys.CloseFile.Help().Key)+" "+styles.HelpDescStyle.Render("close"))
 	}
+	utilItems = append(utilItems, styles.HelpKeyStyle.Render(keys.Quit.Help().Key)+" "+styles.HelpDescStyle.Render("quit"))
 	rows = append(rows, strings.Join(utilItems, separator))
 
 	return lipgloss.JoinVertical(lipgloss.Left, rows...)
@@ -604,6 +2037,17 @@ func (lv ListView) GetSelected() *model.Entry {
 	return nil
 }
 
+// selectEntry moves the selection to the given entry if it is visible
+func (lv *ListView) selectEntry(entry *model.Entry) {
+	for i, e := range lv.filteredEntries {
+		if e == entry {
+			lv.selected = i
+			return
+		}
+	}
+	lv.selected = 0
+}
+
 func (lv ListView) Width() int {
 	return lv.width
 }
@@ -623,16 +2067,169 @@ func (lv *ListView) SetFiles(envFiles []*model.EnvFile, currentIndex int) {
 	lv.currentIndex = currentIndex
 }
 
+// SetInherited sets the entries that come from included files, with the path of
+// each; they are listed with a badge naming the file
+func (lv *ListView) SetInherited(sources map[*model.Entry]string) {
+	lv.inherited = sources
+}
+
+// InheritedFrom returns the path of the included file entry comes from, or ""
+// for an entry of the current file
+func (lv ListView) InheritedFrom(entry *model.Entry) string {
+	return lv.inherited[entry]
+}
+
+// SetValidationIssues sets the issues shown as row markers and in the footer
+func (lv *ListView) SetValidationIssues(issues []model.ValidationIssue) {
+	lv.issueLevels = make(map[string]model.ValidationLevel)
+	lv.issueMessages = make(map[string]string)
+	lv.issueErrors, lv.issueWarnings = 0, 0
+	for _, issue := range issues {
+		switch issue.Level {
+		case model.ValidationError:
+			lv.issueErrors++
+		case model.ValidationWarning:
+			lv.issueWarnings++
+		default:
+			continue
+		}
+		// Lower levels are more severe
+		if level, ok := lv.issueLevels[issue.Key]; !ok || issue.Level < level {
+			lv.issueLevels[issue.Key] = issue.Level
+			lv.issueMessages[issue.Key] = issue.Message
+		}
+	}
+}
+
+// stepToIssue moves the selection to the next (step 1) or previous (step -1)
+// listed entry with a validation error or warning, wrapping around, and shows
+// the issue in the status bar
+func (lv *ListView) stepToIssue(step int) tea.Cmd {
+	var flagged []int
+	for i, entry := range lv.filteredEntries {
+		if _, ok := lv.issueLevels[entry.Key]; ok && entry.Key != "" {
+			flagged = append(flagged, i)
+		}
+	}
+	if len(flagged) == 0 {
+		return lv.setStatus("No issues on the listed entries", false)
+	}
+
+	// The first flagged entry after (or before) the selection
+	pos := 0
+	if step > 0 {
+		for pos < len(flagged) && flagged[pos] <= lv.selected {
+			pos++
+		}
+		pos %= len(flagged)
+	} else {
+		pos = len(flagged) - 1
+		for pos >= 0 && flagged[pos] >= lv.selected {
+			pos--
+		}
+		if pos < 0 {
+			pos = len(flagged) - 1
+		}
+	}
+
+	lv.selected = flagged[pos]
+	entry := lv.filteredEntries[lv.selected]
+	return lv.setStatus(fmt.Sprintf("Issue %d of %d: %s", pos+1, len(flagged), lv.issueMessages[entry.Key]),
+		lv.issueLevels[entry.Key] == model.ValidationError)
+}
+
+// bulkToggleExport flips the export flag of the selected keys together: they
+// are all exported unless every one of them already is
+func (lv *ListView) bulkToggleExport() tea.Cmd {
+	if lv.currentIndex < 0 || lv.currentIndex >= len(lv.envFiles) {
+		return nil
+	}
+	keys := make([]string, 0, len(lv.selectedItems))
+	exported := false
+	for k := range lv.selectedItems {
+		keys = append(keys, k)
+		if entry := lv.envFiles[lv.currentIndex].GetEntry(k); entry != nil && !entry.Exported {
+			exported = true
+		}
+	}
+	sort.Strings(keys)
+	return func() tea.Msg { return BulkExportToggleMsg{Keys: keys, Exported: exported} }
+}
+
+// SelectKey moves the selection to the first entry with the given key, clearing
+// the search if it hides the entry. It returns false if the key is not listed.
+func (lv *ListView) SelectKey(key string) bool {
+	find := func() bool {
+		for i, entry := range lv.filteredEntries {
+			if entry.Key == key {
+				lv.selected = i
+				lv.revealedKey = ""
+				return true
+			}
+		}
+		return false
+	}
+	if find() {
+		return true
+	}
+	if lv.searchInput.Value() != "" {
+		lv.searching = false
+		lv.searchInput.SetValue("")
+		lv.filterEntries("")
+		return find()
+	}
+	return false
+}
+
+// PartialReveal reports whether secrets show their first and last characters
+func (lv ListView) PartialReveal() bool {
+	return lv.partialReveal
+}
+
+// SetPartialReveal shows the first and last characters of secrets, or masks them fully
+func (lv *ListView) SetPartialReveal(partial bool) {
+	lv.partialReveal = partial
+}
+
+// DetailsShown reports whether the selected entry's detail panel is open
+func (lv ListView) DetailsShown() bool {
+	return lv.showDetails
+}
+
+// ShowDetails opens or closes the selected entry's detail panel
+func (lv *ListView) ShowDetails(show bool) {
+	lv.showDetails = show
+}
+
+// SetChangeStack gives the list access to the undo history for the recently changed sort
+func (lv *ListView) SetChangeStack(cs *model.ChangeStack) {
+	lv.changeStack = cs
+}
+
 func (lv *ListView) ToggleDiffs() {
 	lv.showDiffs = !lv.showDiffs
 }
 
 func (lv *ListView) cycleSortMode() {
-	lv.sortMode = (lv.sortMode + 1) % 3
+	lv.SetSortMode((lv.sortMode + 1) % 5)
+}
+
+// SortMode reports the current sort mode and whether the user has sorted the list at all
+func (lv ListView) SortMode() (SortMode, bool) {
+	return lv.sortMode, lv.sorted
+}
+
+// SetSortMode sorts the displayed entries by mode
+func (lv *ListView) SetSortMode(mode SortMode) {
+	lv.sortMode = mode
+	lv.sorted = true
 	lv.applySort()
 }
 
+// applySort reorders the displayed entries only. It sorts a copy, so the entries
+// shared with the EnvFile (and therefore the order written to disk) are untouched.
 func (lv *ListView) applySort() {
+	lv.filteredEntries = append([]*model.Entry(nil), lv.filteredEntries...)
 	switch lv.sortMode {
 	case SortModeAlphabetical:
 		sort.Slice(lv.filteredEntries, func(i, j int) bool {
@@ -651,9 +2248,56 @@ func (lv *ListView) applySort() {
 		sort.Slice(lv.filteredEntries, func(i, j int) bool {
 			return len(lv.filteredEntries[i].Value) > len(lv.filteredEntries[j].Value)
 		})
+	case SortModeByRecentlyChanged:
+		recency := lv.recentlyChangedKeys()
+		sort.SliceStable(lv.filteredEntries, func(i, j int) bool {
+			rankI, changedI := recency[lv.filteredEntries[i].Key]
+			rankJ, changedJ := recency[lv.filteredEntries[j].Key]
+			if changedI != changedJ {
+				return changedI
+			}
+			if changedI {
+				return rankI < rankJ
+			}
+			return lv.filteredEntries[i].Key < lv.filteredEntries[j].Key
+		})
+	case SortModeEmptyFirst:
+		sort.SliceStable(lv.filteredEntries, func(i, j int) bool {
+			emptyI, emptyJ := lv.filteredEntries[i].Value == "", lv.filteredEntries[j].Value == ""
+			if emptyI != emptyJ {
+				return emptyI
+			}
+			return lv.filteredEntries[i].Key < lv.filteredEntries[j].Key
+		})
 	}
 }
 
+// recentlyChangedKeys ranks the keys of the current file by their latest change (0 = most recent).
+// Undone changes no longer count.
+func (lv ListView) recentlyChangedKeys() map[string]int {
+	recency := make(map[string]int)
+	if lv.changeStack == nil {
+		return recency
+	}
+
+	currentPath := ""
+	if lv.currentIndex >= 0 && lv.currentIndex < len(lv.envFiles) {
+		currentPath = lv.envFiles[lv.currentIndex].Path
+	}
+
+	history := lv.changeStack.GetHistory()
+	for i := lv.changeStack.GetCurrentPosition(); i >= 0 && i < len(history); i-- {
+		change := history[i]
+		if change.Entry == nil || (currentPath != "" && change.FilePath != currentPath) {
+			continue
+		}
+		if _, seen := recency[change.Entry.Key]; !seen {
+			recency[change.Entry.Key] = len(recency)
+		}
+	}
+	return recency
+}
+
 func (lv ListView) GetSortModeName() string {
 	switch lv.sortMode {
 	case SortModeAlphabetical:
@@ -662,6 +2306,10 @@ func (lv ListView) GetSortModeName() string {
 		return "by category"
 	case SortModeByValueLength:
 		return "by value length"
+	case SortModeByRecentlyChanged:
+		return "recently changed"
+	case SortModeEmptyFirst:
+		return "empty first"
 	}
 	return ""
 }
@@ -679,10 +2327,211 @@ func (lv *ListView) ClearSelection() {
 	lv.bulkMode = false
 }
 
+// CapturesInput returns true while a text prompt or question owns the keyboard,
+// so the app must pass every key through instead of handling shortcuts
+func (lv ListView) CapturesInput() bool {
+	return lv.searching || lv.filePicker || lv.clipboardPrompt || lv.fileClipboard != fileClipboardNone || lv.bulkPrompt != bulkPromptNone || lv.commitPrompt || lv.jumpMode ||
+		len(lv.deletePrompt) > 0 || len(lv.dedupePrompt) > 0 || lv.exportPrompt || lv.openPrompt
+}
+
+// exportEntries returns the bulk-selected entries in file order, or the visible
+// (filtered) entries when nothing is selected
+func (lv ListView) exportEntries() []*model.Entry {
+	if len(lv.selectedItems) == 0 {
+		return lv.filteredEntries
+	}
+	var entries []*model.Entry
+	for _, entry := range lv.entries {
+		if lv.selectedItems[entry.Key] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// SetConfirmDelete sets whether deletes ask for confirmation before they happen
+func (lv *ListView) SetConfirmDelete(confirm bool) {
+	lv.confirmDelete = confirm
+}
+
+// RequestDelete asks to confirm deleting keys, or deletes them right away when
+// confirmation is disabled
+func (lv *ListView) RequestDelete(keys []string) tea.Cmd {
+	if len(keys) == 0 {
+		return nil
+	}
+	if !lv.confirmDelete {
+		return func() tea.Msg { return BulkDeleteMsg{Keys: keys} }
+	}
+	lv.deletePrompt = keys
+	return nil
+}
+
+// AskDedupe asks which occurrence of the repeated keys to keep
+func (lv *ListView) AskDedupe(keys []string) {
+	lv.dedupePrompt = keys
+}
+
+// describeKeys lists a few keys for a prompt and summarizes the rest
+func describeKeys(keys []string) string {
+	const shown = 3
+	if len(keys) <= shown {
+		return strings.Join(keys, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(keys[:shown], ", "), len(keys)-shown)
+}
+
+// ShowStatus displays a transient status message below the list
+func (lv *ListView) ShowStatus(message string, isError bool) tea.Cmd {
+	return lv.setStatus(message, isError)
+}
+
+// IsClipboardPrompt returns true while asking how to copy a secret value
+func (lv ListView) IsClipboardPrompt() bool {
+	return lv.clipboardPrompt
+}
+
 func (lv ListView) IsCopyMode() bool {
 	return lv.copyMode
 }
 
+// IsCompareMode returns true while picking a file to compare against
+func (lv ListView) IsCompareMode() bool {
+	return lv.compareMode
+}
+
+// SetCompareMode enables or disables the compare file picker
+func (lv *ListView) SetCompareMode(enabled bool) {
+	lv.compareMode = enabled
+}
+
+// OpenFilePicker lists every open file to choose from. In copy or compare mode
+// the chosen file is the copy target or the file to compare with; otherwise the
+// app switches to it.
+func (lv *ListView) OpenFilePicker() {
+	if len(lv.envFiles) < 2 {
+		return
+	}
+	lv.filePicker = true
+	lv.pickerIndex = lv.currentIndex
+	if lv.filePickerPurpose() != FileSwitch {
+		// The current file can't be its own target
+		lv.pickerIndex = (lv.currentIndex + 1) % len(lv.envFiles)
+	}
+}
+
+// IsFilePickerOpen returns true while the file picker is shown
+func (lv ListView) IsFilePickerOpen() bool {
+	return lv.filePicker
+}
+
+func (lv ListView) filePickerPurpose() FilePurpose {
+	switch {
+	case lv.copyMode:
+		return FileCopyTarget
+	case lv.compareMode:
+		return FileCompare
+	}
+	return FileSwitch
+}
+
+// updateFilePicker handles keys while the file picker is open
+func (lv ListView) updateFilePicker(msg tea.KeyMsg) (ListView, tea.Cmd) {
+	purpose := lv.filePickerPurpose()
+	// Targets skip the current file
+	step := func(delta int) {
+		for i := lv.pickerIndex + delta; i >= 0 && i < len(lv.envFiles); i += delta {
+			if purpose == FileSwitch || i != lv.currentIndex {
+				lv.pickerIndex = i
+				return
+			}
+		}
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		step(-1)
+	case "down", "j":
+		step(1)
+	case "home", "g":
+		lv.pickerIndex = -1
+		step(1)
+	case "end":
+		lv.pickerIndex = len(lv.envFiles)
+		step(-1)
+	case "enter":
+		lv.filePicker = false
+		lv.copyMode = false
+		lv.copyTargetIndex = -1
+		lv.compareMode = false
+		picked := FilePickedMsg{Index: lv.pickerIndex, Purpose: purpose}
+		return lv, func() tea.Msg { return picked }
+	case "esc", "q":
+		lv.filePicker = false
+		lv.copyMode = false
+		lv.copyTargetIndex = -1
+		lv.compareMode = false
+	}
+	return lv, nil
+}
+
+// renderFilePicker renders the rows of the file picker
+func (lv ListView) renderFilePicker(height int) []string {
+	purpose := lv.filePickerPurpose()
+	title := "Switch to file"
+	switch purpose {
+	case FileCopyTarget:
+		title = "Copy to file"
+	case FileCompare:
+		title = "Compare with file"
+	}
+	rows := []string{styles.HelpKeyStyle.Padding(0, 1).Render(fmt.Sprintf("%s (%d open)", title, len(lv.envFiles)))}
+
+	start, end := scrollWindow(lv.pickerIndex, len(lv.envFiles), height-1)
+	var items []string
+	for i := start; i < end; i++ {
+		envFile := lv.envFiles[i]
+		shortcut := "  "
+		if i < 9 {
+			shortcut = fmt.Sprintf("%d:", i+1)
+		}
+		line := fmt.Sprintf("%s %s (%d entries)", shortcut, fileDisplayName(envFile.Path), len(envFile.FilterEntries("")))
+		if dir := filepath.Dir(envFile.Path); dir != "." && envFile.Path != storage.StdinPath {
+			line += styles.HelpDescStyle.Render("  " + dir)
+		}
+
+		switch {
+		case i == lv.pickerIndex:
+			items = append(items, styles.SelectedItemStyle.Width(lv.width-6).Render("▶ "+line))
+		case i == lv.currentIndex && purpose != FileSwitch:
+			items = append(items, styles.HelpDescStyle.Padding(0, 2).Render(line+" (current)"))
+		case i == lv.currentIndex:
+			items = append(items, styles.ListItemStyle.Render("  "+line+" (current)"))
+		default:
+			items = append(items, styles.ListItemStyle.Render("  "+line))
+		}
+	}
+	return append(rows, addScrollIndicators(items, start, len(lv.envFiles)-end)...)
+}
+
+// CopyOverwrite returns true if copying replaces existing values in the target
+func (lv ListView) CopyOverwrite() bool {
+	return lv.copyOverwrite
+}
+
+// ToggleCopyOverwrite switches copy mode between add-only and overwrite
+func (lv *ListView) ToggleCopyOverwrite() {
+	lv.copyOverwrite = !lv.copyOverwrite
+}
+
+// copyModeName names the active copy mode for the banner
+func (lv ListView) copyModeName() string {
+	if lv.copyOverwrite {
+		return "overwrite"
+	}
+	return "add only"
+}
+
 func (lv *ListView) SetCopyMode(enabled bool) {
 	lv.copyMode = enabled
 	if !enabled {
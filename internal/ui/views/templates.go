@@ -0,0 +1,224 @@
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/envtui/envtui/internal/model"
+)
+
+// userTemplates are the templates loaded from a file, offered along with the built-in ones
+var userTemplates []Template
+
+// DefaultTemplatesPath returns where user templates are looked for when no file
+// is given, e.g. ~/.config/envtui/templates.json
+func DefaultTemplatesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "envtui", "templates.json"), nil
+}
+
+// LoadTemplates reads templates from a JSON file holding a list of objects with
+// a key and value, and optionally a name, description and category:
+//
+//	[{"key": "SENTRY_DSN", "value": "https://key@sentry.io/1", "description": "Error reporting", "category": "Monitoring"}]
+//
+// A template without a name is named after its key.
+func LoadTemplates(path string) ([]Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+	var templates []Template
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range templates {
+		if !model.IsValidKey(templates[i].Key) {
+			return nil, fmt.Errorf("%s: template %d has an invalid key %q", path, i+1, templates[i].Key)
+		}
+		if templates[i].Name == "" {
+			templates[i].Name = templates[i].Key
+		}
+	}
+	return templates, nil
+}
+
+// SetUserTemplates sets the templates offered along with the built-in ones.
+// Passing nil leaves only the built-in templates.
+func SetUserTemplates(templates []Template) {
+	userTemplates = templates
+}
+
+// Templates returns the built-in templates merged with the user's: a user
+// template replaces the built-in one with the same key, and the others follow
+// the built-ins in file order
+func Templates() []Template {
+	combined := append([]Template(nil), QuickTemplates...)
+	for _, template := range userTemplates {
+		replaced := false
+		for i := range combined {
+			if combined[i].Key == template.Key {
+				combined[i] = template
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			combined = append(combined, template)
+		}
+	}
+	return combined
+}
+
+// otherCategory groups the templates that have no category
+const otherCategory = "Other"
+
+// templateRow is a line of the template picker: a category header, or a
+// template (an index into the edit view's templates)
+type templateRow struct {
+	category string
+	template int // -1 for the header
+}
+
+// templateCategory returns the picker group of a template
+func templateCategory(template Template) string {
+	if template.Category == "" {
+		return otherCategory
+	}
+	return template.Category
+}
+
+// templateRows lists the picker lines: each category, in the order it first
+// appears, followed by its templates unless it is folded
+func (ev EditView) templateRows() []templateRow {
+	var categories []string
+	members := make(map[string][]int)
+	for i, template := range ev.templates {
+		category := templateCategory(template)
+		if _, ok := members[category]; !ok {
+			categories = append(categories, category)
+		}
+		members[category] = append(members[category], i)
+	}
+
+	var rows []templateRow
+	for _, category := range categories {
+		rows = append(rows, templateRow{category: category, template: -1})
+		if ev.collapsed[category] {
+			continue
+		}
+		for _, i := range members[category] {
+			rows = append(rows, templateRow{category: category, template: i})
+		}
+	}
+	return rows
+}
+
+// selectable reports whether the cursor can rest on a row: headers are skipped,
+// except for folded categories, which have nothing else to select
+func (ev EditView) selectable(row templateRow) bool {
+	return row.template >= 0 || ev.collapsed[row.category]
+}
+
+// moveTemplateRow moves the picker cursor by delta selectable rows. A delta of 0
+// moves it forward to the nearest selectable row.
+func (ev *EditView) moveTemplateRow(delta int) {
+	rows := ev.templateRows()
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	target := ev.templateRow
+	if delta == 0 {
+		delta, target = 1, target-1
+	}
+	for moved := 0; moved < delta*step; {
+		target += step
+		if target < 0 || target >= len(rows) {
+			return
+		}
+		if ev.selectable(rows[target]) {
+			moved++
+		}
+	}
+	ev.templateRow = target
+}
+
+// toggleTemplateCategory folds or unfolds the category of the selected row,
+// keeping the cursor in it
+func (ev *EditView) toggleTemplateCategory() {
+	rows := ev.templateRows()
+	if ev.templateRow >= len(rows) {
+		return
+	}
+	category := rows[ev.templateRow].category
+	if ev.collapsed == nil {
+		ev.collapsed = make(map[string]bool)
+	}
+	ev.collapsed[category] = !ev.collapsed[category]
+
+	for i, row := range ev.templateRows() {
+		if row.category == category {
+			ev.templateRow = i
+			break
+		}
+	}
+	if !ev.collapsed[category] {
+		ev.moveTemplateRow(0) // From the header to its first template
+	}
+}
+
+// TemplateBundle is a group of templates inserted together, e.g. every setting
+// a Postgres connection needs
+type TemplateBundle struct {
+	Name        string
+	Description string
+	Templates   []Template
+}
+
+// InsertBundleMsg asks for the templates of a bundle to be added to the current
+// file, skipping keys it already has
+type InsertBundleMsg struct {
+	Bundle TemplateBundle
+}
+
+var QuickBundles = []TemplateBundle{
+	{Name: "Postgres", Description: "Postgres connection settings", Templates: []Template{
+		{Name: "DB_HOST", Key: "DB_HOST", Value: "localhost", Description: "Database host"},
+		{Name: "DB_PORT", Key: "DB_PORT", Value: "5432", Description: "Database port"},
+		{Name: "DB_USER", Key: "DB_USER", Value: "postgres", Description: "Database user"},
+		{Name: "DB_PASSWORD", Key: "DB_PASSWORD", Value: "your-password-here", Description: "Database password"},
+		{Name: "DB_NAME", Key: "DB_NAME", Value: "dbname", Description: "Database name"},
+	}},
+	{Name: "Redis", Description: "Redis connection settings", Templates: []Template{
+		{Name: "REDIS_HOST", Key: "REDIS_HOST", Value: "localhost", Description: "Redis host"},
+		{Name: "REDIS_PORT", Key: "REDIS_PORT", Value: "6379", Description: "Redis port"},
+		{Name: "REDIS_PASSWORD", Key: "REDIS_PASSWORD", Value: "", Description: "Redis password"},
+	}},
+	{Name: "SMTP", Description: "Outgoing mail server", Templates: []Template{
+		{Name: "SMTP_HOST", Key: "SMTP_HOST", Value: "smtp.example.com", Description: "Mail server host"},
+		{Name: "SMTP_PORT", Key: "SMTP_PORT", Value: "587", Description: "Mail server port"},
+		{Name: "SMTP_USER", Key: "SMTP_USER", Value: "user@example.com", Description: "Mail server user"},
+		{Name: "SMTP_PASSWORD", Key: "SMTP_PASSWORD", Value: "your-password-here", Description: "Mail server password"},
+	}},
+	{Name: "AWS", Description: "AWS credentials and region", Templates: []Template{
+		{Name: "AWS_ACCESS_KEY", Key: "AWS_ACCESS_KEY_ID", Value: "your-access-key", Description: "AWS access key ID"},
+		{Name: "AWS_SECRET", Key: "AWS_SECRET_ACCESS_KEY", Value: "your-secret-key", Description: "AWS secret key"},
+		{Name: "AWS_REGION", Key: "AWS_REGION", Value: "us-east-1", Description: "AWS region"},
+	}},
+}
+
+// bundleKeys lists the keys of a bundle, e.g. "DB_HOST, DB_PORT"
+func bundleKeys(bundle TemplateBundle) string {
+	keys := make([]string, len(bundle.Templates))
+	for i, template := range bundle.Templates {
+		keys[i] = template.Key
+	}
+	return strings.Join(keys, ", ")
+}